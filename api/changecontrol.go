@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"strconv"
@@ -149,6 +150,22 @@ type AddNotesToChangeControlResp struct {
 // }
 func (c CvpRestAPI) GetChangeControls(
 	querystr string, start int, end int) ([]ChangeControl, error) {
+	return c.GetChangeControlsCtx(context.Background(), querystr, start, end)
+}
+
+// GetChangeControlsCtx is the context aware version of GetChangeControls.
+func (c CvpRestAPI) GetChangeControlsCtx(ctx context.Context, querystr string, start int,
+	end int) ([]ChangeControl, error) {
+	changeControlInfo, err := c.getChangeControls(ctx, querystr, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return changeControlInfo.Data, nil
+}
+
+// getChangeControls fetches a range of change controls along with the total number of matches.
+func (c CvpRestAPI) getChangeControls(ctx context.Context, querystr string, start int,
+	end int) (*ChangeControlList, error) {
 	var changeControlInfo ChangeControlList
 	query := &url.Values{
 		"queryparam": {querystr},
@@ -156,11 +173,10 @@ func (c CvpRestAPI) GetChangeControls(
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/changeControl/getChangeControls.do",
+	resp, err := c.get(ctx, "/changeControl/getChangeControls.do",
 		query)
 	if err != nil {
-		return nil, errors.Errorf("GetChangeControls: %s",
-			err)
+		return nil, wrapError("GetChangeControls", "/changeControl/getChangeControls.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &changeControlInfo); err != nil {
@@ -170,11 +186,10 @@ func (c CvpRestAPI) GetChangeControls(
 	}
 
 	if err := changeControlInfo.Error(); err != nil {
-		return nil, errors.Errorf("GetChangeControls: %s",
-			err)
+		return nil, wrapError("GetChangeControls", "/changeControl/getChangeControls.do", err)
 	}
 
-	return changeControlInfo.Data, nil
+	return &changeControlInfo, nil
 }
 
 // GetChangeControlAvailableTasks returns a list of ChangeControlTask's.
@@ -186,6 +201,12 @@ func (c CvpRestAPI) GetChangeControls(
 // }
 func (c CvpRestAPI) GetChangeControlAvailableTasks(
 	querystr string, start int, end int) ([]ChangeControlTask, error) {
+	return c.GetChangeControlAvailableTasksCtx(context.Background(), querystr, start, end)
+}
+
+// GetChangeControlAvailableTasksCtx is the context aware version of GetChangeControlAvailableTasks.
+func (c CvpRestAPI) GetChangeControlAvailableTasksCtx(ctx context.Context, querystr string,
+	start int, end int) ([]ChangeControlTask, error) {
 	var availableTaskInfo ChangeControlTaskList
 	query := &url.Values{
 		"queryparam": {querystr},
@@ -193,11 +214,11 @@ func (c CvpRestAPI) GetChangeControlAvailableTasks(
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/changeControl/getTasksByStatus.do",
+	resp, err := c.get(ctx, "/changeControl/getTasksByStatus.do",
 		query)
 	if err != nil {
-		return nil, errors.Errorf("GetChangeControlAvailableTasks: %s",
-			err)
+		return nil, wrapError("GetChangeControlAvailableTasks",
+			"/changeControl/getTasksByStatus.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &availableTaskInfo); err != nil {
@@ -207,8 +228,8 @@ func (c CvpRestAPI) GetChangeControlAvailableTasks(
 	}
 
 	if err := availableTaskInfo.Error(); err != nil {
-		return nil, errors.Errorf("GetChangeControlAvailableTasks: %s",
-			err)
+		return nil, wrapError("GetChangeControlAvailableTasks",
+			"/changeControl/getTasksByStatus.do", err)
 	}
 
 	return availableTaskInfo.Data, nil
@@ -217,6 +238,14 @@ func (c CvpRestAPI) GetChangeControlAvailableTasks(
 // CreateChangeControl adds a note to the Change Control represented by ccID
 func (c CvpRestAPI) CreateChangeControl(ccName, timeZone, countryID, dateTime, snapshotTemplateKey,
 	changeControlType, stopOnError string, tasks []ChangeControlTaskInfo) (string, error) {
+	return c.CreateChangeControlCtx(context.Background(), ccName, timeZone, countryID, dateTime,
+		snapshotTemplateKey, changeControlType, stopOnError, tasks)
+}
+
+// CreateChangeControlCtx is the context aware version of CreateChangeControl.
+func (c CvpRestAPI) CreateChangeControlCtx(ctx context.Context, ccName, timeZone, countryID,
+	dateTime, snapshotTemplateKey, changeControlType, stopOnError string,
+	tasks []ChangeControlTaskInfo) (string, error) {
 	var info AddOrUpdateChangeControlResp
 
 	data := map[string]interface{}{
@@ -230,9 +259,10 @@ func (c CvpRestAPI) CreateChangeControl(ccName, timeZone, countryID, dateTime, s
 		"deletedTaskIds":      []string{},
 		"changeControlTasks":  tasks,
 	}
-	resp, err := c.client.Post("/changeControl/addOrUpdateChangeControl.do", nil, data)
+	resp, err := c.post(ctx, "/changeControl/addOrUpdateChangeControl.do", nil, data)
 	if err != nil {
-		return "", errors.Errorf("CreateChangeControl: %s", err)
+		return "", wrapError("CreateChangeControl",
+			"/changeControl/addOrUpdateChangeControl.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -240,22 +270,29 @@ func (c CvpRestAPI) CreateChangeControl(ccName, timeZone, countryID, dateTime, s
 	}
 
 	if err := info.Error(); err != nil {
-		return "", errors.Errorf("CreateChangeControl: %s", err)
+		return "", wrapError("CreateChangeControl",
+			"/changeControl/addOrUpdateChangeControl.do", err)
 	}
 	return info.CcID, nil
 }
 
 // AddNotesToChangeControl adds a note to the Change Control represented by ccID
 func (c CvpRestAPI) AddNotesToChangeControl(ccID int, notes string) error {
+	return c.AddNotesToChangeControlCtx(context.Background(), ccID, notes)
+}
+
+// AddNotesToChangeControlCtx is the context aware version of AddNotesToChangeControl.
+func (c CvpRestAPI) AddNotesToChangeControlCtx(ctx context.Context, ccID int, notes string) error {
 	var info AddNotesToChangeControlResp
 
 	data := map[string]string{
 		"ccId":  strconv.Itoa(ccID),
 		"notes": notes,
 	}
-	resp, err := c.client.Post("/changeControl/addNotesToChangeControl.do", nil, data)
+	resp, err := c.post(ctx, "/changeControl/addNotesToChangeControl.do", nil, data)
 	if err != nil {
-		return errors.Errorf("AddNotesToChangeControl: %s", err)
+		return wrapError("AddNotesToChangeControl",
+			"/changeControl/addNotesToChangeControl.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -263,7 +300,8 @@ func (c CvpRestAPI) AddNotesToChangeControl(ccID int, notes string) error {
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("AddNotesToChangeControl: %s", err)
+		return wrapError("AddNotesToChangeControl",
+			"/changeControl/addNotesToChangeControl.do", err)
 	}
 	return nil
 }
@@ -31,7 +31,10 @@
 
 package cvpapi
 
-import "net/url"
+import (
+	"context"
+	"net/url"
+)
 
 // The ClientInterface is implemented by a client to allow interaction with
 // CVP REST
@@ -41,12 +44,74 @@ type ClientInterface interface {
 	Delete(string, *url.Values, interface{}) ([]byte, error)
 }
 
+// The ContextClientInterface is implemented by a client that can bound
+// a CVP REST request with a context for cancellation and deadlines
+type ContextClientInterface interface {
+	ClientInterface
+	GetCtx(context.Context, string, *url.Values) ([]byte, error)
+	PostCtx(context.Context, string, *url.Values, interface{}) ([]byte, error)
+	DeleteCtx(context.Context, string, *url.Values, interface{}) ([]byte, error)
+}
+
+// The UpdateClientInterface is implemented by a client that supports the
+// PUT and PATCH requests used by the CVP resource APIs
+type UpdateClientInterface interface {
+	ClientInterface
+	Put(string, *url.Values, interface{}) ([]byte, error)
+	Patch(string, *url.Values, interface{}) ([]byte, error)
+}
+
+// The ContextUpdateClientInterface is the context aware version of
+// UpdateClientInterface
+type ContextUpdateClientInterface interface {
+	UpdateClientInterface
+	PutCtx(context.Context, string, *url.Values, interface{}) ([]byte, error)
+	PatchCtx(context.Context, string, *url.Values, interface{}) ([]byte, error)
+}
+
 // CvpRestAPI provides the REST functionallity
 type CvpRestAPI struct {
-	client ClientInterface
+	client  ClientInterface
+	version *versionState
 }
 
 // NewCvpRestAPI creates a new Rest API
 func NewCvpRestAPI(client ClientInterface) *CvpRestAPI {
-	return &CvpRestAPI{client: client}
+	return &CvpRestAPI{client: client, version: &versionState{}}
+}
+
+// get issues a GET using the context aware client if available. Clients
+// only implementing ClientInterface have the context checked up front.
+func (c CvpRestAPI) get(ctx context.Context, url string, params *url.Values) ([]byte, error) {
+	if cc, ok := c.client.(ContextClientInterface); ok {
+		return cc.GetCtx(ctx, url, params)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.client.Get(url, params)
+}
+
+// post issues a POST using the context aware client if available.
+func (c CvpRestAPI) post(ctx context.Context, url string, params *url.Values,
+	data interface{}) ([]byte, error) {
+	if cc, ok := c.client.(ContextClientInterface); ok {
+		return cc.PostCtx(ctx, url, params, data)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.client.Post(url, params, data)
+}
+
+// delete issues a DELETE using the context aware client if available.
+func (c CvpRestAPI) delete(ctx context.Context, url string, params *url.Values,
+	data interface{}) ([]byte, error) {
+	if cc, ok := c.client.(ContextClientInterface); ok {
+		return cc.DeleteCtx(ctx, url, params, data)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.client.Delete(url, params, data)
 }
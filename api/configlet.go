@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -134,6 +135,22 @@ type VerifyError struct {
 
 // GetConfigletsInfo returns configlet info
 func (c CvpRestAPI) GetConfigletsInfo(start int, end int) ([]Configlet, error) {
+	return c.GetConfigletsInfoCtx(context.Background(), start, end)
+}
+
+// GetConfigletsInfoCtx is the context aware version of GetConfigletsInfo.
+func (c CvpRestAPI) GetConfigletsInfoCtx(ctx context.Context, start int,
+	end int) ([]Configlet, error) {
+	info, err := c.getConfigletsInfo(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return info.Data, nil
+}
+
+// getConfigletsInfo fetches a range of configlets along with the total number of configlets.
+func (c CvpRestAPI) getConfigletsInfo(ctx context.Context, start int,
+	end int) (*ConfigletList, error) {
 	var info ConfigletList
 
 	query := &url.Values{
@@ -141,7 +158,7 @@ func (c CvpRestAPI) GetConfigletsInfo(start int, end int) ([]Configlet, error) {
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/configlet/getConfiglets.do", query)
+	resp, err := c.get(ctx, "/configlet/getConfiglets.do", query)
 	if err != nil {
 		return nil, errors.Wrap(err, "GetConfigletsInfo")
 	}
@@ -153,23 +170,33 @@ func (c CvpRestAPI) GetConfigletsInfo(start int, end int) ([]Configlet, error) {
 	if err := info.Error(); err != nil {
 		return nil, errors.Wrap(err, "GetConfigletsInfo")
 	}
-	return info.Data, nil
+	return &info, nil
 }
 
 // GetConfiglets returns configlet info
 func (c CvpRestAPI) GetConfiglets() ([]Configlet, error) {
-	return c.GetConfigletsInfo(0, 0)
+	return c.GetConfigletsCtx(context.Background())
+}
+
+// GetConfigletsCtx is the context aware version of GetConfiglets.
+func (c CvpRestAPI) GetConfigletsCtx(ctx context.Context) ([]Configlet, error) {
+	return c.GetConfigletsInfoCtx(ctx, 0, 0)
 }
 
 // GetConfigletByName returns the configlet with the specified name
 func (c CvpRestAPI) GetConfigletByName(name string) (*Configlet, error) {
+	return c.GetConfigletByNameCtx(context.Background(), name)
+}
+
+// GetConfigletByNameCtx is the context aware version of GetConfigletByName.
+func (c CvpRestAPI) GetConfigletByNameCtx(ctx context.Context, name string) (*Configlet, error) {
 	var info Configlet
 
 	query := &url.Values{"name": {name}}
 
-	resp, err := c.client.Get("/configlet/getConfigletByName.do", query)
+	resp, err := c.get(ctx, "/configlet/getConfigletByName.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetConfigletByName: %s", err)
+		return nil, wrapError("GetConfigletByName", "/configlet/getConfigletByName.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -178,23 +205,28 @@ func (c CvpRestAPI) GetConfigletByName(name string) (*Configlet, error) {
 
 	if err := info.Error(); err != nil {
 		// Entity does not exist
-		if info.ErrorCode == "132801" {
+		if info.ErrorCode == ENTITY_DOES_NOT_EXIST {
 			return nil, nil
 		}
-		return nil, errors.Errorf("GetConfigletByName: %s", err)
+		return nil, wrapError("GetConfigletByName", "/configlet/getConfigletByName.do", err)
 	}
 	return &info, nil
 }
 
 // GetConfigletByID returns the configlet with the specified ID
 func (c CvpRestAPI) GetConfigletByID(ID string) (*Configlet, error) {
+	return c.GetConfigletByIDCtx(context.Background(), ID)
+}
+
+// GetConfigletByIDCtx is the context aware version of GetConfigletByID.
+func (c CvpRestAPI) GetConfigletByIDCtx(ctx context.Context, ID string) (*Configlet, error) {
 	var info Configlet
 
 	query := &url.Values{"id": {ID}}
 
-	resp, err := c.client.Get("/configlet/getConfigletById.do", query)
+	resp, err := c.get(ctx, "/configlet/getConfigletById.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetConfigletByID: %s", err)
+		return nil, wrapError("GetConfigletByID", "/configlet/getConfigletById.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -203,16 +235,22 @@ func (c CvpRestAPI) GetConfigletByID(ID string) (*Configlet, error) {
 
 	if err := info.Error(); err != nil {
 		// Entity does not exist
-		if info.ErrorCode == "132801" {
+		if info.ErrorCode == ENTITY_DOES_NOT_EXIST {
 			return nil, nil
 		}
-		return nil, errors.Errorf("GetConfigletByID: %s", err)
+		return nil, wrapError("GetConfigletByID", "/configlet/getConfigletById.do", err)
 	}
 	return &info, nil
 }
 
 // GetConfigletHistory returns the history for a configlet provided the key, and a range.
 func (c CvpRestAPI) GetConfigletHistory(key string, start int,
+	end int) (*ConfigletHistoryList, error) {
+	return c.GetConfigletHistoryCtx(context.Background(), key, start, end)
+}
+
+// GetConfigletHistoryCtx is the context aware version of GetConfigletHistory.
+func (c CvpRestAPI) GetConfigletHistoryCtx(ctx context.Context, key string, start int,
 	end int) (*ConfigletHistoryList, error) {
 	var info ConfigletHistoryList
 
@@ -225,9 +263,9 @@ func (c CvpRestAPI) GetConfigletHistory(key string, start int,
 		"endIndex":    {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/configlet/getConfigletHistory.do", query)
+	resp, err := c.get(ctx, "/configlet/getConfigletHistory.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetConfigletHistory: %s", err)
+		return nil, wrapError("GetConfigletHistory", "/configlet/getConfigletHistory.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -235,7 +273,7 @@ func (c CvpRestAPI) GetConfigletHistory(key string, start int,
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetConfigletHistory: %s", err)
+		return nil, wrapError("GetConfigletHistory", "/configlet/getConfigletHistory.do", err)
 	}
 
 	return &info, nil
@@ -243,11 +281,23 @@ func (c CvpRestAPI) GetConfigletHistory(key string, start int,
 
 // GetAllConfigletHistory returns all the history for a given configlet
 func (c CvpRestAPI) GetAllConfigletHistory(key string) (*ConfigletHistoryList, error) {
-	return c.GetConfigletHistory(key, 0, 0)
+	return c.GetAllConfigletHistoryCtx(context.Background(), key)
+}
+
+// GetAllConfigletHistoryCtx is the context aware version of GetAllConfigletHistory.
+func (c CvpRestAPI) GetAllConfigletHistoryCtx(ctx context.Context,
+	key string) (*ConfigletHistoryList, error) {
+	return c.GetConfigletHistoryCtx(ctx, key, 0, 0)
 }
 
 // AddConfiglet creates/adds a configlet
 func (c CvpRestAPI) AddConfiglet(name string, config string) (*Configlet, error) {
+	return c.AddConfigletCtx(context.Background(), name, config)
+}
+
+// AddConfigletCtx is the context aware version of AddConfiglet.
+func (c CvpRestAPI) AddConfigletCtx(ctx context.Context, name string,
+	config string) (*Configlet, error) {
 	var info ConfigletOpReturn
 
 	data := map[string]string{
@@ -255,9 +305,9 @@ func (c CvpRestAPI) AddConfiglet(name string, config string) (*Configlet, error)
 		"config": config,
 	}
 
-	resp, err := c.client.Post("/configlet/addConfiglet.do", nil, data)
+	resp, err := c.post(ctx, "/configlet/addConfiglet.do", nil, data)
 	if err != nil {
-		return nil, errors.Errorf("AddConfiglet: %s", err)
+		return nil, wrapError("AddConfiglet", "/configlet/addConfiglet.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -265,7 +315,7 @@ func (c CvpRestAPI) AddConfiglet(name string, config string) (*Configlet, error)
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("AddConfiglet: %s", err)
+		return nil, wrapError("AddConfiglet", "/configlet/addConfiglet.do", err)
 	}
 
 	return &info.Data, nil
@@ -273,6 +323,11 @@ func (c CvpRestAPI) AddConfiglet(name string, config string) (*Configlet, error)
 
 // DeleteConfiglet deletes a configlet.
 func (c CvpRestAPI) DeleteConfiglet(name string, key string) error {
+	return c.DeleteConfigletCtx(context.Background(), name, key)
+}
+
+// DeleteConfigletCtx is the context aware version of DeleteConfiglet.
+func (c CvpRestAPI) DeleteConfigletCtx(ctx context.Context, name string, key string) error {
 	var info ErrorResponse
 
 	data := []map[string]string{
@@ -281,9 +336,9 @@ func (c CvpRestAPI) DeleteConfiglet(name string, key string) error {
 			"key":  key,
 		},
 	}
-	resp, err := c.client.Post("/configlet/deleteConfiglet.do", nil, data)
+	resp, err := c.post(ctx, "/configlet/deleteConfiglet.do", nil, data)
 	if err != nil {
-		return errors.Errorf("DeleteConfiglet: %s", err)
+		return wrapError("DeleteConfiglet", "/configlet/deleteConfiglet.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -291,14 +346,14 @@ func (c CvpRestAPI) DeleteConfiglet(name string, key string) error {
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("DeleteConfiglet: %s", err)
+		return wrapError("DeleteConfiglet", "/configlet/deleteConfiglet.do", err)
 	}
 
 	return nil
 }
 
 // updateConfiglet updates a configlet.
-func (c CvpRestAPI) updateConfiglet(config string, name string, key string,
+func (c CvpRestAPI) updateConfiglet(ctx context.Context, config string, name string, key string,
 	waitForTaskIds bool) (*ConfigletUpdateReturn, error) {
 	var info ConfigletUpdateReturn
 
@@ -314,7 +369,7 @@ func (c CvpRestAPI) updateConfiglet(config string, name string, key string,
 		WaitForTaskIds: waitForTaskIds,
 	}
 
-	resp, err := c.client.Post("/configlet/updateConfiglet.do", nil, data)
+	resp, err := c.post(ctx, "/configlet/updateConfiglet.do", nil, data)
 	if err != nil {
 		return nil, err
 	}
@@ -332,9 +387,15 @@ func (c CvpRestAPI) updateConfiglet(config string, name string, key string,
 
 // UpdateConfiglet updates a configlet.
 func (c CvpRestAPI) UpdateConfiglet(config string, name string, key string) error {
-	_, err := c.updateConfiglet(config, name, key, false)
+	return c.UpdateConfigletCtx(context.Background(), config, name, key)
+}
+
+// UpdateConfigletCtx is the context aware version of UpdateConfiglet.
+func (c CvpRestAPI) UpdateConfigletCtx(ctx context.Context, config string, name string,
+	key string) error {
+	_, err := c.updateConfiglet(ctx, config, name, key, false)
 	if err != nil {
-		return errors.Errorf("UpdateConfiglet: %s", err)
+		return errors.Wrap(err, "UpdateConfiglet")
 	}
 	return nil
 }
@@ -342,23 +403,34 @@ func (c CvpRestAPI) UpdateConfiglet(config string, name string, key string) erro
 // UpdateConfigletWaitForTask updates a configlet and waits for tasks to be returned.
 func (c CvpRestAPI) UpdateConfigletWaitForTask(config string, name string, key string) ([]string,
 	error) {
-	data, err := c.updateConfiglet(config, name, key, true)
+	return c.UpdateConfigletWaitForTaskCtx(context.Background(), config, name, key)
+}
+
+// UpdateConfigletWaitForTaskCtx is the context aware version of UpdateConfigletWaitForTask.
+func (c CvpRestAPI) UpdateConfigletWaitForTaskCtx(ctx context.Context, config string, name string,
+	key string) ([]string, error) {
+	data, err := c.updateConfiglet(ctx, config, name, key, true)
 	if err != nil {
-		return nil, errors.Errorf("UpdateConfigletWaitForTask: %s", err)
+		return nil, errors.Wrap(err, "UpdateConfigletWaitForTask")
 	}
 	return data.TaskIDs, nil
 }
 
 // AddConfigletNote creates/adds a configlet note
 func (c CvpRestAPI) AddConfigletNote(key string, note string) error {
+	return c.AddConfigletNoteCtx(context.Background(), key, note)
+}
+
+// AddConfigletNoteCtx is the context aware version of AddConfigletNote.
+func (c CvpRestAPI) AddConfigletNoteCtx(ctx context.Context, key string, note string) error {
 	data := map[string]string{
 		"key":  key,
 		"note": note,
 	}
 
-	resp, err := c.client.Post("/configlet/addNoteToConfiglet.do", nil, data)
+	resp, err := c.post(ctx, "/configlet/addNoteToConfiglet.do", nil, data)
 	if err != nil {
-		return errors.Errorf("AddConfigletNote: %s", err)
+		return wrapError("AddConfigletNote", "/configlet/addNoteToConfiglet.do", err)
 	}
 
 	info := struct {
@@ -372,7 +444,7 @@ func (c CvpRestAPI) AddConfigletNote(key string, note string) error {
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("AddConfigletNote: %s", err)
+		return wrapError("AddConfigletNote", "/configlet/addNoteToConfiglet.do", err)
 	}
 
 	return nil
@@ -380,13 +452,18 @@ func (c CvpRestAPI) AddConfigletNote(key string, note string) error {
 
 // VerifyConfig verifies a configlet config config
 func (c CvpRestAPI) VerifyConfig(netElement string, config string) error {
+	return c.VerifyConfigCtx(context.Background(), netElement, config)
+}
+
+// VerifyConfigCtx is the context aware version of VerifyConfig.
+func (c CvpRestAPI) VerifyConfigCtx(ctx context.Context, netElement string, config string) error {
 	var info ConfigletVerifyResp
 	data := map[string]string{
 		"config":       config,
 		"netElementId": netElement,
 	}
 
-	resp, err := c.client.Post("/configlet/validateConfig.do", nil, data)
+	resp, err := c.post(ctx, "/configlet/validateConfig.do", nil, data)
 	if err != nil {
 		return errors.Wrap(err, "VerifyConfig")
 	}
@@ -407,6 +484,12 @@ func (c CvpRestAPI) VerifyConfig(netElement string, config string) error {
 
 // SearchConfigletsWithRange search function for configlets.
 func (c CvpRestAPI) SearchConfigletsWithRange(searchStr string, start int,
+	end int) (*ConfigletList, error) {
+	return c.SearchConfigletsWithRangeCtx(context.Background(), searchStr, start, end)
+}
+
+// SearchConfigletsWithRangeCtx is the context aware version of SearchConfigletsWithRange.
+func (c CvpRestAPI) SearchConfigletsWithRangeCtx(ctx context.Context, searchStr string, start int,
 	end int) (*ConfigletList, error) {
 	var info ConfigletList
 
@@ -416,9 +499,9 @@ func (c CvpRestAPI) SearchConfigletsWithRange(searchStr string, start int,
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/configlet/searchConfiglets.do", query)
+	resp, err := c.get(ctx, "/configlet/searchConfiglets.do", query)
 	if err != nil {
-		return nil, errors.Errorf("SearchConfiglets: %s", err)
+		return nil, wrapError("SearchConfiglets", "/configlet/searchConfiglets.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -426,7 +509,7 @@ func (c CvpRestAPI) SearchConfigletsWithRange(searchStr string, start int,
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("SearchConfiglets: %s", err)
+		return nil, wrapError("SearchConfiglets", "/configlet/searchConfiglets.do", err)
 	}
 
 	return &info, nil
@@ -434,17 +517,35 @@ func (c CvpRestAPI) SearchConfigletsWithRange(searchStr string, start int,
 
 // SearchConfiglets search function for configlets.
 func (c CvpRestAPI) SearchConfiglets(searchStr string) (*ConfigletList, error) {
-	return c.SearchConfigletsWithRange(searchStr, 0, 0)
+	return c.SearchConfigletsCtx(context.Background(), searchStr)
+}
+
+// SearchConfigletsCtx is the context aware version of SearchConfiglets.
+func (c CvpRestAPI) SearchConfigletsCtx(ctx context.Context,
+	searchStr string) (*ConfigletList, error) {
+	return c.SearchConfigletsWithRangeCtx(ctx, searchStr, 0, 0)
 }
 
 // GetAppliedDevices Returns a list of devices to which the named configlet is applied
 func (c CvpRestAPI) GetAppliedDevices(configletName string) ([]ObjectInfo, error) {
-	return c.GetAppliedDevicesWithRange(configletName, 0, 0)
+	return c.GetAppliedDevicesCtx(context.Background(), configletName)
+}
+
+// GetAppliedDevicesCtx is the context aware version of GetAppliedDevices.
+func (c CvpRestAPI) GetAppliedDevicesCtx(ctx context.Context,
+	configletName string) ([]ObjectInfo, error) {
+	return c.GetAppliedDevicesWithRangeCtx(ctx, configletName, 0, 0)
 }
 
 // GetAppliedDevicesWithRange Returns a list of devices to which the named configlet is applied
 func (c CvpRestAPI) GetAppliedDevicesWithRange(configletName string, start int,
 	end int) ([]ObjectInfo, error) {
+	return c.GetAppliedDevicesWithRangeCtx(context.Background(), configletName, start, end)
+}
+
+// GetAppliedDevicesWithRangeCtx is the context aware version of GetAppliedDevicesWithRange.
+func (c CvpRestAPI) GetAppliedDevicesWithRangeCtx(ctx context.Context, configletName string,
+	start int, end int) ([]ObjectInfo, error) {
 	var info GenericReq
 
 	query := &url.Values{
@@ -453,9 +554,9 @@ func (c CvpRestAPI) GetAppliedDevicesWithRange(configletName string, start int,
 		"endIndex":      {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/configlet/getAppliedDevices.do", query)
+	resp, err := c.get(ctx, "/configlet/getAppliedDevices.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetAppliedDevices: %s", err)
+		return nil, wrapError("GetAppliedDevices", "/configlet/getAppliedDevices.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -463,7 +564,7 @@ func (c CvpRestAPI) GetAppliedDevicesWithRange(configletName string, start int,
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetAppliedDevices: %s", err)
+		return nil, wrapError("GetAppliedDevices", "/configlet/getAppliedDevices.do", err)
 	}
 
 	return info.Data, nil
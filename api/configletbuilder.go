@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 
@@ -57,6 +58,13 @@ type BuilderMaps struct {
 
 // GetHierarchicalConfigletBuilders returns the configlet with the specified key
 func (c CvpRestAPI) GetHierarchicalConfigletBuilders(container *Container) (*BuilderInfo, error) {
+	return c.GetHierarchicalConfigletBuildersCtx(context.Background(), container)
+}
+
+// GetHierarchicalConfigletBuildersCtx is the context aware version of
+// GetHierarchicalConfigletBuilders.
+func (c CvpRestAPI) GetHierarchicalConfigletBuildersCtx(ctx context.Context,
+	container *Container) (*BuilderInfo, error) {
 	var info BuilderInfo
 
 	if container == nil {
@@ -70,9 +78,10 @@ func (c CvpRestAPI) GetHierarchicalConfigletBuilders(container *Container) (*Bui
 		"endIndex":    {"0"},
 	}
 
-	resp, err := c.client.Get("/configlet/getHierarchicalConfigletBuilders.do", query)
+	resp, err := c.get(ctx, "/configlet/getHierarchicalConfigletBuilders.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetHierarchicalConfigletBuilders: %s", err)
+		return nil, wrapError("GetHierarchicalConfigletBuilders",
+			"/configlet/getHierarchicalConfigletBuilders.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -105,15 +114,21 @@ type ConfigletBuilderResp struct {
 
 // GetConfigletBuilderByKey returns the configlet with the specified key
 func (c CvpRestAPI) GetConfigletBuilderByKey(key string) (*ConfigletBuilder, error) {
+	return c.GetConfigletBuilderByKeyCtx(context.Background(), key)
+}
+
+// GetConfigletBuilderByKeyCtx is the context aware version of GetConfigletBuilderByKey.
+func (c CvpRestAPI) GetConfigletBuilderByKeyCtx(ctx context.Context,
+	key string) (*ConfigletBuilder, error) {
 	var info ConfigletBuilderResp
 
 	query := &url.Values{
 		"type": {},
 		"id":   {key},
 	}
-	resp, err := c.client.Get("/configlet/getConfigletBuilder.do", query)
+	resp, err := c.get(ctx, "/configlet/getConfigletBuilder.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetConfigletBuilderByKey: %s", err)
+		return nil, wrapError("GetConfigletBuilderByKey", "/configlet/getConfigletBuilder.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -122,22 +137,28 @@ func (c CvpRestAPI) GetConfigletBuilderByKey(key string) (*ConfigletBuilder, err
 
 	if err := info.Error(); err != nil {
 		// Entity does not exist
-		if info.ErrorCode == "132801" {
+		if info.ErrorCode == ENTITY_DOES_NOT_EXIST {
 			return nil, nil
 		}
-		return nil, errors.Errorf("GetConfigletBuilderByKey: %s", err)
+		return nil, wrapError("GetConfigletBuilderByKey", "/configlet/getConfigletBuilder.do", err)
 	}
 	return &info.Data, nil
 }
 
 // GetConfigletBuilderByName returns the configlet with the specified key
 func (c CvpRestAPI) GetConfigletBuilderByName(name string) (*ConfigletBuilder, error) {
-	configlet, err := c.GetConfigletByName(name)
+	return c.GetConfigletBuilderByNameCtx(context.Background(), name)
+}
+
+// GetConfigletBuilderByNameCtx is the context aware version of GetConfigletBuilderByName.
+func (c CvpRestAPI) GetConfigletBuilderByNameCtx(ctx context.Context,
+	name string) (*ConfigletBuilder, error) {
+	configlet, err := c.GetConfigletByNameCtx(ctx, name)
 	if err != nil {
 		return nil, errors.Wrap(err, "GetConfigletBuilderByName")
 	}
 
-	builder, err := c.GetConfigletBuilderByKey(configlet.Key)
+	builder, err := c.GetConfigletBuilderByKeyCtx(ctx, configlet.Key)
 	return builder, errors.Wrap(err, "GetConfigletBuilderByName")
 }
 
@@ -167,6 +188,13 @@ type ConfigletExecStatus struct {
 // If devKeyList is empty, then exec on all devices in container
 func (c CvpRestAPI) GenerateAutoConfiglet(devKeyList []string, builderKey string,
 	containerKey string, pageType string) ([]ConfigletExecStatus, error) {
+	return c.GenerateAutoConfigletCtx(context.Background(), devKeyList, builderKey, containerKey,
+		pageType)
+}
+
+// GenerateAutoConfigletCtx is the context aware version of GenerateAutoConfiglet.
+func (c CvpRestAPI) GenerateAutoConfigletCtx(ctx context.Context, devKeyList []string,
+	builderKey string, containerKey string, pageType string) ([]ConfigletExecStatus, error) {
 	var info AutoConfigletResp
 
 	if pageType != "netelement" && pageType != "container" {
@@ -181,7 +209,7 @@ func (c CvpRestAPI) GenerateAutoConfiglet(devKeyList []string, builderKey string
 		"pageType":           pageType,
 	}
 
-	resp, err := c.client.Post("/configlet/autoConfigletGenerator.do", nil, data)
+	resp, err := c.post(ctx, "/configlet/autoConfigletGenerator.do", nil, data)
 	if err != nil {
 		return nil, errors.Wrap(err, "GenerateAutoConfiglet")
 	}
@@ -191,7 +219,7 @@ func (c CvpRestAPI) GenerateAutoConfiglet(devKeyList []string, builderKey string
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GenerateAutoConfiglet: %s", err)
+		return nil, wrapError("GenerateAutoConfiglet", "/configlet/autoConfigletGenerator.do", err)
 	}
 
 	for _, builderStatus := range info.Data {
@@ -206,6 +234,12 @@ func (c CvpRestAPI) GenerateAutoConfiglet(devKeyList []string, builderKey string
 
 // GenerateConfigletForDevice ...
 func (c CvpRestAPI) GenerateConfigletForDevice(dev *NetElement,
+	builder *ConfigletBuilder) (*Configlet, error) {
+	return c.GenerateConfigletForDeviceCtx(context.Background(), dev, builder)
+}
+
+// GenerateConfigletForDeviceCtx is the context aware version of GenerateConfigletForDevice.
+func (c CvpRestAPI) GenerateConfigletForDeviceCtx(ctx context.Context, dev *NetElement,
 	builder *ConfigletBuilder) (*Configlet, error) {
 	if dev == nil {
 		return nil, errors.Errorf("GenerateConfigletForDevice: dev nil")
@@ -215,7 +249,7 @@ func (c CvpRestAPI) GenerateConfigletForDevice(dev *NetElement,
 	}
 	pageType := "netelement"
 
-	builderConfiglet, err := c.GetConfigletByName(builder.Name)
+	builderConfiglet, err := c.GetConfigletByNameCtx(ctx, builder.Name)
 	if err != nil {
 		return nil, errors.Wrap(err, "GenerateConfigletForDevice")
 	}
@@ -224,7 +258,7 @@ func (c CvpRestAPI) GenerateConfigletForDevice(dev *NetElement,
 		return nil, errors.Errorf("GenerateConfigletForDevice: FormLists not supported")
 	}
 
-	builderStatus, err := c.GenerateAutoConfiglet([]string{dev.SystemMacAddress},
+	builderStatus, err := c.GenerateAutoConfigletCtx(ctx, []string{dev.SystemMacAddress},
 		builderConfiglet.Key,
 		"", pageType)
 	if err != nil {
@@ -238,6 +272,12 @@ func (c CvpRestAPI) GenerateConfigletForDevice(dev *NetElement,
 
 // GenerateConfigletForContainer ...
 func (c CvpRestAPI) GenerateConfigletForContainer(container *Container,
+	builder *ConfigletBuilder, devList []NetElement) ([]Configlet, error) {
+	return c.GenerateConfigletForContainerCtx(context.Background(), container, builder, devList)
+}
+
+// GenerateConfigletForContainerCtx is the context aware version of GenerateConfigletForContainer.
+func (c CvpRestAPI) GenerateConfigletForContainerCtx(ctx context.Context, container *Container,
 	builder *ConfigletBuilder, devList []NetElement) ([]Configlet, error) {
 	if container == nil {
 		return nil, errors.Errorf("GenerateConfigletForContainer: container nil")
@@ -253,7 +293,7 @@ func (c CvpRestAPI) GenerateConfigletForContainer(container *Container,
 
 	pageType := "container"
 
-	builderConfiglet, err := c.GetConfigletByName(builder.Name)
+	builderConfiglet, err := c.GetConfigletByNameCtx(ctx, builder.Name)
 	if err != nil {
 		return nil, errors.Wrap(err, "GenerateConfigletForContainer")
 	}
@@ -262,7 +302,7 @@ func (c CvpRestAPI) GenerateConfigletForContainer(container *Container,
 		return nil, errors.Errorf("GenerateConfigletForContainer: FormLists not supported")
 	}
 
-	builderStatus, err := c.GenerateAutoConfiglet(devMacList,
+	builderStatus, err := c.GenerateAutoConfigletCtx(ctx, devMacList,
 		builderConfiglet.Key,
 		container.Key, pageType)
 	if err != nil {
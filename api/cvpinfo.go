@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/pkg/errors"
@@ -49,11 +50,16 @@ func (i CvpInfo) String() string {
 
 // GetCvpInfo returns the CvpInfo from the Client connection.
 func (c CvpRestAPI) GetCvpInfo() (*CvpInfo, error) {
+	return c.GetCvpInfoCtx(context.Background())
+}
+
+// GetCvpInfoCtx is the context aware version of GetCvpInfo.
+func (c CvpRestAPI) GetCvpInfoCtx(ctx context.Context) (*CvpInfo, error) {
 	var info CvpInfo
 
-	resp, err := c.client.Get("/cvpInfo/getCvpInfo.do", nil)
+	resp, err := c.get(ctx, "/cvpInfo/getCvpInfo.do", nil)
 	if err != nil {
-		return nil, errors.Errorf("GetCvpInfo: %s", err)
+		return nil, wrapError("GetCvpInfo", "/cvpInfo/getCvpInfo.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
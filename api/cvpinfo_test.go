@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"reflect"
@@ -59,6 +60,18 @@ func Test_CvpInfoJsonError_UnitTest(t *testing.T) {
 	}
 }
 
+func Test_CvpInfoCtxCanceled_UnitTest(t *testing.T) {
+	client := NewMockClient("{}", nil)
+	api := NewCvpRestAPI(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := api.GetCvpInfoCtx(ctx); err == nil {
+		t.Fatal("Canceled context should return error")
+	}
+}
+
 func Test_CvpInfoValid_UnitTest(t *testing.T) {
 	expectedResp := &CvpInfo{}
 
@@ -31,7 +31,64 @@
 
 package cvpapi
 
-import "fmt"
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Sentinel errors for well known CVP error codes. A *CvpError carrying one
+// of these codes matches the sentinel using errors.Is.
+var (
+	ErrUnableToLogin                = errors.New("unable to login")
+	ErrDataAlreadyExists            = errors.New("data already exists")
+	ErrEntityDoesNotExist           = errors.New("entity does not exist")
+	ErrNetElementEntityDoesNotExist = errors.New("netelement entity does not exist")
+	ErrRoleAlreadyExists            = errors.New("role already exists")
+	ErrDefaultRoleDelete            = errors.New("default role can not be deleted")
+	ErrInvalidRole                  = errors.New("invalid role")
+	ErrUserAlreadyExists            = errors.New("user already exists")
+	ErrSuperuserDeleteAttempt       = errors.New("superuser can not be deleted")
+	ErrSuperuserEditAttempt         = errors.New("superuser can not be edited")
+	ErrInvalidUser                  = errors.New("invalid user")
+)
+
+// ErrUnsupportedVersion is matched using errors.Is by the *VersionError
+// returned by API calls the CVP release doesn't support
+var ErrUnsupportedVersion = errors.New("unsupported CVP version")
+
+// VersionError is returned, without contacting CVP, by an API call that
+// requires a newer CVP release than the one detected.
+type VersionError struct {
+	Op       string
+	Required Version
+	Version  Version
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%s: requires CVP >= %s, connected to CVP %s", e.Op, e.Required,
+		e.Version)
+}
+
+// Is reports whether target is ErrUnsupportedVersion.
+func (e *VersionError) Is(target error) bool {
+	return target == ErrUnsupportedVersion
+}
+
+var errorCodeSentinels = map[string]error{
+	UNABLE_TO_LOGIN:                  ErrUnableToLogin,
+	DATA_ALREADY_EXISTS:              ErrDataAlreadyExists,
+	ENTITY_DOES_NOT_EXIST:            ErrEntityDoesNotExist,
+	NETELEMENT_ENTITY_DOES_NOT_EXIST: ErrNetElementEntityDoesNotExist,
+	ROLE_ALREADY_EXISTS:              ErrRoleAlreadyExists,
+	DEFAULT_ROLE_DELETE:              ErrDefaultRoleDelete,
+	INVALID_ROLE:                     ErrInvalidRole,
+	USER_ALREADY_EXISTS:              ErrUserAlreadyExists,
+	SUPERUSER_DELETE_ATTEMPT:         ErrSuperuserDeleteAttempt,
+	SUPERUSER_EDIT_ATTEMPT:           ErrSuperuserEditAttempt,
+	INVALID_USER:                     ErrInvalidUser,
+}
 
 // ErrorResponse is the response sent during error conditions within CVP
 type ErrorResponse struct {
@@ -39,10 +96,11 @@ type ErrorResponse struct {
 	ErrorMessage string `json:"errorMessage"`
 }
 
-// Error returns error
+// Error returns a *CvpError if the response carries an error code or
+// message, nil otherwise.
 func (e *ErrorResponse) Error() error {
 	if e.ErrorCode != "" || e.ErrorMessage != "" {
-		return fmt.Errorf("%s", e)
+		return &CvpError{Code: e.ErrorCode, Message: e.ErrorMessage}
 	}
 	return nil
 }
@@ -50,3 +108,75 @@ func (e *ErrorResponse) Error() error {
 func (e *ErrorResponse) String() string {
 	return fmt.Sprintf("[%s] %s", e.ErrorCode, e.ErrorMessage)
 }
+
+// CvpError is an error reported by CVP, either as an error response body or
+// as an unexpected HTTP status. StatusCode is zero when the error was
+// returned in the body of an otherwise successful response.
+type CvpError struct {
+	Code       string
+	Message    string
+	Op         string
+	Endpoint   string
+	StatusCode int
+	// Header holds the headers of an error response, e.g. Retry-After
+	Header http.Header
+}
+
+func (e *CvpError) Error() string {
+	if e.Code == "" && e.Message == "" {
+		return fmt.Sprintf("Status [%d]", e.StatusCode)
+	}
+	if e.StatusCode != 0 && e.StatusCode != http.StatusOK {
+		return fmt.Sprintf("Status [%d] [%s] %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+}
+
+// Is reports whether target is the sentinel error for this error's code.
+func (e *CvpError) Is(target error) bool {
+	sentinel, ok := errorCodeSentinels[e.Code]
+	return ok && sentinel == target
+}
+
+// wrapError records the API operation and endpoint on any *CvpError within
+// err and prefixes err with op, keeping err available to errors.Is/As.
+func wrapError(op string, endpoint string, err error) error {
+	annotateError(op, endpoint, err)
+	return errors.Wrap(err, op)
+}
+
+// wrapErrorf is like wrapError but replaces the message with the provided
+// one.
+func wrapErrorf(op string, endpoint string, err error, format string,
+	args ...interface{}) error {
+	annotateError(op, endpoint, err)
+	return &messageError{msg: fmt.Sprintf(format, args...), err: err}
+}
+
+func annotateError(op string, endpoint string, err error) {
+	var cvpErr *CvpError
+	if !errors.As(err, &cvpErr) {
+		return
+	}
+	if cvpErr.Op == "" {
+		cvpErr.Op = op
+	}
+	if cvpErr.Endpoint == "" {
+		cvpErr.Endpoint = endpoint
+	}
+}
+
+// messageError is an error with its own message that still unwraps to the
+// underlying error.
+type messageError struct {
+	msg string
+	err error
+}
+
+func (e *messageError) Error() string {
+	return e.msg
+}
+
+func (e *messageError) Unwrap() error {
+	return e.err
+}
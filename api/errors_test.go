@@ -0,0 +1,145 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_CvpErrorIs_UnitTest(t *testing.T) {
+	respStr := `{"errorCode": "132801",
+				 "errorMessage": "Entity does not exist"}`
+
+	client := NewMockClient(respStr, nil)
+	api := NewCvpRestAPI(client)
+
+	_, err := api.GetTaskByID(5)
+	assert(t, err != nil, "Error should be returned")
+	assert(t, errors.Is(err, ErrEntityDoesNotExist), "Expected ErrEntityDoesNotExist. Got: %v", err)
+	assert(t, !errors.Is(err, ErrDataAlreadyExists), "Unexpected ErrDataAlreadyExists")
+	equals(t, "GetTaskByID: [132801] Entity does not exist", err.Error())
+}
+
+func Test_CvpErrorAs_UnitTest(t *testing.T) {
+	respStr := `{"errorCode": "122518",
+				 "errorMessage": "Data already exists"}`
+
+	client := NewMockClient(respStr, nil)
+	api := NewCvpRestAPI(client)
+
+	_, err := api.AddConfiglet("test", "config")
+	assert(t, err != nil, "Error should be returned")
+
+	var cvpErr *CvpError
+	assert(t, errors.As(err, &cvpErr), "Expected CvpError. Got: %v", err)
+	equals(t, DATA_ALREADY_EXISTS, cvpErr.Code)
+	equals(t, "Data already exists", cvpErr.Message)
+	equals(t, "AddConfiglet", cvpErr.Op)
+	equals(t, "/configlet/addConfiglet.do", cvpErr.Endpoint)
+	equals(t, 0, cvpErr.StatusCode)
+	assert(t, errors.Is(err, ErrDataAlreadyExists), "Expected ErrDataAlreadyExists")
+}
+
+func Test_CvpErrorOp_UnitTest(t *testing.T) {
+	client := NewMockClient(`{"errorCode": "132801", "errorMessage": "Entity does not exist"}`,
+		nil)
+	api := NewCvpRestAPI(client)
+
+	// Op is the name of the method called
+	calls := map[string]func() error{
+		"GetContainerInfoByID": func() error {
+			_, err := api.GetContainerInfoByID("container_1")
+			return err
+		},
+		"SaveInventory": func() error {
+			_, err := api.SaveInventory()
+			return err
+		},
+		"ClearAllTempActions": func() error {
+			_, err := api.ClearAllTempActions()
+			return err
+		},
+		"GetAllTempActions": func() error {
+			_, err := api.GetAllTempActions(0, 0)
+			return err
+		},
+		"DeleteLabelsByKey": func() error {
+			return api.DeleteLabelsByKey([]string{"key"})
+		},
+		"UpdateUser": func() error {
+			return api.UpdateUser("user", &SingleUser{})
+		},
+	}
+	for op, call := range calls {
+		var cvpErr *CvpError
+		err := call()
+		assert(t, errors.As(err, &cvpErr), "%s: Expected CvpError. Got: %v", op, err)
+		equals(t, op, cvpErr.Op)
+		equals(t, op+": [132801] Entity does not exist", err.Error())
+	}
+}
+
+func Test_CvpErrorClientError_UnitTest(t *testing.T) {
+	clientErr := &CvpError{StatusCode: 500, Endpoint: "/task/getTaskById.do"}
+
+	client := NewMockClient("", clientErr)
+	api := NewCvpRestAPI(client)
+
+	_, err := api.GetTaskByID(5)
+	equals(t, "GetTaskByID: Status [500]", err.Error())
+
+	var cvpErr *CvpError
+	assert(t, errors.As(err, &cvpErr), "Expected CvpError. Got: %v", err)
+	equals(t, 500, cvpErr.StatusCode)
+	equals(t, "GetTaskByID", cvpErr.Op)
+}
+
+func Test_CvpErrorCustomMessage_UnitTest(t *testing.T) {
+	client := NewMockClient(`{ "errorCode": "232518" }`, nil)
+	api := NewCvpRestAPI(client)
+
+	_, err := api.AddRole(&SingleRole{RoleData: Role{Key: "role_test"}})
+	equals(t, "AddRole: Role with key 'role_test' already exists", err.Error())
+	assert(t, errors.Is(err, ErrRoleAlreadyExists), "Expected ErrRoleAlreadyExists")
+
+	var cvpErr *CvpError
+	assert(t, errors.As(err, &cvpErr), "Expected CvpError. Got: %v", err)
+	equals(t, "/role/createRole.do", cvpErr.Endpoint)
+}
+
+func Test_CvpErrorString_UnitTest(t *testing.T) {
+	equals(t, "Status [404]", (&CvpError{StatusCode: 404}).Error())
+	equals(t, "[132801] not found", (&CvpError{Code: "132801", Message: "not found"}).Error())
+	equals(t, "Status [500] [100] oops",
+		(&CvpError{Code: "100", Message: "oops", StatusCode: 500}).Error())
+}
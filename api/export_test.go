@@ -41,6 +41,8 @@ import (
 	"strconv"
 	"testing"
 	"time"
+
+	"github.com/aristanetworks/go-cvprac/replay"
 )
 
 func GetNextTaskID(c *CvpRestAPI) int {
@@ -56,20 +58,13 @@ func GetNextTaskID(c *CvpRestAPI) int {
 }
 
 func monitorTask(c *CvpRestAPI, taskID int, status string) error {
-	sleepCount := 0
-
-	for ; sleepCount < 120; sleepCount++ {
-		taskResult, err := c.GetTaskByID(taskID)
-		if err != nil {
-			return err
-		}
-		if taskResult.WorkOrderUserDefinedStatus == status {
-			break
-		}
-		time.Sleep(time.Second * 3)
+	policy := TaskWaitPolicy{Interval: 3 * time.Second, Multiplier: 1, Timeout: 6 * time.Minute}
+	summary, err := c.WaitForTasks(policy, taskID)
+	if err != nil {
+		return err
 	}
-	if sleepCount >= 30 {
-		return fmt.Errorf("Create task timeout after 120 seconds")
+	if got := summary.Tasks[taskID].WorkOrderUserDefinedStatus; got != status {
+		return fmt.Errorf("Task %d is %s, expected %s", taskID, got, status)
 	}
 	return nil
 }
@@ -149,31 +144,60 @@ var devConfiglets []Configlet
 var debugFlag = flag.Bool("debug", false, "Enable debug")
 var unitTest = flag.Bool("unittest", false, "Run Unit Tests")
 var sysTest = flag.Bool("systest", false, "Run System Tests")
+var recordFile = flag.String("record", "", "Record the CVP responses into a fixture file")
+var replayFile = flag.String("replay", "",
+	"Replay the CVP responses of a fixture file instead of using cvp_node.gcfg")
+
+// recorder records the CVP responses when running with -record
+var recorder *replay.Recorder
 
 func TestMain(m *testing.M) {
 	var err error
 	flag.Parse()
 
-	// Get config data for setup
-	_, err = LoadConfigFile("cvp_node.gcfg")
-	if err != nil {
-		log.Fatal(err)
+	var testClient *RealClient
+	var username, password string
+	if *replayFile != "" {
+		// credentials are scrubbed from fixtures so any will do
+		replayer, err := replay.ReplayFile(*replayFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		testClient = NewRealClient("cvp.replay.invalid", "https", 443)
+		testClient.Client.SetTransport(replayer)
+		username, password = "cvpadmin", "replay"
+		fmt.Printf("Replaying %s\n", *replayFile)
+	} else {
+		// Get config data for setup
+		_, err = LoadConfigFile("cvp_node.gcfg")
+		if err != nil {
+			log.Fatal(err)
+		}
+		config := GetConfig()
+		// Setup our client
+		nodeID := config.GetNodeIds()[0]
+		node := config.Nodes[nodeID]
+		testClient = NewRealClient(nodeID, "https", 443)
+		username, password = node.getUsername(), node.getPassword()
+
+		fmt.Printf("Connecting to %s\n", nodeID)
+		fmt.Printf("Using creds %s/%s for testing\n", username, password)
 	}
-	config := GetConfig()
-	// Setup our client
-	nodeID := config.GetNodeIds()[0]
-	node := config.Nodes[nodeID]
-	testClient := NewRealClient(nodeID, "https", 443)
 	testClient.Client.Debug = *debugFlag
 
-	fmt.Printf("Connecting to %s\n", nodeID)
-	fmt.Printf("Using creds %s/%s for testing\n", node.getUsername(), node.getPassword())
+	if *recordFile != "" {
+		recorder, err = replay.NewRecorder(testClient.Client.GetClient().Transport)
+		if err != nil {
+			log.Fatal(err)
+		}
+		testClient.Client.SetTransport(recorder)
+	}
 
 	api = NewCvpRestAPI(testClient)
 
-	if _, err := api.Login(node.getUsername(), node.getPassword()); err != nil {
+	if _, err := api.Login(username, password); err != nil {
 		log.Printf("Login Failure: %s", err)
-		os.Exit(m.Run())
+		os.Exit(runTests(m))
 	}
 
 	// verify we have at least one device in inventory
@@ -212,5 +236,19 @@ func TestMain(m *testing.M) {
 	fmt.Printf("Device:            %s\n", dev.Fqdn)
 	fmt.Printf("Device Container:  %s\n", devContainer.Name)
 	fmt.Printf("Device Configlets: %v\n", devConfiglets)
-	os.Exit(m.Run())
+	os.Exit(runTests(m))
+}
+
+// runTests runs the tests and saves the recorded fixture when running with
+// -record
+func runTests(m *testing.M) int {
+	code := m.Run()
+	if recorder != nil {
+		if err := recorder.Save(*recordFile); err != nil {
+			log.Printf("Saving fixture: %s", err)
+			return 1
+		}
+		fmt.Printf("Recorded %s\n", *recordFile)
+	}
+	return code
 }
@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"strings"
@@ -158,6 +159,12 @@ type SaveInventoryData struct {
 	Connecting                       string `json:"Connecting"`
 }
 
+// inventoryEndpoints are the paths of GetInventory over the CVP releases
+var inventoryEndpoints = endpoints{
+	{since: MustParseVersion("2019.0.0"), path: "/inventory/devices"},
+	{path: "/inventory/getInventory.do"},
+}
+
 // GetInventory returns a CvpInventoryList based on a provided query and range.
 //
 // Failed search returns empty
@@ -167,14 +174,27 @@ type SaveInventoryData struct {
 //   "netElementList": []
 // }
 func (c CvpRestAPI) GetInventory() ([]NetElement, error) {
+	return c.GetInventoryCtx(context.Background())
+}
+
+// GetInventoryCtx is the context aware version of GetInventory.
+func (c CvpRestAPI) GetInventoryCtx(ctx context.Context) ([]NetElement, error) {
+	endpoint, err := c.resolve(ctx, "GetInventory", inventoryEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint != "/inventory/devices" {
+		return c.getInventoryLegacy(ctx, endpoint)
+	}
+
 	var info []NetElement
 	query := &url.Values{
 		"provisioned": {"true"},
 	}
 
-	resp, err := c.client.Get("/inventory/devices", query)
+	resp, err := c.get(ctx, endpoint, query)
 	if err != nil {
-		return nil, errors.Errorf("GetInventory: %s", err)
+		return nil, wrapError("GetInventory", endpoint, err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -184,6 +204,36 @@ func (c CvpRestAPI) GetInventory() ([]NetElement, error) {
 	return info, nil
 }
 
+// getInventoryLegacy returns the inventory of CVP releases predating
+// /inventory/devices, which wrap the devices in a search result.
+func (c CvpRestAPI) getInventoryLegacy(ctx context.Context, endpoint string) ([]NetElement,
+	error) {
+	var info struct {
+		NetElementList []NetElement `json:"netElementList"`
+
+		ErrorResponse
+	}
+	query := &url.Values{
+		"queryparam": {""},
+		"startIndex": {"0"},
+		"endIndex":   {"0"},
+	}
+
+	resp, err := c.get(ctx, endpoint, query)
+	if err != nil {
+		return nil, wrapError("GetInventory", endpoint, err)
+	}
+
+	if err = json.Unmarshal(resp, &info); err != nil {
+		return nil, errors.Errorf("GetInventory: %s Payload:\n%s", err, resp)
+	}
+
+	if err := info.Error(); err != nil {
+		return nil, wrapError("GetInventory", endpoint, err)
+	}
+	return info.NetElementList, nil
+}
+
 // GetInventoryConfiguration returns a CvpInventoryConfiguration based on a provided MAC Address.
 //
 // Failed search returns empty
@@ -192,15 +242,22 @@ func (c CvpRestAPI) GetInventory() ([]NetElement, error) {
 //   "warnings": [],
 // }
 func (c CvpRestAPI) GetInventoryConfiguration(
+	macAddress string) (*CvpInventoryConfiguration, error) {
+	return c.GetInventoryConfigurationCtx(context.Background(), macAddress)
+}
+
+// GetInventoryConfigurationCtx is the context aware version of GetInventoryConfiguration.
+func (c CvpRestAPI) GetInventoryConfigurationCtx(ctx context.Context,
 	macAddress string) (*CvpInventoryConfiguration, error) {
 	var info CvpInventoryConfiguration
 	query := &url.Values{
 		"netElementId": {macAddress},
 	}
 
-	resp, err := c.client.Get("/inventory/getInventoryConfiguration.do", query)
+	resp, err := c.get(ctx, "/inventory/getInventoryConfiguration.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetInventoryConfiguration: %s", err)
+		return nil, wrapError("GetInventoryConfiguration",
+			"/inventory/getInventoryConfiguration.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -209,7 +266,8 @@ func (c CvpRestAPI) GetInventoryConfiguration(
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetInventoryConfiguration: %s", err)
+		return nil, wrapError("GetInventoryConfiguration",
+			"/inventory/getInventoryConfiguration.do", err)
 	}
 
 	return &info, nil
@@ -217,15 +275,25 @@ func (c CvpRestAPI) GetInventoryConfiguration(
 
 // GetAllDevices returns CvpInventoryList of all current inventory
 func (c CvpRestAPI) GetAllDevices() ([]NetElement, error) {
-	ret, err := c.GetInventory()
+	return c.GetAllDevicesCtx(context.Background())
+}
+
+// GetAllDevicesCtx is the context aware version of GetAllDevices.
+func (c CvpRestAPI) GetAllDevicesCtx(ctx context.Context) ([]NetElement, error) {
+	ret, err := c.GetInventoryCtx(ctx)
 	return ret, errors.Wrap(err, "GetAllDevices")
 }
 
 // GetDeviceByName returns a CvpInventoryList based on device name provided
 func (c CvpRestAPI) GetDeviceByName(fqdn string) (*NetElement, error) {
-	data, err := c.GetInventory()
+	return c.GetDeviceByNameCtx(context.Background(), fqdn)
+}
+
+// GetDeviceByNameCtx is the context aware version of GetDeviceByName.
+func (c CvpRestAPI) GetDeviceByNameCtx(ctx context.Context, fqdn string) (*NetElement, error) {
+	data, err := c.GetInventoryCtx(ctx)
 	if err != nil {
-		return nil, errors.Errorf("GetDeviceByName: %s", err)
+		return nil, errors.Wrap(err, "GetDeviceByName")
 	}
 
 	for idx, device := range data {
@@ -238,9 +306,14 @@ func (c CvpRestAPI) GetDeviceByName(fqdn string) (*NetElement, error) {
 
 // GetDeviceByID returns NetElement info related to a device mac.
 func (c CvpRestAPI) GetDeviceByID(mac string) (*NetElement, error) {
-	data, err := c.GetInventory()
+	return c.GetDeviceByIDCtx(context.Background(), mac)
+}
+
+// GetDeviceByIDCtx is the context aware version of GetDeviceByID.
+func (c CvpRestAPI) GetDeviceByIDCtx(ctx context.Context, mac string) (*NetElement, error) {
+	data, err := c.GetInventoryCtx(ctx)
 	if err != nil {
-		return nil, errors.Errorf("GetDeviceByName: %s", err)
+		return nil, errors.Wrap(err, "GetDeviceByName")
 	}
 
 	for idx, device := range data {
@@ -253,16 +326,22 @@ func (c CvpRestAPI) GetDeviceByID(mac string) (*NetElement, error) {
 
 // GetDevicesInContainer returns a CvpInventoryList based on container name provided
 func (c CvpRestAPI) GetDevicesInContainer(name string) ([]NetElement, error) {
-	containerInfo, err := c.GetContainerByName(name)
+	return c.GetDevicesInContainerCtx(context.Background(), name)
+}
+
+// GetDevicesInContainerCtx is the context aware version of GetDevicesInContainer.
+func (c CvpRestAPI) GetDevicesInContainerCtx(ctx context.Context,
+	name string) ([]NetElement, error) {
+	containerInfo, err := c.GetContainerByNameCtx(ctx, name)
 	if err != nil {
-		return nil, errors.Errorf("GetDevicesInContainer: %s", err)
+		return nil, errors.Wrap(err, "GetDevicesInContainer")
 	} else if containerInfo == nil {
 		return nil, nil
 	}
 
-	data, err := c.GetAllDevices()
+	data, err := c.GetAllDevicesCtx(ctx)
 	if err != nil {
-		return nil, errors.Errorf("GetDevicesInContainer: %s", err)
+		return nil, errors.Wrap(err, "GetDevicesInContainer")
 	} else if data == nil {
 		return nil, nil
 	}
@@ -278,11 +357,16 @@ func (c CvpRestAPI) GetDevicesInContainer(name string) ([]NetElement, error) {
 
 // GetUndefinedDevices returns a NetElement list of devices within the Undefined container
 func (c CvpRestAPI) GetUndefinedDevices() ([]NetElement, error) {
+	return c.GetUndefinedDevicesCtx(context.Background())
+}
+
+// GetUndefinedDevicesCtx is the context aware version of GetUndefinedDevices.
+func (c CvpRestAPI) GetUndefinedDevicesCtx(ctx context.Context) ([]NetElement, error) {
 	var res []NetElement
 
-	data, err := c.GetInventory()
+	data, err := c.GetInventoryCtx(ctx)
 	if err != nil {
-		return nil, errors.Errorf("GetUndefinedDevices: %s", err)
+		return nil, errors.Wrap(err, "GetUndefinedDevices")
 	}
 
 	for _, netElement := range data {
@@ -295,9 +379,14 @@ func (c CvpRestAPI) GetUndefinedDevices() ([]NetElement, error) {
 
 // GetDeviceContainer returns a Container this device is allocated to
 func (c CvpRestAPI) GetDeviceContainer(mac string) (*Container, error) {
-	data, err := c.SearchTopology(mac)
+	return c.GetDeviceContainerCtx(context.Background(), mac)
+}
+
+// GetDeviceContainerCtx is the context aware version of GetDeviceContainer.
+func (c CvpRestAPI) GetDeviceContainerCtx(ctx context.Context, mac string) (*Container, error) {
+	data, err := c.SearchTopologyCtx(ctx, mac)
 	if err != nil {
-		return nil, errors.Errorf("GetDeviceContainer: %s", err)
+		return nil, errors.Wrap(err, "GetDeviceContainer")
 	}
 
 	var containerName string
@@ -311,7 +400,7 @@ func (c CvpRestAPI) GetDeviceContainer(mac string) (*Container, error) {
 	if containerName == "" {
 		return nil, errors.Errorf("Device [%s] not of any Container", mac)
 	}
-	return c.GetContainerByName(containerName)
+	return c.GetContainerByNameCtx(ctx, containerName)
 }
 
 // Container is
@@ -326,6 +415,11 @@ type Container struct {
 // GetContainer returns
 // The endpoint searchContainers.do will not return the Undefined_Container in the list
 func (c CvpRestAPI) GetContainer(name string) ([]Container, error) {
+	return c.GetContainerCtx(context.Background(), name)
+}
+
+// GetContainerCtx is the context aware version of GetContainer.
+func (c CvpRestAPI) GetContainerCtx(ctx context.Context, name string) ([]Container, error) {
 	var info []Container
 	var query *url.Values
 
@@ -335,9 +429,9 @@ func (c CvpRestAPI) GetContainer(name string) ([]Container, error) {
 		}
 	}
 
-	resp, err := c.client.Get("/inventory/containers", query)
+	resp, err := c.get(ctx, "/inventory/containers", query)
 	if err != nil {
-		return nil, errors.Errorf("GetContainer: %s", err)
+		return nil, wrapError("GetContainer", "/inventory/containers", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -349,14 +443,24 @@ func (c CvpRestAPI) GetContainer(name string) ([]Container, error) {
 
 // GetAllContainers returns all current inventory Containers
 func (c CvpRestAPI) GetAllContainers() ([]Container, error) {
-	return c.GetContainer("")
+	return c.GetAllContainersCtx(context.Background())
+}
+
+// GetAllContainersCtx is the context aware version of GetAllContainers.
+func (c CvpRestAPI) GetAllContainersCtx(ctx context.Context) ([]Container, error) {
+	return c.GetContainerCtx(ctx, "")
 }
 
 // GetContainerByName returns a Container
 func (c CvpRestAPI) GetContainerByName(name string) (*Container, error) {
-	containers, err := c.GetContainer(name)
+	return c.GetContainerByNameCtx(context.Background(), name)
+}
+
+// GetContainerByNameCtx is the context aware version of GetContainerByName.
+func (c CvpRestAPI) GetContainerByNameCtx(ctx context.Context, name string) (*Container, error) {
+	containers, err := c.GetContainerCtx(ctx, name)
 	if err != nil {
-		return nil, errors.Errorf("GetContainerByName: %s", err)
+		return nil, errors.Wrap(err, "GetContainerByName")
 	}
 	for _, container := range containers {
 		// Container names are not case sensitive
@@ -381,6 +485,12 @@ type ContainerInfo struct {
 
 // GetContainerInfoByID returns ContainerInfo
 func (c CvpRestAPI) GetContainerInfoByID(id string) (*ContainerInfo, error) {
+	return c.GetContainerInfoByIDCtx(context.Background(), id)
+}
+
+// GetContainerInfoByIDCtx is the context aware version of GetContainerInfoByID.
+func (c CvpRestAPI) GetContainerInfoByIDCtx(ctx context.Context,
+	id string) (*ContainerInfo, error) {
 	var query *url.Values
 
 	query = &url.Values{
@@ -392,9 +502,9 @@ func (c CvpRestAPI) GetContainerInfoByID(id string) (*ContainerInfo, error) {
 		ErrorResponse
 	}{}
 
-	resp, err := c.client.Get("/provisioning/getContainerInfoById.do", query)
+	resp, err := c.get(ctx, "/provisioning/getContainerInfoById.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetContainerInfoByID: %s", err)
+		return nil, wrapError("GetContainerInfoByID", "/provisioning/getContainerInfoById.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &infoResp); err != nil {
@@ -402,16 +512,27 @@ func (c CvpRestAPI) GetContainerInfoByID(id string) (*ContainerInfo, error) {
 	}
 
 	if err := infoResp.Error(); err != nil {
-		return nil, errors.Errorf("GetNonConnectedDeviceCount: %s", err)
+		return nil, wrapError("GetContainerInfoByID",
+			"/provisioning/getContainerInfoById.do", err)
 	}
 	return &infoResp.ContainerInfo, nil
 }
 
 // GetNonConnectedDeviceCount returns number of devices not connected
 func (c CvpRestAPI) GetNonConnectedDeviceCount() (int, error) {
-	resp, err := c.client.Get("/inventory/add/getNonConnectedDeviceCount.do", nil)
+	return c.GetNonConnectedDeviceCountCtx(context.Background())
+}
+
+// GetNonConnectedDeviceCountCtx is the context aware version of GetNonConnectedDeviceCount.
+func (c CvpRestAPI) GetNonConnectedDeviceCountCtx(ctx context.Context) (int, error) {
+	if err := c.requires(ctx, "GetNonConnectedDeviceCount", addDeviceVersion); err != nil {
+		return -1, err
+	}
+
+	resp, err := c.get(ctx, "/inventory/add/getNonConnectedDeviceCount.do", nil)
 	if err != nil {
-		return -1, errors.Errorf("GetNonConnectedDeviceCount: %s", err)
+		return -1, wrapError("GetNonConnectedDeviceCount",
+			"/inventory/add/getNonConnectedDeviceCount.do", err)
 	}
 
 	info := struct {
@@ -424,27 +545,41 @@ func (c CvpRestAPI) GetNonConnectedDeviceCount() (int, error) {
 	}
 
 	if err := info.Error(); err != nil {
-		return -1, errors.Errorf("GetNonConnectedDeviceCount: %s", err)
+		return -1, wrapError("GetNonConnectedDeviceCount",
+			"/inventory/add/getNonConnectedDeviceCount.do", err)
 	}
 
 	return info.Data, nil
 }
 
+// addDeviceVersion is the CVP release that introduced the device onboarding
+// flow of counting the devices not yet connected and saving the inventory
+var addDeviceVersion = MustParseVersion("2018.2.0")
+
 // SaveInventory saves the current CVP inventory
 func (c CvpRestAPI) SaveInventory() (*SaveInventoryData, error) {
+	return c.SaveInventoryCtx(context.Background())
+}
+
+// SaveInventoryCtx is the context aware version of SaveInventory.
+func (c CvpRestAPI) SaveInventoryCtx(ctx context.Context) (*SaveInventoryData, error) {
 	var info SaveInventoryResp
 
-	resp, err := c.client.Post("/inventory/v2/saveInventory.do", nil, []string{})
+	if err := c.requires(ctx, "SaveInventory", addDeviceVersion); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(ctx, "/inventory/v2/saveInventory.do", nil, []string{})
 	if err != nil {
-		return nil, errors.Errorf("SaveInventory: %s", err)
+		return nil, wrapError("SaveInventory", "/inventory/v2/saveInventory.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
-		return nil, errors.Errorf("GetNonConnectedDeviceCount: %s Payload:\n%s", err, resp)
+		return nil, errors.Errorf("SaveInventory: %s Payload:\n%s", err, resp)
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetNonConnectedDeviceCount: %s", err)
+		return nil, wrapError("SaveInventory", "/inventory/v2/saveInventory.do", err)
 	}
 
 	return &info.Data, nil
@@ -453,6 +588,13 @@ func (c CvpRestAPI) SaveInventory() (*SaveInventoryData, error) {
 // AddToInventory Add device to the Cvp inventory. Warning -- Method doesn't check the
 // existance of the parent container
 func (c CvpRestAPI) AddToInventory(deviceIPAddress, parentContainerName,
+	parentContainerID string) error {
+	return c.AddToInventoryCtx(context.Background(), deviceIPAddress, parentContainerName,
+		parentContainerID)
+}
+
+// AddToInventoryCtx is the context aware version of AddToInventory.
+func (c CvpRestAPI) AddToInventoryCtx(ctx context.Context, deviceIPAddress, parentContainerName,
 	parentContainerID string) error {
 	urlParams := &url.Values{
 		"startIndex": {"0"},
@@ -475,24 +617,34 @@ func (c CvpRestAPI) AddToInventory(deviceIPAddress, parentContainerName,
 		},
 	}
 
-	_, err := c.client.Post("/inventory/add/addToInventory.do", urlParams, data)
+	_, err := c.post(ctx, "/inventory/add/addToInventory.do", urlParams, data)
 	return errors.Wrapf(err, "AddToInventor:")
 }
 
 // DeleteDevice Remove device from the Cvp inventory
 func (c CvpRestAPI) DeleteDevice(deviceMac string) error {
-	err := c.DeleteDevices([]string{deviceMac})
+	return c.DeleteDeviceCtx(context.Background(), deviceMac)
+}
+
+// DeleteDeviceCtx is the context aware version of DeleteDevice.
+func (c CvpRestAPI) DeleteDeviceCtx(ctx context.Context, deviceMac string) error {
+	err := c.DeleteDevicesCtx(ctx, []string{deviceMac})
 	return errors.Wrapf(err, "DeleteDevice:")
 }
 
 // DeleteDevices Remove devices from the Cvp inventory
 func (c CvpRestAPI) DeleteDevices(deviceMacs []string) error {
+	return c.DeleteDevicesCtx(context.Background(), deviceMacs)
+}
+
+// DeleteDevicesCtx is the context aware version of DeleteDevices.
+func (c CvpRestAPI) DeleteDevicesCtx(ctx context.Context, deviceMacs []string) error {
 	data := struct {
 		Data []string `json:"data"`
 	}{
 		Data: deviceMacs,
 	}
 
-	_, err := c.client.Post("/inventory/deleteDevices.do", nil, data)
+	_, err := c.post(ctx, "/inventory/deleteDevices.do", nil, data)
 	return errors.Wrapf(err, "DeleteDevices:")
 }
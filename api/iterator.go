@@ -0,0 +1,345 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"context"
+)
+
+// DefaultPageSize is the number of items an iterator requests per page when
+// no page size is given.
+const DefaultPageSize = 100
+
+// pageFunc fetches the items in the range [start, end) into the iterator's
+// current page and returns how many were fetched along with the total number
+// of items reported by CVP.
+type pageFunc func(ctx context.Context, start, end int) (n int, total int, err error)
+
+// pager walks a range based list endpoint one page at a time. The typed
+// iterators embed it and keep the items of the current page.
+type pager struct {
+	ctx   context.Context
+	size  int
+	fetch pageFunc
+
+	start int // index of the first item of the next page
+	idx   int // index of the current item within the page
+	n     int // number of items in the current page
+	total int // total reported by CVP, -1 until the first page is fetched
+	done  bool
+	err   error
+}
+
+func newPager(ctx context.Context, size int, fetch pageFunc) pager {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	return pager{ctx: ctx, size: size, fetch: fetch, idx: -1, total: -1}
+}
+
+// Next advances the iterator to the next item, fetching the next page when
+// the current one is exhausted. It returns false once all items reported by
+// CVP have been returned or an error occurred, see Err.
+func (p *pager) Next() bool {
+	if p.done {
+		return false
+	}
+	if p.idx+1 < p.n {
+		p.idx++
+		return true
+	}
+	if p.total >= 0 && p.start >= p.total {
+		p.done = true
+		return false
+	}
+	if err := p.ctx.Err(); err != nil {
+		p.err, p.done = err, true
+		return false
+	}
+	n, total, err := p.fetch(p.ctx, p.start, p.start+p.size)
+	if err != nil {
+		p.err, p.done = err, true
+		return false
+	}
+	p.start += n
+	p.n, p.idx, p.total = n, 0, total
+	if n == 0 {
+		// Guard against a total that is larger than what CVP will return.
+		p.done = true
+		return false
+	}
+	return true
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (p *pager) Err() error {
+	return p.err
+}
+
+// Total returns the total number of items reported by CVP, or -1 if no page
+// has been fetched yet.
+func (p *pager) Total() int {
+	return p.total
+}
+
+// TaskIterator iterates over the tasks returned by GetTasks.
+type TaskIterator struct {
+	pager
+	page []CvpTask
+}
+
+// Item returns the current task.
+func (it *TaskIterator) Item() CvpTask {
+	return it.page[it.idx]
+}
+
+// IterateTasks returns an iterator over the tasks matching queryStr that
+// fetches pageSize tasks per request.
+func (c CvpRestAPI) IterateTasks(queryStr string, pageSize int) *TaskIterator {
+	return c.IterateTasksCtx(context.Background(), queryStr, pageSize)
+}
+
+// IterateTasksCtx is the context aware version of IterateTasks.
+func (c CvpRestAPI) IterateTasksCtx(ctx context.Context, queryStr string,
+	pageSize int) *TaskIterator {
+	it := &TaskIterator{}
+	it.pager = newPager(ctx, pageSize, func(ctx context.Context, start, end int) (int, int, error) {
+		info, err := c.getTasks(ctx, queryStr, start, end)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = info.Data
+		return len(it.page), info.Total, nil
+	})
+	return it
+}
+
+// ConfigletIterator iterates over the configlets returned by GetConfigletsInfo.
+type ConfigletIterator struct {
+	pager
+	page []Configlet
+}
+
+// Item returns the current configlet.
+func (it *ConfigletIterator) Item() Configlet {
+	return it.page[it.idx]
+}
+
+// IterateConfiglets returns an iterator over all configlets that fetches
+// pageSize configlets per request.
+func (c CvpRestAPI) IterateConfiglets(pageSize int) *ConfigletIterator {
+	return c.IterateConfigletsCtx(context.Background(), pageSize)
+}
+
+// IterateConfigletsCtx is the context aware version of IterateConfiglets.
+func (c CvpRestAPI) IterateConfigletsCtx(ctx context.Context, pageSize int) *ConfigletIterator {
+	it := &ConfigletIterator{}
+	it.pager = newPager(ctx, pageSize, func(ctx context.Context, start, end int) (int, int, error) {
+		info, err := c.getConfigletsInfo(ctx, start, end)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = info.Data
+		return len(it.page), info.Total, nil
+	})
+	return it
+}
+
+// ImageIterator iterates over the images returned by GetImages.
+type ImageIterator struct {
+	pager
+	page []ImageInfo
+}
+
+// Item returns the current image.
+func (it *ImageIterator) Item() ImageInfo {
+	return it.page[it.idx]
+}
+
+// IterateImages returns an iterator over the images matching querystr that
+// fetches pageSize images per request.
+func (c CvpRestAPI) IterateImages(querystr string, pageSize int) *ImageIterator {
+	return c.IterateImagesCtx(context.Background(), querystr, pageSize)
+}
+
+// IterateImagesCtx is the context aware version of IterateImages.
+func (c CvpRestAPI) IterateImagesCtx(ctx context.Context, querystr string,
+	pageSize int) *ImageIterator {
+	it := &ImageIterator{}
+	it.pager = newPager(ctx, pageSize, func(ctx context.Context, start, end int) (int, int, error) {
+		resp, err := c.getImages(ctx, querystr, start, end)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = resp.Data
+		return len(it.page), resp.Total, nil
+	})
+	return it
+}
+
+// UserIterator iterates over the users returned by GetAllUsers.
+type UserIterator struct {
+	pager
+	page  []User
+	roles map[string][]string
+}
+
+// Item returns the current user.
+func (it *UserIterator) Item() User {
+	return it.page[it.idx]
+}
+
+// Roles returns the roles associated with the current user.
+func (it *UserIterator) Roles() []string {
+	return it.roles[it.page[it.idx].UserID]
+}
+
+// IterateUsers returns an iterator over all users that fetches pageSize
+// users per request.
+func (c CvpRestAPI) IterateUsers(pageSize int) *UserIterator {
+	return c.IterateUsersCtx(context.Background(), pageSize)
+}
+
+// IterateUsersCtx is the context aware version of IterateUsers.
+func (c CvpRestAPI) IterateUsersCtx(ctx context.Context, pageSize int) *UserIterator {
+	it := &UserIterator{}
+	it.pager = newPager(ctx, pageSize, func(ctx context.Context, start, end int) (int, int, error) {
+		users, err := c.GetAllUsersCtx(ctx, start, end)
+		if err != nil || users == nil {
+			return 0, 0, err
+		}
+		it.page, it.roles = users.Users, users.Roles
+		return len(it.page), users.Total, nil
+	})
+	return it
+}
+
+// RoleIterator iterates over the roles returned by GetAllRoles.
+type RoleIterator struct {
+	pager
+	page []Role
+}
+
+// Item returns the current role.
+func (it *RoleIterator) Item() Role {
+	return it.page[it.idx]
+}
+
+// IterateRoles returns an iterator over all roles that fetches pageSize
+// roles per request.
+func (c CvpRestAPI) IterateRoles(pageSize int) *RoleIterator {
+	return c.IterateRolesCtx(context.Background(), pageSize)
+}
+
+// IterateRolesCtx is the context aware version of IterateRoles.
+func (c CvpRestAPI) IterateRolesCtx(ctx context.Context, pageSize int) *RoleIterator {
+	it := &RoleIterator{}
+	it.pager = newPager(ctx, pageSize, func(ctx context.Context, start, end int) (int, int, error) {
+		roles, err := c.GetAllRolesCtx(ctx, start, end)
+		if err != nil || roles == nil {
+			return 0, 0, err
+		}
+		it.page = roles.Roles
+		return len(it.page), roles.Total, nil
+	})
+	return it
+}
+
+// ChangeControlIterator iterates over the change controls returned by
+// GetChangeControls.
+type ChangeControlIterator struct {
+	pager
+	page []ChangeControl
+}
+
+// Item returns the current change control.
+func (it *ChangeControlIterator) Item() ChangeControl {
+	return it.page[it.idx]
+}
+
+// IterateChangeControls returns an iterator over the change controls matching
+// querystr that fetches pageSize change controls per request.
+func (c CvpRestAPI) IterateChangeControls(querystr string,
+	pageSize int) *ChangeControlIterator {
+	return c.IterateChangeControlsCtx(context.Background(), querystr, pageSize)
+}
+
+// IterateChangeControlsCtx is the context aware version of IterateChangeControls.
+func (c CvpRestAPI) IterateChangeControlsCtx(ctx context.Context, querystr string,
+	pageSize int) *ChangeControlIterator {
+	it := &ChangeControlIterator{}
+	it.pager = newPager(ctx, pageSize, func(ctx context.Context, start, end int) (int, int, error) {
+		info, err := c.getChangeControls(ctx, querystr, start, end)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = info.Data
+		return len(it.page), info.Total, nil
+	})
+	return it
+}
+
+// NetElementIterator iterates over the devices returned by
+// SearchTopologyWithRange.
+type NetElementIterator struct {
+	pager
+	page []NetElement
+}
+
+// Item returns the current device.
+func (it *NetElementIterator) Item() NetElement {
+	return it.page[it.idx]
+}
+
+// IterateTopology returns an iterator over the devices matching querystr that
+// fetches pageSize devices per request.
+func (c CvpRestAPI) IterateTopology(querystr string, pageSize int) *NetElementIterator {
+	return c.IterateTopologyCtx(context.Background(), querystr, pageSize)
+}
+
+// IterateTopologyCtx is the context aware version of IterateTopology.
+func (c CvpRestAPI) IterateTopologyCtx(ctx context.Context, querystr string,
+	pageSize int) *NetElementIterator {
+	it := &NetElementIterator{}
+	it.pager = newPager(ctx, pageSize, func(ctx context.Context, start, end int) (int, int, error) {
+		resp, err := c.SearchTopologyWithRangeCtx(ctx, querystr, start, end)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = resp.NetElementList
+		return len(it.page), resp.Total, nil
+	})
+	return it
+}
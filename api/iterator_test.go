@@ -0,0 +1,278 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// PagingMockClient answers range based requests with a slice of 'total'
+// generated items and records the ranges requested.
+type PagingMockClient struct {
+	MockClient
+	total  int
+	report int
+	key    string
+	item   func(i int) interface{}
+	ranges [][2]int
+	failAt int
+}
+
+// NewPagingMockClient creates a PagingMockClient with 'total' items stored
+// under 'key' in the response
+func NewPagingMockClient(total int, key string, item func(i int) interface{}) *PagingMockClient {
+	return &PagingMockClient{total: total, report: total, key: key, item: item, failAt: -1}
+}
+
+// Get satisfies the api ClientInterface for Get operation
+func (c *PagingMockClient) Get(url string, params *url.Values) ([]byte, error) {
+	start, _ := strconv.Atoi(params.Get("startIndex"))
+	end, _ := strconv.Atoi(params.Get("endIndex"))
+	if c.failAt >= 0 && len(c.ranges) == c.failAt {
+		return nil, errors.New("Client error")
+	}
+	c.ranges = append(c.ranges, [2]int{start, end})
+	if end == 0 || end > c.total {
+		end = c.total
+	}
+	items := []interface{}{}
+	for i := start; i < end; i++ {
+		items = append(items, c.item(i))
+	}
+	return json.Marshal(map[string]interface{}{"total": c.report, c.key: items})
+}
+
+func taskItem(i int) interface{} {
+	return map[string]interface{}{"workOrderId": strconv.Itoa(i)}
+}
+
+func Test_CvpIterateTasks_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(25, "data", taskItem)
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateTasks("", 10)
+	equals(t, -1, it.Total())
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Item().WorkOrderID)
+	}
+	ok(t, it.Err())
+	equals(t, 25, len(ids))
+	equals(t, "0", ids[0])
+	equals(t, "24", ids[24])
+	equals(t, 25, it.Total())
+	equals(t, [][2]int{{0, 10}, {10, 20}, {20, 30}}, client.ranges)
+	assert(t, !it.Next(), "Next should keep returning false once done")
+}
+
+func Test_CvpIterateTasksStopsOnTotal_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(20, "data", taskItem)
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateTasks("", 10)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	ok(t, it.Err())
+	equals(t, 20, n)
+	// The total tells the iterator there is no third page to fetch
+	equals(t, 2, len(client.ranges))
+}
+
+func Test_CvpIterateTasksEmpty_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(0, "data", taskItem)
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateTasks("", 0)
+	assert(t, !it.Next(), "Next should return false without items")
+	ok(t, it.Err())
+	equals(t, 0, it.Total())
+	equals(t, [][2]int{{0, DefaultPageSize}}, client.ranges)
+}
+
+func Test_CvpIterateTasksShortTotal_UnitTest(t *testing.T) {
+	// CVP reports more items than it returns; iteration ends on an empty page
+	client := NewPagingMockClient(5, "data", taskItem)
+	client.report = 8
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateTasks("", 3)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	ok(t, it.Err())
+	equals(t, 5, n)
+	equals(t, [][2]int{{0, 3}, {3, 6}, {5, 8}}, client.ranges)
+}
+
+func Test_CvpIterateTasksRetError_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(25, "data", taskItem)
+	client.failAt = 1
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateTasks("", 10)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	equals(t, 10, n)
+	assert(t, it.Err() != nil, "Error should be returned")
+	equals(t, "GetTasks: Client error", it.Err().Error())
+	assert(t, !it.Next(), "Next should return false after an error")
+}
+
+func Test_CvpIterateTasksReturnError_UnitTest(t *testing.T) {
+	respStr := `{"errorCode": "112498",
+  				 "errorMessage": "Unauthorized User"}`
+
+	client := NewMockClient(respStr, nil)
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateTasks("", 10)
+	assert(t, !it.Next(), "Next should return false on error")
+	assert(t, it.Err() != nil, "Error should be returned")
+}
+
+func Test_CvpIterateTasksCanceled_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(25, "data", taskItem)
+	api := NewCvpRestAPI(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := api.IterateTasksCtx(ctx, "", 10)
+	assert(t, it.Next(), "Next should return the first task")
+	cancel()
+	for it.Next() {
+	}
+	equals(t, context.Canceled, it.Err())
+	equals(t, 1, len(client.ranges))
+}
+
+func Test_CvpIterateConfiglets_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(7, "data", func(i int) interface{} {
+		return map[string]interface{}{"name": fmt.Sprintf("cfglt%d", i)}
+	})
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateConfiglets(3)
+	var names []string
+	for it.Next() {
+		names = append(names, it.Item().Name)
+	}
+	ok(t, it.Err())
+	equals(t, 7, len(names))
+	equals(t, "cfglt6", names[6])
+}
+
+func Test_CvpIterateImages_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(4, "data", func(i int) interface{} {
+		return map[string]interface{}{"name": fmt.Sprintf("EOS-%d.swi", i)}
+	})
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateImages("", 2)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	ok(t, it.Err())
+	equals(t, 4, n)
+}
+
+func Test_CvpIterateUsers_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(3, "users", func(i int) interface{} {
+		return map[string]interface{}{"userId": fmt.Sprintf("user%d", i)}
+	})
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateUsers(2)
+	var users []string
+	for it.Next() {
+		users = append(users, it.Item().UserID)
+		equals(t, 0, len(it.Roles()))
+	}
+	ok(t, it.Err())
+	equals(t, []string{"user0", "user1", "user2"}, users)
+}
+
+func Test_CvpIterateRoles_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(3, "Roles", func(i int) interface{} {
+		return map[string]interface{}{"name": fmt.Sprintf("role%d", i)}
+	})
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateRoles(2)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	ok(t, it.Err())
+	equals(t, 3, n)
+}
+
+func Test_CvpIterateChangeControls_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(5, "data", func(i int) interface{} {
+		return map[string]interface{}{"ccName": fmt.Sprintf("cc%d", i)}
+	})
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateChangeControls("", 5)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	ok(t, it.Err())
+	equals(t, 5, n)
+	equals(t, 1, len(client.ranges))
+}
+
+func Test_CvpIterateTopology_UnitTest(t *testing.T) {
+	client := NewPagingMockClient(3, "netElementList", func(i int) interface{} {
+		return map[string]interface{}{"fqdn": fmt.Sprintf("leaf%d", i)}
+	})
+	api := NewCvpRestAPI(client)
+
+	it := api.IterateTopology("", 2)
+	var fqdns []string
+	for it.Next() {
+		fqdns = append(fqdns, it.Item().Fqdn)
+	}
+	ok(t, it.Err())
+	equals(t, []string{"leaf0", "leaf1", "leaf2"}, fqdns)
+}
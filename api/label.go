@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"strconv"
@@ -64,6 +65,12 @@ type Label struct {
 
 // GetLabels returns the labels for
 func (c CvpRestAPI) GetLabels(module, labelType, searchStr string,
+	start int, end int) ([]Label, error) {
+	return c.GetLabelsCtx(context.Background(), module, labelType, searchStr, start, end)
+}
+
+// GetLabelsCtx is the context aware version of GetLabels.
+func (c CvpRestAPI) GetLabelsCtx(ctx context.Context, module, labelType, searchStr string,
 	start int, end int) ([]Label, error) {
 	var info CvpLabelList
 
@@ -75,9 +82,9 @@ func (c CvpRestAPI) GetLabels(module, labelType, searchStr string,
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/label/getLabels.do", query)
+	resp, err := c.get(ctx, "/label/getLabels.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetLabels: %s", err)
+		return nil, wrapError("GetLabels", "/label/getLabels.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -85,16 +92,21 @@ func (c CvpRestAPI) GetLabels(module, labelType, searchStr string,
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetLabels: %s", err)
+		return nil, wrapError("GetLabels", "/label/getLabels.do", err)
 	}
 	return info.LabelList, nil
 }
 
 // GetLabel returns the label for name provided.
 func (c CvpRestAPI) GetLabel(name string) (*Label, error) {
-	labels, err := c.GetLabels("LABEL", "ALL", name, 0, 0)
+	return c.GetLabelCtx(context.Background(), name)
+}
+
+// GetLabelCtx is the context aware version of GetLabel.
+func (c CvpRestAPI) GetLabelCtx(ctx context.Context, name string) (*Label, error) {
+	labels, err := c.GetLabelsCtx(ctx, "LABEL", "ALL", name, 0, 0)
 	if err != nil {
-		return nil, errors.Errorf("GetLabel Failed: %v", err)
+		return nil, wrapErrorf("GetLabel", "", err, "GetLabel Failed: %v", err)
 	}
 	for idx, label := range labels {
 		if label.Name == name {
@@ -106,13 +118,18 @@ func (c CvpRestAPI) GetLabel(name string) (*Label, error) {
 
 // GetLabelInfo returns the label info for the specified labelID
 func (c CvpRestAPI) GetLabelInfo(labelID string) (*Label, error) {
+	return c.GetLabelInfoCtx(context.Background(), labelID)
+}
+
+// GetLabelInfoCtx is the context aware version of GetLabelInfo.
+func (c CvpRestAPI) GetLabelInfoCtx(ctx context.Context, labelID string) (*Label, error) {
 	var info Label
 
 	query := &url.Values{"labelId": {labelID}}
 
-	resp, err := c.client.Get("/label/getLabelInfo.do", query)
+	resp, err := c.get(ctx, "/label/getLabelInfo.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetLabelInfo: %s", err)
+		return nil, wrapError("GetLabelInfo", "/label/getLabelInfo.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -121,16 +138,22 @@ func (c CvpRestAPI) GetLabelInfo(labelID string) (*Label, error) {
 
 	if err := info.Error(); err != nil {
 		// Entity does not exist
-		if info.ErrorCode == "132801" {
+		if info.ErrorCode == ENTITY_DOES_NOT_EXIST {
 			return nil, nil
 		}
-		return nil, errors.Errorf("GetLabelInfo: %s", err)
+		return nil, wrapError("GetLabelInfo", "/label/getLabelInfo.do", err)
 	}
 	return &info, nil
 }
 
 // AddLabel adds a label
 func (c CvpRestAPI) AddLabel(name string, note string, labeltype string) (*Label, error) {
+	return c.AddLabelCtx(context.Background(), name, note, labeltype)
+}
+
+// AddLabelCtx is the context aware version of AddLabel.
+func (c CvpRestAPI) AddLabelCtx(ctx context.Context, name string, note string,
+	labeltype string) (*Label, error) {
 	var info Label
 
 	data := map[string]interface{}{
@@ -139,9 +162,9 @@ func (c CvpRestAPI) AddLabel(name string, note string, labeltype string) (*Label
 		"type": labeltype,
 	}
 
-	resp, err := c.client.Post("/label/addLabel.do", nil, data)
+	resp, err := c.post(ctx, "/label/addLabel.do", nil, data)
 	if err != nil {
-		return nil, errors.Errorf("AddLabel: %s", err)
+		return nil, wrapError("AddLabel", "/label/addLabel.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -149,7 +172,7 @@ func (c CvpRestAPI) AddLabel(name string, note string, labeltype string) (*Label
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("AddLabel: %s", err)
+		return nil, wrapError("AddLabel", "/label/addLabel.do", err)
 	}
 
 	return &info, nil
@@ -158,45 +181,65 @@ func (c CvpRestAPI) AddLabel(name string, note string, labeltype string) (*Label
 // DeleteLabelsByKey deletes a group of Labels using list of their respective
 // Keys.
 func (c CvpRestAPI) DeleteLabelsByKey(keys []string) error {
+	return c.DeleteLabelsByKeyCtx(context.Background(), keys)
+}
+
+// DeleteLabelsByKeyCtx is the context aware version of DeleteLabelsByKey.
+func (c CvpRestAPI) DeleteLabelsByKeyCtx(ctx context.Context, keys []string) error {
 	var info ErrorResponse
 
 	data := map[string][]string{
 		"data": keys,
 	}
 
-	resp, err := c.client.Post("/label/deleteLabel.do", nil, data)
+	resp, err := c.post(ctx, "/label/deleteLabel.do", nil, data)
 	if err != nil {
-		return errors.Errorf("DeleteLabel: %s", err)
+		return wrapError("DeleteLabelsByKey", "/label/deleteLabel.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
-		return errors.Errorf("DeleteLabel: %s Payload:\n%s", err, resp)
+		return errors.Errorf("DeleteLabelsByKey: %s Payload:\n%s", err, resp)
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("DeleteLabel: %s", err)
+		return wrapError("DeleteLabelsByKey", "/label/deleteLabel.do", err)
 	}
 	return nil
 }
 
 // DeleteLabelByKey deletes a Label using key.
 func (c CvpRestAPI) DeleteLabelByKey(key string) error {
-	return c.DeleteLabelsByKey([]string{key})
+	return c.DeleteLabelByKeyCtx(context.Background(), key)
+}
+
+// DeleteLabelByKeyCtx is the context aware version of DeleteLabelByKey.
+func (c CvpRestAPI) DeleteLabelByKeyCtx(ctx context.Context, key string) error {
+	return c.DeleteLabelsByKeyCtx(ctx, []string{key})
 }
 
 // DeleteLabelByName deletes a Label using its name
 func (c CvpRestAPI) DeleteLabelByName(name string) error {
-	label, err := c.GetLabel(name)
+	return c.DeleteLabelByNameCtx(context.Background(), name)
+}
+
+// DeleteLabelByNameCtx is the context aware version of DeleteLabelByName.
+func (c CvpRestAPI) DeleteLabelByNameCtx(ctx context.Context, name string) error {
+	label, err := c.GetLabelCtx(ctx, name)
 	if err != nil {
 		return errors.Wrap(err, "DeleteLabel")
 	} else if label == nil {
 		return nil
 	}
-	return c.DeleteLabelsByKey([]string{label.Key})
+	return c.DeleteLabelsByKeyCtx(ctx, []string{label.Key})
 }
 
 // UpdateLabel updates a configlet.
 func (c CvpRestAPI) UpdateLabel(name, key, note, labelType string) error {
+	return c.UpdateLabelCtx(context.Background(), name, key, note, labelType)
+}
+
+// UpdateLabelCtx is the context aware version of UpdateLabel.
+func (c CvpRestAPI) UpdateLabelCtx(ctx context.Context, name, key, note, labelType string) error {
 	var info ErrorResponse
 
 	data := map[string]string{
@@ -206,9 +249,9 @@ func (c CvpRestAPI) UpdateLabel(name, key, note, labelType string) error {
 		"type": labelType,
 	}
 
-	resp, err := c.client.Post("/label/updateLabel.do", nil, data)
+	resp, err := c.post(ctx, "/label/updateLabel.do", nil, data)
 	if err != nil {
-		return errors.Errorf("UpdateLabel: %s", err)
+		return wrapError("UpdateLabel", "/label/updateLabel.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -216,13 +259,18 @@ func (c CvpRestAPI) UpdateLabel(name, key, note, labelType string) error {
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("UpdateLabel: %s", err)
+		return wrapError("UpdateLabel", "/label/updateLabel.do", err)
 	}
 	return nil
 }
 
 // UpdateLabelNote updates a label note.
 func (c CvpRestAPI) UpdateLabelNote(key, note string) error {
+	return c.UpdateLabelNoteCtx(context.Background(), key, note)
+}
+
+// UpdateLabelNoteCtx is the context aware version of UpdateLabelNote.
+func (c CvpRestAPI) UpdateLabelNoteCtx(ctx context.Context, key, note string) error {
 	var info ErrorResponse
 
 	data := map[string]string{
@@ -230,9 +278,9 @@ func (c CvpRestAPI) UpdateLabelNote(key, note string) error {
 		"note": note,
 	}
 
-	resp, err := c.client.Post("/label/updateNotesToLabel.do", nil, data)
+	resp, err := c.post(ctx, "/label/updateNotesToLabel.do", nil, data)
 	if err != nil {
-		return errors.Errorf("UpdateLabelNote: %s", err)
+		return wrapError("UpdateLabelNote", "/label/updateNotesToLabel.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -240,7 +288,7 @@ func (c CvpRestAPI) UpdateLabelNote(key, note string) error {
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("UpdateLabelNote: %s", err)
+		return wrapError("UpdateLabelNote", "/label/updateNotesToLabel.do", err)
 	}
 	return nil
 }
@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/pkg/errors"
@@ -98,13 +99,18 @@ type User struct {
 
 // Login perform loging and save off cookies
 func (c *CvpRestAPI) Login(username, password string) (*LoginResp, error) {
+	return c.LoginCtx(context.Background(), username, password)
+}
+
+// LoginCtx is the context aware version of Login.
+func (c *CvpRestAPI) LoginCtx(ctx context.Context, username, password string) (*LoginResp, error) {
 	var resp LoginResp
 
 	auth := "{\"userId\":\"" + username + "\", \"password\":\"" + password + "\"}"
 
-	rawResp, err := c.client.Post("/login/authenticate.do", nil, auth)
+	rawResp, err := c.post(ctx, "/login/authenticate.do", nil, auth)
 	if err != nil {
-		return nil, errors.Errorf("Login: %s", err)
+		return nil, wrapError("Login", "/login/authenticate.do", err)
 	}
 
 	if err = json.Unmarshal(rawResp, &resp); err != nil {
@@ -112,7 +118,7 @@ func (c *CvpRestAPI) Login(username, password string) (*LoginResp, error) {
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("Login: %s", err)
+		return nil, wrapError("Login", "/login/authenticate.do", err)
 	}
 
 	return &resp, nil
@@ -120,11 +126,16 @@ func (c *CvpRestAPI) Login(username, password string) (*LoginResp, error) {
 
 // Logout perform logout
 func (c *CvpRestAPI) Logout() error {
+	return c.LogoutCtx(context.Background())
+}
+
+// LogoutCtx is the context aware version of Logout.
+func (c *CvpRestAPI) LogoutCtx(ctx context.Context) error {
 	var resp ErrorResponse
 
-	rawResp, err := c.client.Post("/login/logout.do", nil, nil)
+	rawResp, err := c.post(ctx, "/login/logout.do", nil, nil)
 	if err != nil {
-		return errors.Errorf("Logout: %s", err)
+		return wrapError("Logout", "/login/logout.do", err)
 	}
 
 	if err = json.Unmarshal(rawResp, &resp); err != nil {
@@ -132,7 +143,7 @@ func (c *CvpRestAPI) Logout() error {
 	}
 
 	if err := resp.Error(); err != nil {
-		return errors.Errorf("Logout: %s", err)
+		return wrapError("Logout", "/login/logout.do", err)
 	}
 	return nil
 }
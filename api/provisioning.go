@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"strconv"
@@ -350,6 +351,12 @@ type TaskInfo struct {
 
 // GetDeviceConfigletInfo returns all configlet info related to a device.
 func (c CvpRestAPI) GetDeviceConfigletInfo(mac string) (*ConfigletInfo, error) {
+	return c.GetDeviceConfigletInfoCtx(context.Background(), mac)
+}
+
+// GetDeviceConfigletInfoCtx is the context aware version of GetDeviceConfigletInfo.
+func (c CvpRestAPI) GetDeviceConfigletInfoCtx(ctx context.Context,
+	mac string) (*ConfigletInfo, error) {
 	var info ConfigletInfo
 	query := &url.Values{
 		"netElementId": {mac},
@@ -358,9 +365,10 @@ func (c CvpRestAPI) GetDeviceConfigletInfo(mac string) (*ConfigletInfo, error) {
 		"endIndex":     {"0"},
 	}
 
-	resp, err := c.client.Get("/provisioning/getConfigletsByNetElementId.do", query)
+	resp, err := c.get(ctx, "/provisioning/getConfigletsByNetElementId.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetDeviceConfigletInfo: %s", err)
+		return nil, wrapError("GetDeviceConfigletInfo",
+			"/provisioning/getConfigletsByNetElementId.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -368,21 +376,28 @@ func (c CvpRestAPI) GetDeviceConfigletInfo(mac string) (*ConfigletInfo, error) {
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetDeviceConfigletInfo: %s", err)
+		return nil, wrapError("GetDeviceConfigletInfo",
+			"/provisioning/getConfigletsByNetElementId.do", err)
 	}
 	return &info, nil
 }
 
 // GetConfigletsByDeviceID returns the list of configlets applied to a device.
 func (c CvpRestAPI) GetConfigletsByDeviceID(mac string) ([]Configlet, error) {
-	info, err := c.GetDeviceConfigletInfo(mac)
+	return c.GetConfigletsByDeviceIDCtx(context.Background(), mac)
+}
+
+// GetConfigletsByDeviceIDCtx is the context aware version of GetConfigletsByDeviceID.
+func (c CvpRestAPI) GetConfigletsByDeviceIDCtx(ctx context.Context,
+	mac string) ([]Configlet, error) {
+	info, err := c.GetDeviceConfigletInfoCtx(ctx, mac)
 	if err != nil {
-		return nil, errors.Errorf("GetConfigletsByDeviceID: %s", err)
+		return nil, errors.Wrap(err, "GetConfigletsByDeviceID")
 	}
 	return info.ConfigletList, nil
 }
 
-func (c CvpRestAPI) addTempAction(data interface{}) error {
+func (c CvpRestAPI) addTempAction(ctx context.Context, data interface{}) error {
 	var resp ErrorResponse
 
 	query := &url.Values{
@@ -391,9 +406,9 @@ func (c CvpRestAPI) addTempAction(data interface{}) error {
 		"nodeId":     {"root"},
 	}
 
-	reqResp, err := c.client.Post("/ztp/addTempAction.do", query, data)
+	reqResp, err := c.post(ctx, "/ztp/addTempAction.do", query, data)
 	if err != nil {
-		return errors.Errorf("addTempAction: %s", err)
+		return wrapError("addTempAction", "/ztp/addTempAction.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -401,7 +416,7 @@ func (c CvpRestAPI) addTempAction(data interface{}) error {
 	}
 
 	if err := resp.Error(); err != nil {
-		return errors.Errorf("addTempAction: %s", err)
+		return wrapError("addTempAction", "/ztp/addTempAction.do", err)
 	}
 	return nil
 }
@@ -411,13 +426,29 @@ func (c CvpRestAPI) addTempAction(data interface{}) error {
 // deletion of device. Return a list of taskIds created in response to saving
 // the topology.
 func (c CvpRestAPI) SaveTopology() (*TaskInfo, error) {
+	return c.SaveTopologyCtx(context.Background())
+}
+
+// saveTopologyEndpoints are the paths of SaveTopology over the CVP releases
+var saveTopologyEndpoints = endpoints{
+	{since: MustParseVersion("2018.2.0"), path: "/ztp/v2/saveTopology.do"},
+	{path: "/ztp/saveTopology.do"},
+}
+
+// SaveTopologyCtx is the context aware version of SaveTopology.
+func (c CvpRestAPI) SaveTopologyCtx(ctx context.Context) (*TaskInfo, error) {
 	resp := struct {
 		Data TaskInfo `json:"data"`
 	}{}
 
-	reqResp, err := c.client.Post("/ztp/v2/saveTopology.do", nil, []string{})
+	endpoint, err := c.resolve(ctx, "SaveTopology", saveTopologyEndpoints)
 	if err != nil {
-		return nil, errors.Errorf("SaveTopology: %s", err)
+		return nil, err
+	}
+
+	reqResp, err := c.post(ctx, endpoint, nil, []string{})
+	if err != nil {
+		return nil, wrapError("SaveTopology", endpoint, err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -430,13 +461,20 @@ func (c CvpRestAPI) SaveTopology() (*TaskInfo, error) {
 // ApplyConfigletsToDevice apply the configlets to the device.
 func (c CvpRestAPI) ApplyConfigletsToDevice(appName string, dev *NetElement, commit bool,
 	newConfiglets ...Configlet) (*TaskInfo, error) {
+	return c.ApplyConfigletsToDeviceCtx(context.Background(), appName, dev, commit,
+		newConfiglets...)
+}
+
+// ApplyConfigletsToDeviceCtx is the context aware version of ApplyConfigletsToDevice.
+func (c CvpRestAPI) ApplyConfigletsToDeviceCtx(ctx context.Context, appName string, dev *NetElement,
+	commit bool, newConfiglets ...Configlet) (*TaskInfo, error) {
 	if dev == nil {
 		return nil, errors.Errorf("ApplyConfigletsToDevice: nil NetElement")
 	}
 
-	configlets, err := c.GetConfigletsByDeviceID(dev.SystemMacAddress)
+	configlets, err := c.GetConfigletsByDeviceIDCtx(ctx, dev.SystemMacAddress)
 	if err != nil {
-		return nil, errors.Errorf("ApplyConfigletsToDevice: %s", err)
+		return nil, errors.Wrap(err, "ApplyConfigletsToDevice")
 	}
 
 	action, cnames, ckeys, cbnames, cbkeys, err := checkConfigMapping(configlets, newConfiglets)
@@ -482,26 +520,38 @@ func (c CvpRestAPI) ApplyConfigletsToDevice(appName string, dev *NetElement, com
 		},
 	}}
 
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("ApplyConfigletsToDevice: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "ApplyConfigletsToDevice")
 	}
 	if commit {
-		return c.SaveTopology()
+		return c.SaveTopologyCtx(ctx)
 	}
 	return nil, nil
 }
 
 // ApplyConfigletToDevice apply the configlets to the device.
 func (c CvpRestAPI) ApplyConfigletToDevice(appName string, dev *NetElement,
+	newConfiglet *Configlet, commit bool) (*TaskInfo, error) {
+	return c.ApplyConfigletToDeviceCtx(context.Background(), appName, dev, newConfiglet, commit)
+}
+
+// ApplyConfigletToDeviceCtx is the context aware version of ApplyConfigletToDevice.
+func (c CvpRestAPI) ApplyConfigletToDeviceCtx(ctx context.Context, appName string, dev *NetElement,
 	newConfiglet *Configlet, commit bool) (*TaskInfo, error) {
 	var newConfigletList []Configlet
 	newConfigletList = append(newConfigletList, *newConfiglet)
-	return c.ApplyConfigletsToDevice(appName, dev, commit, newConfigletList...)
+	return c.ApplyConfigletsToDeviceCtx(ctx, appName, dev, commit, newConfigletList...)
 }
 
 // ValidateConfigletsForDevice validate provided configlets for device.
 func (c CvpRestAPI) ValidateConfigletsForDevice(deviceMac string, configletKeys []string) (
 	*ValidateAndCompareConfigletsResp, error) {
+	return c.ValidateConfigletsForDeviceCtx(context.Background(), deviceMac, configletKeys)
+}
+
+// ValidateConfigletsForDeviceCtx is the context aware version of ValidateConfigletsForDevice.
+func (c CvpRestAPI) ValidateConfigletsForDeviceCtx(ctx context.Context, deviceMac string,
+	configletKeys []string) (*ValidateAndCompareConfigletsResp, error) {
 	var resp ValidateAndCompareConfigletsResp
 	data := struct {
 		NetElementID string   `json:"netElementId"`
@@ -513,9 +563,10 @@ func (c CvpRestAPI) ValidateConfigletsForDevice(deviceMac string, configletKeys
 		PageType:     "validateConfig",
 	}
 
-	reqResp, err := c.client.Post("/provisioning/v2/validateAndCompareConfiglets.do", nil, data)
+	reqResp, err := c.post(ctx, "/provisioning/v2/validateAndCompareConfiglets.do", nil, data)
 	if err != nil {
-		return nil, errors.Errorf("ValidateConfigletsForDevice: %s", err)
+		return nil, wrapError("ValidateConfigletsForDevice",
+			"/provisioning/v2/validateAndCompareConfiglets.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -535,13 +586,21 @@ func (c CvpRestAPI) ValidateConfigletsForDevice(deviceMac string, configletKeys
 // ValidateAndApplyConfigletsToDevice validate and apply the configlets to the device.
 func (c CvpRestAPI) ValidateAndApplyConfigletsToDevice(appName string, dev *NetElement, commit bool,
 	newConfiglets ...Configlet) (*TaskInfo, error) {
+	return c.ValidateAndApplyConfigletsToDeviceCtx(context.Background(), appName, dev, commit,
+		newConfiglets...)
+}
+
+// ValidateAndApplyConfigletsToDeviceCtx is the context aware version of
+// ValidateAndApplyConfigletsToDevice.
+func (c CvpRestAPI) ValidateAndApplyConfigletsToDeviceCtx(ctx context.Context, appName string,
+	dev *NetElement, commit bool, newConfiglets ...Configlet) (*TaskInfo, error) {
 	if dev == nil {
 		return nil, errors.Errorf("ApplyConfigletsToDevice: nil NetElement")
 	}
 
-	configlets, err := c.GetConfigletsByDeviceID(dev.SystemMacAddress)
+	configlets, err := c.GetConfigletsByDeviceIDCtx(ctx, dev.SystemMacAddress)
 	if err != nil {
-		return nil, errors.Errorf("ApplyConfigletsToDevice: %s", err)
+		return nil, errors.Wrap(err, "ApplyConfigletsToDevice")
 	}
 	action, cnames, ckeys, cbnames, cbkeys, err := checkConfigMapping(configlets, newConfiglets)
 	if err != nil {
@@ -553,9 +612,9 @@ func (c CvpRestAPI) ValidateAndApplyConfigletsToDevice(appName string, dev *NetE
 	}
 
 	// Run Validation of new configlets to be applied
-	validateResp, err := c.ValidateConfigletsForDevice(dev.SystemMacAddress, ckeys)
+	validateResp, err := c.ValidateConfigletsForDeviceCtx(ctx, dev.SystemMacAddress, ckeys)
 	if err != nil {
-		return nil, errors.Errorf("ApplyConfigletsToDevice: %s", err)
+		return nil, errors.Wrap(err, "ApplyConfigletsToDevice")
 	}
 	// If validation returned a proper validation response pull the config compare count values
 	// to be applied to the Action data
@@ -603,11 +662,11 @@ func (c CvpRestAPI) ValidateAndApplyConfigletsToDevice(appName string, dev *NetE
 		},
 	}}
 
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("ApplyConfigletsToDevice: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "ApplyConfigletsToDevice")
 	}
 	if commit {
-		return c.SaveTopology()
+		return c.SaveTopologyCtx(ctx)
 	}
 	return nil, nil
 }
@@ -615,13 +674,20 @@ func (c CvpRestAPI) ValidateAndApplyConfigletsToDevice(appName string, dev *NetE
 // RemoveConfigletsFromDevice Remove the configlets from the device.
 func (c CvpRestAPI) RemoveConfigletsFromDevice(appName string, dev *NetElement, commit bool,
 	remConfiglets ...Configlet) (*TaskInfo, error) {
+	return c.RemoveConfigletsFromDeviceCtx(context.Background(), appName, dev, commit,
+		remConfiglets...)
+}
+
+// RemoveConfigletsFromDeviceCtx is the context aware version of RemoveConfigletsFromDevice.
+func (c CvpRestAPI) RemoveConfigletsFromDeviceCtx(ctx context.Context, appName string,
+	dev *NetElement, commit bool, remConfiglets ...Configlet) (*TaskInfo, error) {
 	if dev == nil {
 		return nil, errors.Errorf("RemoveConfigletsFromDevice: nil NetElement")
 	}
 
-	configlets, err := c.GetConfigletsByDeviceID(dev.SystemMacAddress)
+	configlets, err := c.GetConfigletsByDeviceIDCtx(ctx, dev.SystemMacAddress)
 	if err != nil {
-		return nil, errors.Errorf("RemoveConfigletsFromDevice: %s", err)
+		return nil, errors.Wrap(err, "RemoveConfigletsFromDevice")
 	}
 
 	action, cNames, cbNames, rmNames, rmbNames, err :=
@@ -636,22 +702,22 @@ func (c CvpRestAPI) RemoveConfigletsFromDevice(appName string, dev *NetElement,
 
 	var cKeys, cbKeys, rmKeys, rmbKeys []string
 	// Build a list of the configlet names/keys to remove.
-	if cKeys, err = c.getConfigletKeys(cNames); err != nil {
+	if cKeys, err = c.getConfigletKeys(ctx, cNames); err != nil {
 		return nil, errors.Wrap(err, "RemoveConfigletsFromDevice")
 	}
 
 	// Build a list of the configlet names/keys to remove.
-	if cbKeys, err = c.getConfigletKeys(cbNames); err != nil {
+	if cbKeys, err = c.getConfigletKeys(ctx, cbNames); err != nil {
 		return nil, errors.Wrap(err, "RemoveConfigletsFromDevice")
 	}
 
 	// Build a list of the configlet names/keys to remove.
-	if rmKeys, err = c.getConfigletKeys(rmNames); err != nil {
+	if rmKeys, err = c.getConfigletKeys(ctx, rmNames); err != nil {
 		return nil, errors.Wrap(err, "RemoveConfigletsFromDevice")
 	}
 
 	// Build a list of the configlet names/keys to remove.
-	if rmbKeys, err = c.getConfigletKeys(rmbNames); err != nil {
+	if rmbKeys, err = c.getConfigletKeys(ctx, rmbNames); err != nil {
 		return nil, errors.Wrap(err, "RemoveConfigletsFromDevice")
 	}
 
@@ -689,12 +755,12 @@ func (c CvpRestAPI) RemoveConfigletsFromDevice(appName string, dev *NetElement,
 			ParentTask:                      "",
 		},
 	}}
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("RemoveConfigletsFromDevice: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "RemoveConfigletsFromDevice")
 	}
 
 	if commit {
-		return c.SaveTopology()
+		return c.SaveTopologyCtx(ctx)
 	}
 	return nil, nil
 }
@@ -702,23 +768,35 @@ func (c CvpRestAPI) RemoveConfigletsFromDevice(appName string, dev *NetElement,
 // RemoveConfigletFromDevice Remove the configlets from the device.
 func (c CvpRestAPI) RemoveConfigletFromDevice(appName string, dev *NetElement,
 	remConfiglet *Configlet, commit bool) (*TaskInfo, error) {
+	return c.RemoveConfigletFromDeviceCtx(context.Background(), appName, dev, remConfiglet, commit)
+}
+
+// RemoveConfigletFromDeviceCtx is the context aware version of RemoveConfigletFromDevice.
+func (c CvpRestAPI) RemoveConfigletFromDeviceCtx(ctx context.Context, appName string,
+	dev *NetElement, remConfiglet *Configlet, commit bool) (*TaskInfo, error) {
 	var remConfigletList []Configlet
 	remConfigletList = append(remConfigletList, *remConfiglet)
-	return c.RemoveConfigletsFromDevice(appName, dev, commit, remConfigletList...)
+	return c.RemoveConfigletsFromDeviceCtx(ctx, appName, dev, commit, remConfigletList...)
 }
 
 // SetConfigletsToContainer Sets the configlets to the container,
 // and removes configlets from the container not referenced in `configlets`.
 func (c CvpRestAPI) SetConfigletsToContainer(appName string, cont *Container, commit bool,
 	configlets ...Configlet) (*TaskInfo, error) {
+	return c.SetConfigletsToContainerCtx(context.Background(), appName, cont, commit, configlets...)
+}
+
+// SetConfigletsToContainerCtx is the context aware version of SetConfigletsToContainer.
+func (c CvpRestAPI) SetConfigletsToContainerCtx(ctx context.Context, appName string,
+	cont *Container, commit bool, configlets ...Configlet) (*TaskInfo, error) {
 	if cont == nil {
 		return nil, errors.Errorf("SetConfigletsToContainer: nil Container")
 	}
 
 	// configlets to be removed; applied minus not in configlets
-	currentConfiglets, err := c.GetContainerConfiglets(cont.Key)
+	currentConfiglets, err := c.GetContainerConfigletsCtx(ctx, cont.Key)
 	if err != nil {
-		return nil, errors.Errorf("SetConfigletsToContainer: %s", err)
+		return nil, errors.Wrap(err, "SetConfigletsToContainer")
 	}
 
 	newCAndB, rmCAndB, err := changesNeeded(currentConfiglets, configlets)
@@ -758,12 +836,12 @@ func (c CvpRestAPI) SetConfigletsToContainer(appName string, cont *Container, co
 		},
 	}}
 
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("SetConfigletsToDevice: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "SetConfigletsToDevice")
 	}
 
 	if commit {
-		return c.SaveTopology()
+		return c.SaveTopologyCtx(ctx)
 	}
 
 	return nil, nil
@@ -772,13 +850,19 @@ func (c CvpRestAPI) SetConfigletsToContainer(appName string, cont *Container, co
 // ApplyConfigletsToContainer apply the configlets to the container.
 func (c CvpRestAPI) ApplyConfigletsToContainer(appName string, cont *Container,
 	newConfiglets ...Configlet) (*TaskInfo, error) {
+	return c.ApplyConfigletsToContainerCtx(context.Background(), appName, cont, newConfiglets...)
+}
+
+// ApplyConfigletsToContainerCtx is the context aware version of ApplyConfigletsToContainer.
+func (c CvpRestAPI) ApplyConfigletsToContainerCtx(ctx context.Context, appName string,
+	cont *Container, newConfiglets ...Configlet) (*TaskInfo, error) {
 	if cont == nil {
 		return nil, errors.Errorf("ApplyConfigletsToContainer: nil Container")
 	}
 
-	configlets, err := c.GetContainerConfiglets(cont.Key)
+	configlets, err := c.GetContainerConfigletsCtx(ctx, cont.Key)
 	if err != nil {
-		return nil, errors.Errorf("ApplyConfigletsToContainer: %s", err)
+		return nil, errors.Wrap(err, "ApplyConfigletsToContainer")
 	}
 
 	action, cnames, ckeys, cbnames, cbkeys, err := checkConfigMapping(configlets, newConfiglets)
@@ -824,30 +908,42 @@ func (c CvpRestAPI) ApplyConfigletsToContainer(appName string, cont *Container,
 		},
 	}}
 
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("ApplyConfigletsToContainer: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "ApplyConfigletsToContainer")
 	}
-	return c.SaveTopology()
+	return c.SaveTopologyCtx(ctx)
 }
 
 // ApplyConfigletToContainer apply the configlets to the container.
 func (c CvpRestAPI) ApplyConfigletToContainer(appName string, cont *Container,
 	newConfiglet *Configlet) (*TaskInfo, error) {
+	return c.ApplyConfigletToContainerCtx(context.Background(), appName, cont, newConfiglet)
+}
+
+// ApplyConfigletToContainerCtx is the context aware version of ApplyConfigletToContainer.
+func (c CvpRestAPI) ApplyConfigletToContainerCtx(ctx context.Context, appName string,
+	cont *Container, newConfiglet *Configlet) (*TaskInfo, error) {
 	var newConfigletList []Configlet
 	newConfigletList = append(newConfigletList, *newConfiglet)
-	return c.ApplyConfigletsToContainer(appName, cont, newConfigletList...)
+	return c.ApplyConfigletsToContainerCtx(ctx, appName, cont, newConfigletList...)
 }
 
 // RemoveConfigletsFromContainer Remove the configlets from the container.
 func (c CvpRestAPI) RemoveConfigletsFromContainer(appName string, cont *Container,
 	remConfiglets ...Configlet) (*TaskInfo, error) {
+	return c.RemoveConfigletsFromContainerCtx(context.Background(), appName, cont, remConfiglets...)
+}
+
+// RemoveConfigletsFromContainerCtx is the context aware version of RemoveConfigletsFromContainer.
+func (c CvpRestAPI) RemoveConfigletsFromContainerCtx(ctx context.Context, appName string,
+	cont *Container, remConfiglets ...Configlet) (*TaskInfo, error) {
 	if cont == nil {
 		return nil, errors.Errorf("RemoveConfigletsFromContainer: nil Container")
 	}
 
-	configlets, err := c.GetContainerConfiglets(cont.Key)
+	configlets, err := c.GetContainerConfigletsCtx(ctx, cont.Key)
 	if err != nil {
-		return nil, errors.Errorf("RemoveConfigletsFromContainer: %s", err)
+		return nil, errors.Wrap(err, "RemoveConfigletsFromContainer")
 	}
 
 	action, cNames, cbNames, rmNames, rmbNames, err :=
@@ -862,22 +958,22 @@ func (c CvpRestAPI) RemoveConfigletsFromContainer(appName string, cont *Containe
 
 	var cKeys, cbKeys, rmKeys, rmbKeys []string
 	// Build a list of the configlet names/keys to remove.
-	if cKeys, err = c.getConfigletKeys(cNames); err != nil {
+	if cKeys, err = c.getConfigletKeys(ctx, cNames); err != nil {
 		return nil, errors.Wrap(err, "RemoveConfigletsFromContainer")
 	}
 
 	// Build a list of the configlet names/keys to remove.
-	if cbKeys, err = c.getConfigletKeys(cbNames); err != nil {
+	if cbKeys, err = c.getConfigletKeys(ctx, cbNames); err != nil {
 		return nil, errors.Wrap(err, "RemoveConfigletsFromContainer")
 	}
 
 	// Build a list of the configlet names/keys to remove.
-	if rmKeys, err = c.getConfigletKeys(rmNames); err != nil {
+	if rmKeys, err = c.getConfigletKeys(ctx, rmNames); err != nil {
 		return nil, errors.Wrap(err, "RemoveConfigletsFromContainer")
 	}
 
 	// Build a list of the configlet names/keys to remove.
-	if rmbKeys, err = c.getConfigletKeys(rmbNames); err != nil {
+	if rmbKeys, err = c.getConfigletKeys(ctx, rmbNames); err != nil {
 		return nil, errors.Wrap(err, "RemoveConfigletsFromContainer")
 	}
 
@@ -915,29 +1011,48 @@ func (c CvpRestAPI) RemoveConfigletsFromContainer(appName string, cont *Containe
 			ParentTask: "",
 		},
 	}}
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("RemoveConfigletsFromContainer: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "RemoveConfigletsFromContainer")
 	}
-	return c.SaveTopology()
+	return c.SaveTopologyCtx(ctx)
 }
 
 // RemoveConfigletFromContainer Remove the configlets from the device.
 func (c CvpRestAPI) RemoveConfigletFromContainer(appName string, cont *Container,
 	remConfiglet *Configlet) (*TaskInfo, error) {
+	return c.RemoveConfigletFromContainerCtx(context.Background(), appName, cont, remConfiglet)
+}
+
+// RemoveConfigletFromContainerCtx is the context aware version of RemoveConfigletFromContainer.
+func (c CvpRestAPI) RemoveConfigletFromContainerCtx(ctx context.Context, appName string,
+	cont *Container, remConfiglet *Configlet) (*TaskInfo, error) {
 	var remConfigletList []Configlet
 	remConfigletList = append(remConfigletList, *remConfiglet)
-	return c.RemoveConfigletsFromContainer(appName, cont, remConfigletList...)
+	return c.RemoveConfigletsFromContainerCtx(ctx, appName, cont, remConfigletList...)
 }
 
 // GetContainerConfiglets returns a list of configlets for a given container key
 func (c CvpRestAPI) GetContainerConfiglets(cid string) ([]Configlet, error) {
-	result, err := c.GetContainerConfigletsWithRange(cid, 0, 0)
+	return c.GetContainerConfigletsCtx(context.Background(), cid)
+}
+
+// GetContainerConfigletsCtx is the context aware version of GetContainerConfiglets.
+func (c CvpRestAPI) GetContainerConfigletsCtx(ctx context.Context,
+	cid string) ([]Configlet, error) {
+	result, err := c.GetContainerConfigletsWithRangeCtx(ctx, cid, 0, 0)
 	return result, errors.Wrap(err, "GetContainerConfiglets")
 }
 
 // GetContainerConfigletsWithRange returns a list of configlets for a given
 // container key and start/end range
 func (c CvpRestAPI) GetContainerConfigletsWithRange(cid string, start int,
+	end int) ([]Configlet, error) {
+	return c.GetContainerConfigletsWithRangeCtx(context.Background(), cid, start, end)
+}
+
+// GetContainerConfigletsWithRangeCtx is the context aware version of
+// GetContainerConfigletsWithRange.
+func (c CvpRestAPI) GetContainerConfigletsWithRangeCtx(ctx context.Context, cid string, start int,
 	end int) ([]Configlet, error) {
 	var resp ConfigletInfo
 	query := &url.Values{
@@ -946,9 +1061,10 @@ func (c CvpRestAPI) GetContainerConfigletsWithRange(cid string, start int,
 		"endIndex":    {strconv.Itoa(end)},
 	}
 
-	reqResp, err := c.client.Get("/provisioning/getConfigletsByContainerId.do", query)
+	reqResp, err := c.get(ctx, "/provisioning/getConfigletsByContainerId.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetContainerConfigletsWithRange: %s", err)
+		return nil, wrapError("GetContainerConfigletsWithRange",
+			"/provisioning/getConfigletsByContainerId.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -956,12 +1072,13 @@ func (c CvpRestAPI) GetContainerConfigletsWithRange(cid string, start int,
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("GetContainerConfigletsWithRange: %s", err)
+		return nil, wrapError("GetContainerConfigletsWithRange",
+			"/provisioning/getConfigletsByContainerId.do", err)
 	}
 	return resp.ConfigletList, nil
 }
 
-func (c CvpRestAPI) containerOp(containerName, containerKey, parentName,
+func (c CvpRestAPI) containerOp(ctx context.Context, containerName, containerKey, parentName,
 	parentKey, operation string) (*TaskInfo, error) {
 
 	msg := operation + " container " + containerName + " under container " + parentName
@@ -983,28 +1100,47 @@ func (c CvpRestAPI) containerOp(containerName, containerKey, parentName,
 			NodeName:    containerName,
 		},
 	}}
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("containerOp: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "containerOp")
 	}
-	return c.SaveTopology()
+	return c.SaveTopologyCtx(ctx)
 }
 
 // AddContainer adds the container to the specified parent.
 func (c CvpRestAPI) AddContainer(containerName, parentName,
 	parentKey string) error {
-	_, err := c.containerOp(containerName, "New_container1", parentName, parentKey, "add")
+	return c.AddContainerCtx(context.Background(), containerName, parentName, parentKey)
+}
+
+// AddContainerCtx is the context aware version of AddContainer.
+func (c CvpRestAPI) AddContainerCtx(ctx context.Context, containerName, parentName,
+	parentKey string) error {
+	_, err := c.containerOp(ctx, containerName, "New_container1", parentName, parentKey, "add")
 	return errors.Wrap(err, "AddContainer")
 }
 
 // DeleteContainer deletes the container from the specified parent.
 func (c CvpRestAPI) DeleteContainer(containerName, containerKey,
 	parentName, parentKey string) error {
-	_, err := c.containerOp(containerName, containerKey, parentName, parentKey, "delete")
+	return c.DeleteContainerCtx(context.Background(), containerName, containerKey, parentName,
+		parentKey)
+}
+
+// DeleteContainerCtx is the context aware version of DeleteContainer.
+func (c CvpRestAPI) DeleteContainerCtx(ctx context.Context, containerName, containerKey, parentName,
+	parentKey string) error {
+	_, err := c.containerOp(ctx, containerName, containerKey, parentName, parentKey, "delete")
 	return errors.Wrap(err, "DeleteContainer")
 }
 
 // ResetDevice Resets/Reboots the device to factory setting.
 func (c CvpRestAPI) ResetDevice(appName string, dev *NetElement,
+	container *Container, commit bool) (*TaskInfo, error) {
+	return c.ResetDeviceCtx(context.Background(), appName, dev, container, commit)
+}
+
+// ResetDeviceCtx is the context aware version of ResetDevice.
+func (c CvpRestAPI) ResetDeviceCtx(ctx context.Context, appName string, dev *NetElement,
 	container *Container, commit bool) (*TaskInfo, error) {
 	if dev == nil {
 		return nil, errors.Errorf("ResetDevice: nil NetElement ref provided")
@@ -1035,12 +1171,12 @@ func (c CvpRestAPI) ResetDevice(appName string, dev *NetElement,
 		},
 	}}
 
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("ResetDevice: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "ResetDevice")
 	}
 
 	if commit {
-		return c.SaveTopology()
+		return c.SaveTopologyCtx(ctx)
 	}
 	return nil, nil
 }
@@ -1050,6 +1186,12 @@ func (c CvpRestAPI) ResetDevice(appName string, dev *NetElement,
 //
 // If query yields no hits, then result is (SearchTopologyResp{})
 func (c CvpRestAPI) SearchTopologyWithRange(querystr string, start int,
+	end int) (*SearchTopologyResp, error) {
+	return c.SearchTopologyWithRangeCtx(context.Background(), querystr, start, end)
+}
+
+// SearchTopologyWithRangeCtx is the context aware version of SearchTopologyWithRange.
+func (c CvpRestAPI) SearchTopologyWithRangeCtx(ctx context.Context, querystr string, start int,
 	end int) (*SearchTopologyResp, error) {
 	var resp SearchTopologyResp
 	query := &url.Values{
@@ -1058,9 +1200,9 @@ func (c CvpRestAPI) SearchTopologyWithRange(querystr string, start int,
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	reqResp, err := c.client.Get("/provisioning/searchTopology.do", query)
+	reqResp, err := c.get(ctx, "/provisioning/searchTopology.do", query)
 	if err != nil {
-		return nil, errors.Errorf("SearchTopologyWithRange: %s", err)
+		return nil, wrapError("SearchTopologyWithRange", "/provisioning/searchTopology.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -1068,14 +1210,20 @@ func (c CvpRestAPI) SearchTopologyWithRange(querystr string, start int,
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("SearchTopologyWithRange: %s", err)
+		return nil, wrapError("SearchTopologyWithRange", "/provisioning/searchTopology.do", err)
 	}
 	return &resp, nil
 }
 
 // SearchTopology searches the topology for items matching the query parameter.
 func (c CvpRestAPI) SearchTopology(query string) (*SearchTopologyResp, error) {
-	return c.SearchTopologyWithRange(query, 0, 0)
+	return c.SearchTopologyCtx(context.Background(), query)
+}
+
+// SearchTopologyCtx is the context aware version of SearchTopology.
+func (c CvpRestAPI) SearchTopologyCtx(ctx context.Context,
+	query string) (*SearchTopologyResp, error) {
+	return c.SearchTopologyWithRangeCtx(ctx, query, 0, 0)
 }
 
 // CheckCompliance Check that a device is in compliance, that is the configlets
@@ -1084,15 +1232,21 @@ func (c CvpRestAPI) SearchTopology(query string) (*SearchTopologyResp, error) {
 // Supported only for NetElements
 //
 func (c CvpRestAPI) CheckCompliance(nodeKey string, nodeType string) (*ComplianceResp, error) {
+	return c.CheckComplianceCtx(context.Background(), nodeKey, nodeType)
+}
+
+// CheckComplianceCtx is the context aware version of CheckCompliance.
+func (c CvpRestAPI) CheckComplianceCtx(ctx context.Context, nodeKey string,
+	nodeType string) (*ComplianceResp, error) {
 	var info ComplianceResp
 	data := map[string]string{
 		"nodeId":   nodeKey,
 		"nodeType": nodeType,
 	}
 
-	resp, err := c.client.Post("/provisioning/checkCompliance.do", nil, data)
+	resp, err := c.post(ctx, "/provisioning/checkCompliance.do", nil, data)
 	if err != nil {
-		return nil, errors.Errorf("CheckCompliance: %s", err)
+		return nil, wrapError("CheckCompliance", "/provisioning/checkCompliance.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -1100,7 +1254,7 @@ func (c CvpRestAPI) CheckCompliance(nodeKey string, nodeType string) (*Complianc
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("CheckCompliance: %s", err)
+		return nil, wrapError("CheckCompliance", "/provisioning/checkCompliance.do", err)
 	}
 
 	return &info, nil
@@ -1108,13 +1262,19 @@ func (c CvpRestAPI) CheckCompliance(nodeKey string, nodeType string) (*Complianc
 
 // GetParentContainerForDevice returns the Container for specified deviceMAC
 func (c CvpRestAPI) GetParentContainerForDevice(deviceMAC string) (*Container, error) {
-	results, err := c.SearchTopologyWithRange(deviceMAC, 0, 0)
+	return c.GetParentContainerForDeviceCtx(context.Background(), deviceMAC)
+}
+
+// GetParentContainerForDeviceCtx is the context aware version of GetParentContainerForDevice.
+func (c CvpRestAPI) GetParentContainerForDeviceCtx(ctx context.Context,
+	deviceMAC string) (*Container, error) {
+	results, err := c.SearchTopologyWithRangeCtx(ctx, deviceMAC, 0, 0)
 	if err != nil {
-		return nil, errors.Errorf("GetParentContainerForDevice: %s", err)
+		return nil, errors.Wrap(err, "GetParentContainerForDevice")
 	}
 	for _, netContainerInfo := range results.NetElementContainerList {
 		if netContainerInfo.NetElementKey == deviceMAC {
-			return c.GetContainerByName(netContainerInfo.ContainerName)
+			return c.GetContainerByNameCtx(ctx, netContainerInfo.ContainerName)
 		}
 	}
 	return nil, nil
@@ -1123,6 +1283,12 @@ func (c CvpRestAPI) GetParentContainerForDevice(deviceMAC string) (*Container, e
 // MoveDeviceToContainer moves a specified netelement to a container.
 func (c CvpRestAPI) MoveDeviceToContainer(appName string, device *NetElement,
 	container *Container, commit bool) (*TaskInfo, error) {
+	return c.MoveDeviceToContainerCtx(context.Background(), appName, device, container, commit)
+}
+
+// MoveDeviceToContainerCtx is the context aware version of MoveDeviceToContainer.
+func (c CvpRestAPI) MoveDeviceToContainerCtx(ctx context.Context, appName string,
+	device *NetElement, container *Container, commit bool) (*TaskInfo, error) {
 	if device == nil {
 		return nil, errors.Errorf("MoveDeviceToContainer: nil NetElement")
 	}
@@ -1133,9 +1299,9 @@ func (c CvpRestAPI) MoveDeviceToContainer(appName string, device *NetElement,
 	var fromID string
 	var fromName string
 	if device.ParentContainerKey != "" {
-		container, err := c.GetContainerInfoByID(device.ParentContainerKey)
+		container, err := c.GetContainerInfoByIDCtx(ctx, device.ParentContainerKey)
 		if err != nil {
-			return nil, errors.Errorf("MoveDeviceToContainer: %s", err)
+			return nil, errors.Wrap(err, "MoveDeviceToContainer")
 		}
 		if container == nil {
 			return nil, errors.Errorf("MoveDeviceToContainer: No container found for "+
@@ -1144,9 +1310,9 @@ func (c CvpRestAPI) MoveDeviceToContainer(appName string, device *NetElement,
 		fromID = device.ParentContainerKey
 		fromName = container.Name
 	} else {
-		parentCont, err := c.GetParentContainerForDevice(device.SystemMacAddress)
+		parentCont, err := c.GetParentContainerForDeviceCtx(ctx, device.SystemMacAddress)
 		if err != nil {
-			return nil, errors.Errorf("MoveDeviceToContainer: %s", err)
+			return nil, errors.Wrap(err, "MoveDeviceToContainer")
 		}
 		if parentCont == nil {
 			return nil, errors.Errorf("MoveDeviceToContainer: No parent container found for "+
@@ -1178,12 +1344,12 @@ func (c CvpRestAPI) MoveDeviceToContainer(appName string, device *NetElement,
 			NodeName:    device.Fqdn,
 		},
 	}}
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("MoveDeviceToContainer: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "MoveDeviceToContainer")
 	}
 
 	if commit {
-		return c.SaveTopology()
+		return c.SaveTopologyCtx(ctx)
 	}
 	return nil, nil
 }
@@ -1250,6 +1416,22 @@ type ImageBundleResp struct {
 
 // GetImages returns a list of Images based on a specific query string and range
 func (c CvpRestAPI) GetImages(querystr string, start int, end int) ([]ImageInfo, error) {
+	return c.GetImagesCtx(context.Background(), querystr, start, end)
+}
+
+// GetImagesCtx is the context aware version of GetImages.
+func (c CvpRestAPI) GetImagesCtx(ctx context.Context, querystr string, start int,
+	end int) ([]ImageInfo, error) {
+	resp, err := c.getImages(ctx, querystr, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// getImages fetches a range of images along with the total number of matches.
+func (c CvpRestAPI) getImages(ctx context.Context, querystr string, start int,
+	end int) (*ImageResp, error) {
 	var resp ImageResp
 	query := &url.Values{
 		"queryParam": {querystr},
@@ -1257,9 +1439,9 @@ func (c CvpRestAPI) GetImages(querystr string, start int, end int) ([]ImageInfo,
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	reqResp, err := c.client.Get("/image/getImages.do", query)
+	reqResp, err := c.get(ctx, "/image/getImages.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetImages: %s", err)
+		return nil, wrapError("GetImages", "/image/getImages.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -1267,16 +1449,21 @@ func (c CvpRestAPI) GetImages(querystr string, start int, end int) ([]ImageInfo,
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("GetImages: %s", err)
+		return nil, wrapError("GetImages", "/image/getImages.do", err)
 	}
-	return resp.Data, nil
+	return &resp, nil
 }
 
 // GetImageByName returns an ImageInfo object based on name provided
 func (c CvpRestAPI) GetImageByName(name string) (*ImageInfo, error) {
-	resp, err := c.GetImages(name, 0, 0)
+	return c.GetImageByNameCtx(context.Background(), name)
+}
+
+// GetImageByNameCtx is the context aware version of GetImageByName.
+func (c CvpRestAPI) GetImageByNameCtx(ctx context.Context, name string) (*ImageInfo, error) {
+	resp, err := c.GetImagesCtx(ctx, name, 0, 0)
 	if err != nil {
-		return nil, errors.Errorf("GetImageByName: %s", err)
+		return nil, errors.Wrap(err, "GetImageByName")
 	}
 
 	for _, image := range resp {
@@ -1289,6 +1476,12 @@ func (c CvpRestAPI) GetImageByName(name string) (*ImageInfo, error) {
 
 // GetImageBundles returns a list of ImageBundles based on a specific query string and range
 func (c CvpRestAPI) GetImageBundles(querystr string, start, end int) ([]ImageBundleInfo, error) {
+	return c.GetImageBundlesCtx(context.Background(), querystr, start, end)
+}
+
+// GetImageBundlesCtx is the context aware version of GetImageBundles.
+func (c CvpRestAPI) GetImageBundlesCtx(ctx context.Context, querystr string, start,
+	end int) ([]ImageBundleInfo, error) {
 	var resp ImageBundleResp
 	query := &url.Values{
 		"queryParam": {querystr},
@@ -1296,9 +1489,9 @@ func (c CvpRestAPI) GetImageBundles(querystr string, start, end int) ([]ImageBun
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	reqResp, err := c.client.Get("/image/getImageBundles.do", query)
+	reqResp, err := c.get(ctx, "/image/getImageBundles.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetImageBundles: %s", err)
+		return nil, wrapError("GetImageBundles", "/image/getImageBundles.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -1306,7 +1499,7 @@ func (c CvpRestAPI) GetImageBundles(querystr string, start, end int) ([]ImageBun
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("GetImageBundles: %s", err)
+		return nil, wrapError("GetImageBundles", "/image/getImageBundles.do", err)
 	}
 	return resp.Data, nil
 
@@ -1314,11 +1507,22 @@ func (c CvpRestAPI) GetImageBundles(querystr string, start, end int) ([]ImageBun
 
 // GetAllImageBundles gets all ImageBundles
 func (c CvpRestAPI) GetAllImageBundles() ([]ImageBundleInfo, error) {
-	return c.GetImageBundles("", 0, 0)
+	return c.GetAllImageBundlesCtx(context.Background())
+}
+
+// GetAllImageBundlesCtx is the context aware version of GetAllImageBundles.
+func (c CvpRestAPI) GetAllImageBundlesCtx(ctx context.Context) ([]ImageBundleInfo, error) {
+	return c.GetImageBundlesCtx(ctx, "", 0, 0)
 }
 
 // GetImageBundleByName gets ImageBundle by specified name
 func (c CvpRestAPI) GetImageBundleByName(name string) (*ImageBundleInfo, error) {
+	return c.GetImageBundleByNameCtx(context.Background(), name)
+}
+
+// GetImageBundleByNameCtx is the context aware version of GetImageBundleByName.
+func (c CvpRestAPI) GetImageBundleByNameCtx(ctx context.Context,
+	name string) (*ImageBundleInfo, error) {
 	// Hack around string returned for ID
 	type tmp struct {
 		AppliedContainersCount   int         `json:"appliedContainersCount"`
@@ -1342,9 +1546,9 @@ func (c CvpRestAPI) GetImageBundleByName(name string) (*ImageBundleInfo, error)
 		"name": {name},
 	}
 
-	reqResp, err := c.client.Get("/image/getImageBundleByName.do", query)
+	reqResp, err := c.get(ctx, "/image/getImageBundleByName.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetImageBundleByName: %s", err)
+		return nil, wrapError("GetImageBundleByName", "/image/getImageBundleByName.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -1352,7 +1556,7 @@ func (c CvpRestAPI) GetImageBundleByName(name string) (*ImageBundleInfo, error)
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("GetImageBundleByName: %s", err)
+		return nil, wrapError("GetImageBundleByName", "/image/getImageBundleByName.do", err)
 	}
 	ret := &ImageBundleInfo{
 		AppliedContainersCount:   resp.AppliedContainersCount,
@@ -1376,6 +1580,12 @@ func (c CvpRestAPI) GetImageBundleByName(name string) (*ImageBundleInfo, error)
 // ApplyImageToDevice Applies image bundle to device
 func (c CvpRestAPI) ApplyImageToDevice(appName string, imageInfo *ImageBundleInfo,
 	netElement *NetElement, commit bool) (*TaskInfo, error) {
+	return c.ApplyImageToDeviceCtx(context.Background(), appName, imageInfo, netElement, commit)
+}
+
+// ApplyImageToDeviceCtx is the context aware version of ApplyImageToDevice.
+func (c CvpRestAPI) ApplyImageToDeviceCtx(ctx context.Context, appName string,
+	imageInfo *ImageBundleInfo, netElement *NetElement, commit bool) (*TaskInfo, error) {
 	if imageInfo == nil {
 		return nil, errors.Errorf("ApplyImageToDevice: nil ImageBundleInfo")
 	}
@@ -1403,12 +1613,12 @@ func (c CvpRestAPI) ApplyImageToDevice(appName string, imageInfo *ImageBundleInf
 			ToName:      netElement.Fqdn,
 		},
 	}}
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("ApplyImageToDevice: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "ApplyImageToDevice")
 	}
 
 	if commit {
-		return c.SaveTopology()
+		return c.SaveTopologyCtx(ctx)
 	}
 	return nil, nil
 }
@@ -1416,6 +1626,12 @@ func (c CvpRestAPI) ApplyImageToDevice(appName string, imageInfo *ImageBundleInf
 // ApplyImageToContainer Applies image bundle to container
 func (c CvpRestAPI) ApplyImageToContainer(appName string, imageInfo *ImageBundleInfo,
 	container *Container, commit bool) (*TaskInfo, error) {
+	return c.ApplyImageToContainerCtx(context.Background(), appName, imageInfo, container, commit)
+}
+
+// ApplyImageToContainerCtx is the context aware version of ApplyImageToContainer.
+func (c CvpRestAPI) ApplyImageToContainerCtx(ctx context.Context, appName string,
+	imageInfo *ImageBundleInfo, container *Container, commit bool) (*TaskInfo, error) {
 	if imageInfo == nil {
 		return nil, errors.Errorf("ApplyImageToContainer: nil ImageBundleInfo")
 	}
@@ -1443,12 +1659,12 @@ func (c CvpRestAPI) ApplyImageToContainer(appName string, imageInfo *ImageBundle
 			ToName:      container.Name,
 		},
 	}}
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("ApplyImageToContainer: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "ApplyImageToContainer")
 	}
 
 	if commit {
-		return c.SaveTopology()
+		return c.SaveTopologyCtx(ctx)
 	}
 	return nil, nil
 }
@@ -1456,6 +1672,12 @@ func (c CvpRestAPI) ApplyImageToContainer(appName string, imageInfo *ImageBundle
 // RemoveImageFromContainer removes image bundle from container
 func (c CvpRestAPI) RemoveImageFromContainer(appName string, imageInfo *ImageBundleInfo,
 	container *Container) (*TaskInfo, error) {
+	return c.RemoveImageFromContainerCtx(context.Background(), appName, imageInfo, container)
+}
+
+// RemoveImageFromContainerCtx is the context aware version of RemoveImageFromContainer.
+func (c CvpRestAPI) RemoveImageFromContainerCtx(ctx context.Context, appName string,
+	imageInfo *ImageBundleInfo, container *Container) (*TaskInfo, error) {
 	if imageInfo == nil {
 		return nil, errors.Errorf("RemoveImageFromContainer: nil ImageBundleInfo")
 	}
@@ -1486,37 +1708,52 @@ func (c CvpRestAPI) RemoveImageFromContainer(appName string, imageInfo *ImageBun
 			IgnoreNodeName: imageInfo.Name,
 		},
 	}}
-	if err := c.addTempAction(data); err != nil {
-		return nil, errors.Errorf("RemoveImageFromContainer: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		return nil, errors.Wrap(err, "RemoveImageFromContainer")
 	}
-	return c.SaveTopology()
+	return c.SaveTopologyCtx(ctx)
 }
 
 // DeployDevice Move a device from the undefined container to a target container.
 // Optionally, apply device-specific configlets to the device.
 func (c CvpRestAPI) DeployDevice(appName string, dev *NetElement, devTargetIP string,
 	container *Container, configlets ...Configlet) (*TaskInfo, error) {
-	return c.DeployDeviceWithImage(appName, dev, devTargetIP, container, "", configlets...)
+	return c.DeployDeviceCtx(context.Background(), appName, dev, devTargetIP, container,
+		configlets...)
+}
+
+// DeployDeviceCtx is the context aware version of DeployDevice.
+func (c CvpRestAPI) DeployDeviceCtx(ctx context.Context, appName string, dev *NetElement,
+	devTargetIP string, container *Container, configlets ...Configlet) (*TaskInfo, error) {
+	return c.DeployDeviceWithImageCtx(ctx, appName, dev, devTargetIP, container, "", configlets...)
 }
 
 // DeployDeviceWithImage Move a device from the undefined container to a target container
 // and apply image. Optionally, apply device-specific configlets to the device.
 func (c CvpRestAPI) DeployDeviceWithImage(appName string, dev *NetElement, devTargetIP string,
 	container *Container, image string, configlets ...Configlet) (*TaskInfo, error) {
+	return c.DeployDeviceWithImageCtx(context.Background(), appName, dev, devTargetIP, container,
+		image, configlets...)
+}
+
+// DeployDeviceWithImageCtx is the context aware version of DeployDeviceWithImage.
+func (c CvpRestAPI) DeployDeviceWithImageCtx(ctx context.Context, appName string, dev *NetElement,
+	devTargetIP string, container *Container, image string,
+	configlets ...Configlet) (*TaskInfo, error) {
 
-	if _, err := c.MoveDeviceToContainer(appName, dev, container, false); err != nil {
-		c.ClearAllTempActions()
-		return nil, errors.Errorf("DeployDeviceWithImage: %s", err)
+	if _, err := c.MoveDeviceToContainerCtx(ctx, appName, dev, container, false); err != nil {
+		c.ClearAllTempActionsCtx(ctx)
+		return nil, errors.Wrap(err, "DeployDeviceWithImage")
 	}
 
-	applyConfiglets, err := c.GenerateHierarchicalConfiglets(dev, container)
+	applyConfiglets, err := c.GenerateHierarchicalConfigletsCtx(ctx, dev, container)
 	if err != nil {
-		return nil, errors.Errorf("DeployDeviceWithImage: %s", err)
+		return nil, errors.Wrap(err, "DeployDeviceWithImage")
 	}
 
-	conf, err := c.GetTempConfigByNetElementID(dev.SystemMacAddress)
+	conf, err := c.GetTempConfigByNetElementIDCtx(ctx, dev.SystemMacAddress)
 	if err != nil {
-		return nil, errors.Errorf("DeployDeviceWithImage: %s", err)
+		return nil, errors.Wrap(err, "DeployDeviceWithImage")
 	}
 
 	applyConfiglets = append(applyConfiglets, conf.ProposedConfiglets...)
@@ -1524,9 +1761,9 @@ func (c CvpRestAPI) DeployDeviceWithImage(appName string, dev *NetElement, devTa
 		applyConfiglets = append(applyConfiglets, configlets...)
 	}
 
-	curConfiglets, err := c.GetConfigletsByDeviceID(dev.SystemMacAddress)
+	curConfiglets, err := c.GetConfigletsByDeviceIDCtx(ctx, dev.SystemMacAddress)
 	if err != nil {
-		return nil, errors.Errorf("DeployDeviceWithImage: %s", err)
+		return nil, errors.Wrap(err, "DeployDeviceWithImage")
 	}
 
 	_, cnames, ckeys, cbnames, cbkeys, err := checkConfigMapping(curConfiglets,
@@ -1571,26 +1808,26 @@ func (c CvpRestAPI) DeployDeviceWithImage(appName string, dev *NetElement, devTa
 		},
 	}}
 
-	if err := c.addTempAction(data); err != nil {
-		c.ClearAllTempActions()
-		return nil, errors.Errorf("DeployDeviceWithImage: %s", err)
+	if err := c.addTempAction(ctx, data); err != nil {
+		c.ClearAllTempActionsCtx(ctx)
+		return nil, errors.Wrap(err, "DeployDeviceWithImage")
 	}
 
 	if image != "" {
-		imageBundle, err := c.GetImageBundleByName(image)
+		imageBundle, err := c.GetImageBundleByNameCtx(ctx, image)
 		if err != nil {
-			return nil, errors.Errorf("DeployDeviceWithImage: %s", err)
+			return nil, errors.Wrap(err, "DeployDeviceWithImage")
 		}
-		if _, err = c.ApplyImageToDevice(appName, imageBundle, dev, false); err != nil {
-			c.ClearAllTempActions()
-			return nil, errors.Errorf("DeployDeviceWithImage: %s", err)
+		if _, err = c.ApplyImageToDeviceCtx(ctx, appName, imageBundle, dev, false); err != nil {
+			c.ClearAllTempActionsCtx(ctx)
+			return nil, errors.Wrap(err, "DeployDeviceWithImage")
 		}
 	}
 
 	// Clear the temp Actions if we have issues
 	var taskInfo *TaskInfo
-	if taskInfo, err = c.SaveTopology(); err != nil {
-		c.ClearAllTempActions()
+	if taskInfo, err = c.SaveTopologyCtx(ctx); err != nil {
+		c.ClearAllTempActionsCtx(ctx)
 		return nil, errors.Wrap(err, "DeployDeviceWithImage")
 	}
 	return taskInfo, nil
@@ -1598,6 +1835,12 @@ func (c CvpRestAPI) DeployDeviceWithImage(appName string, dev *NetElement, devTa
 
 // GenerateHierarchicalConfiglets ...
 func (c CvpRestAPI) GenerateHierarchicalConfiglets(dev *NetElement,
+	container *Container) ([]Configlet, error) {
+	return c.GenerateHierarchicalConfigletsCtx(context.Background(), dev, container)
+}
+
+// GenerateHierarchicalConfigletsCtx is the context aware version of GenerateHierarchicalConfiglets.
+func (c CvpRestAPI) GenerateHierarchicalConfigletsCtx(ctx context.Context, dev *NetElement,
 	container *Container) ([]Configlet, error) {
 	var applyConfiglets []Configlet
 
@@ -1609,14 +1852,14 @@ func (c CvpRestAPI) GenerateHierarchicalConfiglets(dev *NetElement,
 	}
 
 	// The the hierarchical Configlet BuilderInfo
-	cblInfoList, err := c.GetHierarchicalConfigletBuilders(container)
+	cblInfoList, err := c.GetHierarchicalConfigletBuildersCtx(ctx, container)
 	if err != nil {
 		return nil, errors.Wrap(err, "GenerateHierarchicalConfiglets")
 	}
 
 	// Generate configlets using the builders
 	for _, builder := range cblInfoList.BuildMapperList {
-		cb, err := c.GetConfigletBuilderByKey(builder.BuilderID)
+		cb, err := c.GetConfigletBuilderByKeyCtx(ctx, builder.BuilderID)
 		if err != nil {
 			return nil, errors.Wrap(err, "GenerateHierarchicalConfiglets")
 		}
@@ -1625,8 +1868,8 @@ func (c CvpRestAPI) GenerateHierarchicalConfiglets(dev *NetElement,
 			continue
 		}
 
-		cbInfo, err := c.GenerateAutoConfiglet([]string{dev.SystemMacAddress}, builder.BuilderID,
-			container.Key, "netelement")
+		cbInfo, err := c.GenerateAutoConfigletCtx(ctx, []string{dev.SystemMacAddress},
+			builder.BuilderID, container.Key, "netelement")
 		if err != nil {
 			return nil, errors.Wrap(err, "GenerateHierarchicalConfiglets")
 		}
@@ -1656,14 +1899,21 @@ type TempConfig struct {
 
 // GetTempConfigByNetElementID gets the current temporary config for the supplied netElement
 func (c CvpRestAPI) GetTempConfigByNetElementID(netElementID string) (*TempConfig, error) {
+	return c.GetTempConfigByNetElementIDCtx(context.Background(), netElementID)
+}
+
+// GetTempConfigByNetElementIDCtx is the context aware version of GetTempConfigByNetElementID.
+func (c CvpRestAPI) GetTempConfigByNetElementIDCtx(ctx context.Context,
+	netElementID string) (*TempConfig, error) {
 	var resp TempConfig
 	query := &url.Values{
 		"netElementId": {netElementID},
 	}
 
-	reqResp, err := c.client.Get("/provisioning/getTempConfigsByNetElementId.do", query)
+	reqResp, err := c.get(ctx, "/provisioning/getTempConfigsByNetElementId.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetTempConfigByNetElementID: %s", err)
+		return nil, wrapError("GetTempConfigByNetElementID",
+			"/provisioning/getTempConfigsByNetElementId.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -1671,7 +1921,8 @@ func (c CvpRestAPI) GetTempConfigByNetElementID(netElementID string) (*TempConfi
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("GetTempConfigByNetElementID: %s", err)
+		return nil, wrapError("GetTempConfigByNetElementID",
+			"/provisioning/getTempConfigsByNetElementId.do", err)
 	}
 	return &resp, nil
 
@@ -1679,22 +1930,27 @@ func (c CvpRestAPI) GetTempConfigByNetElementID(netElementID string) (*TempConfi
 
 // ClearAllTempActions clears outstanding actions
 func (c CvpRestAPI) ClearAllTempActions() (string, error) {
+	return c.ClearAllTempActionsCtx(context.Background())
+}
+
+// ClearAllTempActionsCtx is the context aware version of ClearAllTempActions.
+func (c CvpRestAPI) ClearAllTempActionsCtx(ctx context.Context) (string, error) {
 	var resp struct {
 		Data string `json:"data"`
 		ErrorResponse
 	}
 
-	reqResp, err := c.client.Delete("/ztp/deleteAllTempAction.do", nil, nil)
+	reqResp, err := c.delete(ctx, "/ztp/deleteAllTempAction.do", nil, nil)
 	if err != nil {
-		return "", errors.Errorf("GetTempActions: %s", err)
+		return "", wrapError("ClearAllTempActions", "/ztp/deleteAllTempAction.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
-		return "", errors.Errorf("GetTempActions: %s Payload:\n%s", err, reqResp)
+		return "", errors.Errorf("ClearAllTempActions: %s Payload:\n%s", err, reqResp)
 	}
 
 	if err := resp.Error(); err != nil {
-		return "", errors.Errorf("GetTempActions: %s", err)
+		return "", wrapError("ClearAllTempActions", "/ztp/deleteAllTempAction.do", err)
 	}
 	return resp.Data, nil
 
@@ -1702,6 +1958,11 @@ func (c CvpRestAPI) ClearAllTempActions() (string, error) {
 
 // GetAllTempActions gets the list of current actions outstanding
 func (c CvpRestAPI) GetAllTempActions(start, end int) ([]Action, error) {
+	return c.GetAllTempActionsCtx(context.Background(), start, end)
+}
+
+// GetAllTempActionsCtx is the context aware version of GetAllTempActions.
+func (c CvpRestAPI) GetAllTempActionsCtx(ctx context.Context, start, end int) ([]Action, error) {
 	var resp struct {
 		Total int
 		Data  []Action
@@ -1713,17 +1974,17 @@ func (c CvpRestAPI) GetAllTempActions(start, end int) ([]Action, error) {
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	reqResp, err := c.client.Get("/provisioning/getAllTempActions.do", query)
+	reqResp, err := c.get(ctx, "/provisioning/getAllTempActions.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetTempActions: %s", err)
+		return nil, wrapError("GetAllTempActions", "/provisioning/getAllTempActions.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
-		return nil, errors.Errorf("GetTempActions: %s Payload:\n%s", err, reqResp)
+		return nil, errors.Errorf("GetAllTempActions: %s Payload:\n%s", err, reqResp)
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("GetTempActions: %s", err)
+		return nil, wrapError("GetAllTempActions", "/provisioning/getAllTempActions.do", err)
 	}
 	return resp.Data, nil
 
@@ -1731,9 +1992,14 @@ func (c CvpRestAPI) GetAllTempActions(start, end int) ([]Action, error) {
 
 // GetTempAction returns the first outstanding action
 func (c CvpRestAPI) GetTempAction() (*Action, error) {
-	results, err := c.GetAllTempActions(0, 1)
+	return c.GetTempActionCtx(context.Background())
+}
+
+// GetTempActionCtx is the context aware version of GetTempAction.
+func (c CvpRestAPI) GetTempActionCtx(ctx context.Context) (*Action, error) {
+	results, err := c.GetAllTempActionsCtx(ctx, 0, 1)
 	if err != nil {
-		return nil, errors.Errorf("GetTempAction: %s", err)
+		return nil, errors.Wrap(err, "GetTempAction")
 	}
 	if len(results) > 0 {
 		return &results[0], nil
@@ -1745,6 +2011,12 @@ func (c CvpRestAPI) GetTempAction() (*Action, error) {
 // and returning those within the specified range.
 func (c CvpRestAPI) FilterTopologyWithRange(nodeID, querystr, format string, start int,
 	end int) (*Topology, error) {
+	return c.FilterTopologyWithRangeCtx(context.Background(), nodeID, querystr, format, start, end)
+}
+
+// FilterTopologyWithRangeCtx is the context aware version of FilterTopologyWithRange.
+func (c CvpRestAPI) FilterTopologyWithRangeCtx(ctx context.Context, nodeID, querystr, format string,
+	start int, end int) (*Topology, error) {
 
 	query := &url.Values{
 		"nodeId":     {nodeID},
@@ -1761,9 +2033,9 @@ func (c CvpRestAPI) FilterTopologyWithRange(nodeID, querystr, format string, sta
 		ErrorResponse
 	}{}
 
-	reqResp, err := c.client.Get("/ztp/filterTopology.do", query)
+	reqResp, err := c.get(ctx, "/ztp/filterTopology.do", query)
 	if err != nil {
-		return nil, errors.Errorf("FilterTopologyWithRange: %s", err)
+		return nil, wrapError("FilterTopologyWithRange", "/ztp/filterTopology.do", err)
 	}
 
 	if err = json.Unmarshal(reqResp, &resp); err != nil {
@@ -1771,14 +2043,20 @@ func (c CvpRestAPI) FilterTopologyWithRange(nodeID, querystr, format string, sta
 	}
 
 	if err := resp.Error(); err != nil {
-		return nil, errors.Errorf("FilterTopologyWithRange: %s", err)
+		return nil, wrapError("FilterTopologyWithRange", "/ztp/filterTopology.do", err)
 	}
 	return &resp.Topology, nil
 }
 
 // FilterTopology filters the topology for items matching the query parameter.
 func (c CvpRestAPI) FilterTopology(nodeID, query string) (*Topology, error) {
-	return c.FilterTopologyWithRange(nodeID, query, "topology", 0, 0)
+	return c.FilterTopologyCtx(context.Background(), nodeID, query)
+}
+
+// FilterTopologyCtx is the context aware version of FilterTopology.
+func (c CvpRestAPI) FilterTopologyCtx(ctx context.Context, nodeID,
+	query string) (*Topology, error) {
+	return c.FilterTopologyWithRangeCtx(ctx, nodeID, query, "topology", 0, 0)
 }
 
 // checkConfigMapping Checks whether the new configlets to be applied are
@@ -1922,8 +2200,63 @@ func checkRemoveConfigMapping(applied []Configlet, rmConfiglets []Configlet) (bo
 	return actionReqd, configletNames, builderNames, rmConfigletNames, rmBuilderNames, nil
 }
 
-func (c CvpRestAPI) getConfigletKeys(configletNames []string) ([]string, error) {
-	configletInfo, err := c.GetConfiglets()
+// configletKeysAndNames holds the configlet and configlet builder keys/names
+// used to build an Action
+type configletKeysAndNames struct {
+	keys   []string
+	names  []string
+	bKeys  []string
+	bNames []string
+}
+
+func (k *configletKeysAndNames) add(configlet Configlet) error {
+	switch configlet.Type {
+	case "Static":
+		fallthrough
+	case "Generated":
+		fallthrough
+	case "Reconciled":
+		k.keys = append(k.keys, configlet.Key)
+		k.names = append(k.names, configlet.Name)
+	case "Builder":
+		k.bKeys = append(k.bKeys, configlet.Key)
+		k.bNames = append(k.bNames, configlet.Name)
+	default:
+		return errors.Errorf("Configlet [%s] Invalid Type [%s]", configlet.Name, configlet.Type)
+	}
+	return nil
+}
+
+// changesNeeded returns the configlets that must be applied in order to end up
+// with exactly the desired configlets, and the currently applied configlets
+// that need to be removed.
+func changesNeeded(current []Configlet, desired []Configlet) (*configletKeysAndNames,
+	*configletKeysAndNames, error) {
+	newCAndB := &configletKeysAndNames{}
+	rmCAndB := &configletKeysAndNames{}
+
+	desiredMap := make(map[string]bool, len(desired))
+	for _, configlet := range desired {
+		desiredMap[configlet.Key] = true
+		if err := newCAndB.add(configlet); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, configlet := range current {
+		if desiredMap[configlet.Key] {
+			continue
+		}
+		if err := rmCAndB.add(configlet); err != nil {
+			return nil, nil, err
+		}
+	}
+	return newCAndB, rmCAndB, nil
+}
+
+func (c CvpRestAPI) getConfigletKeys(ctx context.Context,
+	configletNames []string) ([]string, error) {
+	configletInfo, err := c.GetConfigletsCtx(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "getConfigletKeys")
 	}
@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"strconv"
@@ -57,6 +58,11 @@ type SingleRole struct {
 
 // GetAllRoles returns all the existing roles in CVP
 func (c CvpRestAPI) GetAllRoles(start, end int) (*RoleList, error) {
+	return c.GetAllRolesCtx(context.Background(), start, end)
+}
+
+// GetAllRolesCtx is the context aware version of GetAllRoles.
+func (c CvpRestAPI) GetAllRolesCtx(ctx context.Context, start, end int) (*RoleList, error) {
 	var roles RoleList
 
 	query := &url.Values{
@@ -65,9 +71,9 @@ func (c CvpRestAPI) GetAllRoles(start, end int) (*RoleList, error) {
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/role/getRoles.do", query)
+	resp, err := c.get(ctx, "/role/getRoles.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetAllRoles: %s", err)
+		return nil, wrapError("GetAllRoles", "/role/getRoles.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &roles); err != nil {
@@ -76,23 +82,28 @@ func (c CvpRestAPI) GetAllRoles(start, end int) (*RoleList, error) {
 
 	if err := roles.Error(); err != nil {
 		// Entity does not exist
-		if roles.ErrorCode == "132801" {
+		if roles.ErrorCode == ENTITY_DOES_NOT_EXIST {
 			return nil, nil
 		}
-		return nil, errors.Errorf("GetAllRoles: %s", err)
+		return nil, wrapError("GetAllRoles", "/role/getRoles.do", err)
 	}
 	return &roles, nil
 }
 
 // GetRoleByID returns the role with ID 'roleID'
 func (c CvpRestAPI) GetRoleByID(roleID string) (*SingleRole, error) {
+	return c.GetRoleByIDCtx(context.Background(), roleID)
+}
+
+// GetRoleByIDCtx is the context aware version of GetRoleByID.
+func (c CvpRestAPI) GetRoleByIDCtx(ctx context.Context, roleID string) (*SingleRole, error) {
 	var info SingleRole
 
 	query := &url.Values{"roleId": {roleID}}
 
-	resp, err := c.client.Get("/role/getRole.do", query)
+	resp, err := c.get(ctx, "/role/getRole.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetRoleByID: %s", err)
+		return nil, wrapError("GetRoleByID", "/role/getRole.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -101,10 +112,10 @@ func (c CvpRestAPI) GetRoleByID(roleID string) (*SingleRole, error) {
 
 	if err := info.Error(); err != nil {
 		// Entity does not exist
-		if info.ErrorCode == "132801" {
+		if info.ErrorCode == ENTITY_DOES_NOT_EXIST {
 			return nil, nil
 		}
-		return nil, errors.Errorf("GetRoleByID: %s", err)
+		return nil, wrapError("GetRoleByID", "/role/getRole.do", err)
 	}
 	return &info, nil
 }
@@ -112,9 +123,14 @@ func (c CvpRestAPI) GetRoleByID(roleID string) (*SingleRole, error) {
 // GetRoleByName returns a role having name 'roleName'
 // by querying for all the roles and returning one that matches
 func (c CvpRestAPI) GetRoleByName(roleName string) (*SingleRole, error) {
-	allRoles, err := c.GetAllRoles(0, 0)
+	return c.GetRoleByNameCtx(context.Background(), roleName)
+}
+
+// GetRoleByNameCtx is the context aware version of GetRoleByName.
+func (c CvpRestAPI) GetRoleByNameCtx(ctx context.Context, roleName string) (*SingleRole, error) {
+	allRoles, err := c.GetAllRolesCtx(ctx, 0, 0)
 	if err != nil {
-		return nil, errors.Errorf("GetRoleByName: [%s]", err)
+		return nil, wrapErrorf("GetRoleByName", "", err, "GetRoleByName: [%s]", err)
 	}
 	if allRoles != nil {
 		for _, role := range allRoles.Roles {
@@ -128,13 +144,19 @@ func (c CvpRestAPI) GetRoleByName(roleName string) (*SingleRole, error) {
 
 // AddRole adds a custom role
 func (c CvpRestAPI) AddRole(role *SingleRole) (*SingleRole, error) {
+	return c.AddRoleCtx(context.Background(), role)
+}
+
+// AddRoleCtx is the context aware version of AddRole.
+func (c CvpRestAPI) AddRoleCtx(ctx context.Context, role *SingleRole) (*SingleRole, error) {
 	if role == nil {
 		return nil, errors.New("AddRole: can not add nil role")
 	}
 
-	resp, err := c.client.Post("/role/createRole.do", nil, role.RoleData)
+	resp, err := c.post(ctx, "/role/createRole.do", nil, role.RoleData)
 	if err != nil {
-		return nil, errors.Errorf("AddRole: Error: [%v]", err)
+		return nil, wrapErrorf("AddRole", "/role/createRole.do", err,
+			"AddRole: Error: [%v]", err)
 	}
 	var returnedRole SingleRole
 	if err = json.Unmarshal(resp, &returnedRole); err != nil {
@@ -143,9 +165,10 @@ func (c CvpRestAPI) AddRole(role *SingleRole) (*SingleRole, error) {
 	var retErr error
 	if err = returnedRole.Error(); err != nil {
 		if returnedRole.ErrorCode == ROLE_ALREADY_EXISTS {
-			retErr = errors.Errorf("AddRole: Role with key '%s' already exists", role.RoleData.Key)
+			retErr = wrapErrorf("AddRole", "/role/createRole.do", err,
+				"AddRole: Role with key '%s' already exists", role.RoleData.Key)
 		} else {
-			retErr = errors.Errorf("AddRole: %s", err)
+			retErr = wrapError("AddRole", "/role/createRole.do", err)
 		}
 	}
 	if retErr == nil {
@@ -156,12 +179,18 @@ func (c CvpRestAPI) AddRole(role *SingleRole) (*SingleRole, error) {
 
 // DeleteRoles deletes the roles with specified keys
 func (c CvpRestAPI) DeleteRoles(roleIds []string) error {
+	return c.DeleteRolesCtx(context.Background(), roleIds)
+}
+
+// DeleteRolesCtx is the context aware version of DeleteRoles.
+func (c CvpRestAPI) DeleteRolesCtx(ctx context.Context, roleIds []string) error {
 	if len(roleIds) == 0 {
 		return errors.New("DeleteRoles: empty roleId list")
 	}
-	resp, err := c.client.Post("/role/deleteRoles.do", nil, roleIds)
+	resp, err := c.post(ctx, "/role/deleteRoles.do", nil, roleIds)
 	if err != nil {
-		return errors.Errorf("DeleteRoles: Error: [%v]", err)
+		return wrapErrorf("DeleteRoles", "/role/deleteRoles.do", err,
+			"DeleteRoles: Error: [%v]", err)
 	}
 	var msg struct {
 		ResponseMessage string `json:"data"`
@@ -174,11 +203,14 @@ func (c CvpRestAPI) DeleteRoles(roleIds []string) error {
 	if err := msg.Error(); err != nil {
 		switch msg.ErrorCode {
 		case DEFAULT_ROLE_DELETE:
-			retErr = errors.Errorf("DeleteRoles: can not delete default role: [%s]", roleIds)
+			retErr = wrapErrorf("DeleteRoles", "/role/deleteRoles.do", err,
+				"DeleteRoles: can not delete default role: [%s]", roleIds)
 		case INVALID_ROLE, ENTITY_DOES_NOT_EXIST:
-			retErr = errors.Errorf("DeleteRoles: one of the role in [%s] does not exist", roleIds)
+			retErr = wrapErrorf("DeleteRoles", "/role/deleteRoles.do", err,
+				"DeleteRoles: one of the role in [%s] does not exist", roleIds)
 		default:
-			retErr = errors.Errorf("DeleteRoles: Unexpected error: %v", err)
+			retErr = wrapErrorf("DeleteRoles", "/role/deleteRoles.do", err,
+				"DeleteRoles: Unexpected error: %v", err)
 		}
 	} else {
 		lowerCaseResp := strings.ToLower(msg.ResponseMessage)
@@ -192,12 +224,18 @@ func (c CvpRestAPI) DeleteRoles(roleIds []string) error {
 
 // UpdateRole updates the given role and also the user permissions
 func (c CvpRestAPI) UpdateRole(role *SingleRole) error {
+	return c.UpdateRoleCtx(context.Background(), role)
+}
+
+// UpdateRoleCtx is the context aware version of UpdateRole.
+func (c CvpRestAPI) UpdateRoleCtx(ctx context.Context, role *SingleRole) error {
 	if role == nil {
 		return errors.New("UpdateRole: can not update a nil role")
 	}
-	resp, err := c.client.Post("/role/updateRole.do", nil, role.RoleData)
+	resp, err := c.post(ctx, "/role/updateRole.do", nil, role.RoleData)
 	if err != nil {
-		return errors.Errorf("UpdateRole: Error: [%v]", err)
+		return wrapErrorf("UpdateRole", "/role/updateRole.do", err,
+			"UpdateRole: Error: [%v]", err)
 	}
 	var msg struct {
 		ResponseMessage string `json:"data"`
@@ -207,7 +245,8 @@ func (c CvpRestAPI) UpdateRole(role *SingleRole) error {
 		return errors.Errorf("UpdateRole: unmarshal error - [%v] \nin response - [%v]", err, resp)
 	}
 	if err = msg.Error(); err != nil {
-		return errors.Errorf("UpdateRole: Unexpected error - [%v]", err)
+		return wrapErrorf("UpdateRole", "/role/updateRole.do", err,
+			"UpdateRole: Unexpected error - [%v]", err)
 	}
 	lowerCaseResp := strings.ToLower(msg.ResponseMessage)
 	if !strings.Contains(lowerCaseResp, successMsg) {
@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"strconv"
@@ -114,13 +115,18 @@ type CvpLogList struct {
 
 // GetTaskByID returns the current Task for the specified taskID.
 func (c CvpRestAPI) GetTaskByID(taskID int) (*CvpTask, error) {
+	return c.GetTaskByIDCtx(context.Background(), taskID)
+}
+
+// GetTaskByIDCtx is the context aware version of GetTaskByID.
+func (c CvpRestAPI) GetTaskByIDCtx(ctx context.Context, taskID int) (*CvpTask, error) {
 	var info CvpTask
 
 	query := &url.Values{"taskId": {strconv.Itoa(taskID)}}
 
-	resp, err := c.client.Get("/task/getTaskById.do", query)
+	resp, err := c.get(ctx, "/task/getTaskById.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetTaskByID: %s", err)
+		return nil, wrapError("GetTaskByID", "/task/getTaskById.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -128,7 +134,7 @@ func (c CvpRestAPI) GetTaskByID(taskID int) (*CvpTask, error) {
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetTaskByID: %s", err)
+		return nil, wrapError("GetTaskByID", "/task/getTaskById.do", err)
 	}
 
 	return &info, nil
@@ -137,6 +143,22 @@ func (c CvpRestAPI) GetTaskByID(taskID int) (*CvpTask, error) {
 // GetTasks returns the current CVP Tasks that match the provided string
 // and within the provided start/end range.
 func (c CvpRestAPI) GetTasks(queryStr string, start int, end int) ([]CvpTask, error) {
+	return c.GetTasksCtx(context.Background(), queryStr, start, end)
+}
+
+// GetTasksCtx is the context aware version of GetTasks.
+func (c CvpRestAPI) GetTasksCtx(ctx context.Context, queryStr string, start int,
+	end int) ([]CvpTask, error) {
+	info, err := c.getTasks(ctx, queryStr, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return info.Data, nil
+}
+
+// getTasks fetches a range of tasks along with the total number of matches.
+func (c CvpRestAPI) getTasks(ctx context.Context, queryStr string, start int,
+	end int) (*CvpTaskList, error) {
 	var info CvpTaskList
 	query := &url.Values{
 		"queryparam": {queryStr},
@@ -144,9 +166,9 @@ func (c CvpRestAPI) GetTasks(queryStr string, start int, end int) ([]CvpTask, er
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/workflow/getTasks.do", query)
+	resp, err := c.get(ctx, "/workflow/getTasks.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetTasks: %s", err)
+		return nil, wrapError("GetTasks", "/workflow/getTasks.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -154,25 +176,41 @@ func (c CvpRestAPI) GetTasks(queryStr string, start int, end int) ([]CvpTask, er
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetTasks: %s", err)
+		return nil, wrapError("GetTasks", "/workflow/getTasks.do", err)
 	}
 
-	return info.Data, nil
+	return &info, nil
 }
 
 // GetTaskByStatus returns a list of all tasks with the given status.
 func (c CvpRestAPI) GetTaskByStatus(status string) ([]CvpTask, error) {
-	return c.GetTasks(status, 0, 0)
+	return c.GetTaskByStatusCtx(context.Background(), status)
+}
+
+// GetTaskByStatusCtx is the context aware version of GetTaskByStatus.
+func (c CvpRestAPI) GetTaskByStatusCtx(ctx context.Context, status string) ([]CvpTask, error) {
+	return c.GetTasksCtx(ctx, status, 0, 0)
 }
 
 // GetAllTasks returns a list of all the tasks.
 func (c CvpRestAPI) GetAllTasks() ([]CvpTask, error) {
-	return c.GetTasks("", 0, 0)
+	return c.GetAllTasksCtx(context.Background())
+}
+
+// GetAllTasksCtx is the context aware version of GetAllTasks.
+func (c CvpRestAPI) GetAllTasksCtx(ctx context.Context) ([]CvpTask, error) {
+	return c.GetTasksCtx(ctx, "", 0, 0)
 }
 
 // GetLogs returns the log entries for the task with the specified taskID and
 // within the provide start/end range.
 func (c CvpRestAPI) GetLogs(taskID int, start int, end int) ([]LogData, error) {
+	return c.GetLogsCtx(context.Background(), taskID, start, end)
+}
+
+// GetLogsCtx is the context aware version of GetLogs.
+func (c CvpRestAPI) GetLogsCtx(ctx context.Context, taskID int, start int,
+	end int) ([]LogData, error) {
 	var info CvpLogList
 	query := &url.Values{
 		"id":         {strconv.Itoa(taskID)},
@@ -181,9 +219,9 @@ func (c CvpRestAPI) GetLogs(taskID int, start int, end int) ([]LogData, error) {
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/task/getLogsById.do", query)
+	resp, err := c.get(ctx, "/task/getLogsById.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetLogs: %s", err)
+		return nil, wrapError("GetLogs", "/task/getLogsById.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -191,7 +229,7 @@ func (c CvpRestAPI) GetLogs(taskID int, start int, end int) ([]LogData, error) {
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetLogs: %s", err)
+		return nil, wrapError("GetLogs", "/task/getLogsById.do", err)
 	}
 
 	return info.Data, nil
@@ -199,20 +237,30 @@ func (c CvpRestAPI) GetLogs(taskID int, start int, end int) ([]LogData, error) {
 
 // GetLogsByID returns the log entries for the task with the specified taskID.
 func (c CvpRestAPI) GetLogsByID(taskID int) ([]LogData, error) {
-	return c.GetLogs(taskID, 0, 0)
+	return c.GetLogsByIDCtx(context.Background(), taskID)
+}
+
+// GetLogsByIDCtx is the context aware version of GetLogsByID.
+func (c CvpRestAPI) GetLogsByIDCtx(ctx context.Context, taskID int) ([]LogData, error) {
+	return c.GetLogsCtx(ctx, taskID, 0, 0)
 }
 
 // AddNoteToTask adds a note to the task represented by taskID
 func (c CvpRestAPI) AddNoteToTask(taskID int, note string) error {
+	return c.AddNoteToTaskCtx(context.Background(), taskID, note)
+}
+
+// AddNoteToTaskCtx is the context aware version of AddNoteToTask.
+func (c CvpRestAPI) AddNoteToTaskCtx(ctx context.Context, taskID int, note string) error {
 	var info ErrorResponse
 
 	data := map[string]string{
 		"workOrderId": strconv.Itoa(taskID),
 		"note":        note,
 	}
-	resp, err := c.client.Post("/task/addNoteToTask.do", nil, data)
+	resp, err := c.post(ctx, "/task/addNoteToTask.do", nil, data)
 	if err != nil {
-		return errors.Errorf("AddNoteToTask: %s", err)
+		return wrapError("AddNoteToTask", "/task/addNoteToTask.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -220,18 +268,28 @@ func (c CvpRestAPI) AddNoteToTask(taskID int, note string) error {
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("AddNoteToTask: %s", err)
+		return wrapError("AddNoteToTask", "/task/addNoteToTask.do", err)
 	}
 	return nil
 }
 
 // ExecuteTask executes a task given the taskID.
 func (c CvpRestAPI) ExecuteTask(taskID int) error {
-	return c.ExecuteTasks([]int{taskID})
+	return c.ExecuteTaskCtx(context.Background(), taskID)
+}
+
+// ExecuteTaskCtx is the context aware version of ExecuteTask.
+func (c CvpRestAPI) ExecuteTaskCtx(ctx context.Context, taskID int) error {
+	return c.ExecuteTasksCtx(ctx, []int{taskID})
 }
 
 // ExecuteTasks executes a task given the taskID.
 func (c CvpRestAPI) ExecuteTasks(taskID []int) error {
+	return c.ExecuteTasksCtx(context.Background(), taskID)
+}
+
+// ExecuteTasksCtx is the context aware version of ExecuteTasks.
+func (c CvpRestAPI) ExecuteTasksCtx(ctx context.Context, taskID []int) error {
 	var info ErrorResponse
 	var taskIDs []string
 
@@ -242,9 +300,9 @@ func (c CvpRestAPI) ExecuteTasks(taskID []int) error {
 	data := map[string][]string{
 		"data": taskIDs,
 	}
-	resp, err := c.client.Post("/workflow/executeTask.do", nil, data)
+	resp, err := c.post(ctx, "/workflow/executeTask.do", nil, data)
 	if err != nil {
-		return errors.Errorf("ExecuteTask: %s", err)
+		return wrapError("ExecuteTask", "/workflow/executeTask.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -252,18 +310,28 @@ func (c CvpRestAPI) ExecuteTasks(taskID []int) error {
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("ExecuteTask: %s", err)
+		return wrapError("ExecuteTask", "/workflow/executeTask.do", err)
 	}
 	return nil
 }
 
 // CancelTask cancels the task given the taskID
 func (c CvpRestAPI) CancelTask(taskID int) error {
-	return c.CancelTasks([]int{taskID})
+	return c.CancelTaskCtx(context.Background(), taskID)
+}
+
+// CancelTaskCtx is the context aware version of CancelTask.
+func (c CvpRestAPI) CancelTaskCtx(ctx context.Context, taskID int) error {
+	return c.CancelTasksCtx(ctx, []int{taskID})
 }
 
 // CancelTasks cancels the list of taskIDs
 func (c CvpRestAPI) CancelTasks(taskID []int) error {
+	return c.CancelTasksCtx(context.Background(), taskID)
+}
+
+// CancelTasksCtx is the context aware version of CancelTasks.
+func (c CvpRestAPI) CancelTasksCtx(ctx context.Context, taskID []int) error {
 	var info ErrorResponse
 	var taskIDs []string
 
@@ -274,9 +342,9 @@ func (c CvpRestAPI) CancelTasks(taskID []int) error {
 	data := map[string][]string{
 		"data": taskIDs,
 	}
-	resp, err := c.client.Post("/task/cancelTask.do", nil, data)
+	resp, err := c.post(ctx, "/task/cancelTask.do", nil, data)
 	if err != nil {
-		return errors.Errorf("CancelTask: %s", err)
+		return wrapError("CancelTask", "/task/cancelTask.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -284,7 +352,7 @@ func (c CvpRestAPI) CancelTasks(taskID []int) error {
 	}
 
 	if err := info.Error(); err != nil {
-		return errors.Errorf("CancelTask: %s", err)
+		return wrapError("CancelTask", "/task/cancelTask.do", err)
 	}
 	return nil
 }
@@ -0,0 +1,290 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// TaskRunPolicy configures how RunTasks executes the tasks.
+type TaskRunPolicy struct {
+	// WaveSize is the number of tasks executed at once. Zero executes every
+	// task of a group at once.
+	WaveSize int
+	// GroupBy, if set, returns the group of a task, see GroupByContainer
+	// and GroupByDevice. Groups run one after the other, in the order
+	// their first task was given, each in waves of WaveSize.
+	GroupBy func(*CvpTask) string
+	// MaxFailureRatio stops the run after a wave once the ratio of
+	// executed tasks that didn't complete exceeds it. The zero value stops
+	// at the first failure, 1 never stops.
+	MaxFailureRatio float64
+	// CancelRemaining cancels the tasks left once the run stops.
+	CancelRemaining bool
+	// Wait is the policy used to wait for each wave. Its Timeout applies to
+	// every wave.
+	Wait TaskWaitPolicy
+	// OnWave, if set, is called before executing each wave. Waves are
+	// numbered from 1 across groups.
+	OnWave func(group string, wave int, taskIDs []int)
+}
+
+// GroupByContainer groups the tasks by the container of their device once
+// the task is executed.
+func GroupByContainer(task *CvpTask) string {
+	if task.Data.NewparentContainerID != "" {
+		return task.Data.NewparentContainerID
+	}
+	return task.Data.CurrentparentContainerID
+}
+
+// GroupByDevice groups the tasks by the group of their device, e.g. a tag
+// value, in groups keyed by the device system MAC address. Tasks of other
+// devices are in the "" group.
+func GroupByDevice(groups map[string]string) func(*CvpTask) string {
+	return func(task *CvpTask) string {
+		return groups[task.WorkOrderDetails.NetElementID]
+	}
+}
+
+// TaskResult is the outcome of a task given to RunTasks.
+type TaskResult struct {
+	TaskID int
+	Group  string
+	// Wave is the wave the task was executed in, 0 if it wasn't executed
+	Wave int
+	// Status is the last status of the task
+	Status string
+	// Task is the last state polled of the task
+	Task *CvpTask
+	// Err is the error executing or waiting for the task, if any
+	Err error
+	// Logs holds the logs of a failed task
+	Logs []LogData
+}
+
+// Executed reports whether RunTasks executed the task.
+func (r *TaskResult) Executed() bool {
+	return r.Wave > 0
+}
+
+// Succeeded reports whether the task was executed and completed.
+func (r *TaskResult) Succeeded() bool {
+	return r.Executed() && r.Err == nil && r.Status == TaskCompleted
+}
+
+// TaskRunReport is the outcome of RunTasks.
+type TaskRunReport struct {
+	// Results holds the outcome of every task, in the order they were given
+	Results []*TaskResult
+	// Stopped is set if the run stopped as MaxFailureRatio was exceeded
+	Stopped bool
+	// Waves is the number of waves executed
+	Waves int
+}
+
+// Result returns the outcome of the task, nil if it wasn't given to RunTasks.
+func (r *TaskRunReport) Result(taskID int) *TaskResult {
+	for _, res := range r.Results {
+		if res.TaskID == taskID {
+			return res
+		}
+	}
+	return nil
+}
+
+// Executed returns the IDs of the tasks executed.
+func (r *TaskRunReport) Executed() []int {
+	return r.filter(func(res *TaskResult) bool { return res.Executed() })
+}
+
+// Failed returns the IDs of the tasks executed that didn't complete.
+func (r *TaskRunReport) Failed() []int {
+	return r.filter(func(res *TaskResult) bool { return res.Executed() && !res.Succeeded() })
+}
+
+// Skipped returns the IDs of the tasks that weren't executed, either as they
+// weren't pending or as the run stopped.
+func (r *TaskRunReport) Skipped() []int {
+	return r.filter(func(res *TaskResult) bool { return !res.Executed() })
+}
+
+// Succeeded reports whether every task was executed and completed.
+func (r *TaskRunReport) Succeeded() bool {
+	for _, res := range r.Results {
+		if !res.Succeeded() {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *TaskRunReport) filter(keep func(*TaskResult) bool) []int {
+	var ids []int
+	for _, res := range r.Results {
+		if keep(res) {
+			ids = append(ids, res.TaskID)
+		}
+	}
+	return ids
+}
+
+// RunTasks executes the pending tasks in waves of policy.WaveSize, waiting
+// for each wave with WaitForTasks before executing the next. Tasks that
+// aren't pending are reported without being executed. The run stops once
+// policy.MaxFailureRatio is exceeded, optionally cancelling the tasks left.
+// Task failures are reported in the TaskRunReport; an error is only
+// returned, along with the report so far, if the tasks can't be fetched or
+// ctx is done.
+func (c CvpRestAPI) RunTasks(policy TaskRunPolicy, taskIDs ...int) (*TaskRunReport, error) {
+	return c.RunTasksCtx(context.Background(), policy, taskIDs...)
+}
+
+// RunTasksCtx is the context aware version of RunTasks.
+func (c CvpRestAPI) RunTasksCtx(ctx context.Context, policy TaskRunPolicy,
+	taskIDs ...int) (*TaskRunReport, error) {
+	report := &TaskRunReport{}
+	results := make(map[int]*TaskResult, len(taskIDs))
+	var groups []string
+	waiting := make(map[string][]int)
+
+	for _, id := range taskIDs {
+		if _, dup := results[id]; dup {
+			continue
+		}
+		task, err := c.GetTaskByIDCtx(ctx, id)
+		if err != nil {
+			return report, errors.Wrapf(err, "RunTasks: Task %d", id)
+		}
+		res := &TaskResult{TaskID: id, Task: task, Status: task.WorkOrderUserDefinedStatus}
+		if policy.GroupBy != nil {
+			res.Group = policy.GroupBy(task)
+		}
+		results[id] = res
+		report.Results = append(report.Results, res)
+		if res.Status != TaskPending {
+			continue
+		}
+		if _, found := waiting[res.Group]; !found {
+			groups = append(groups, res.Group)
+		}
+		waiting[res.Group] = append(waiting[res.Group], id)
+	}
+
+	var executed, failed int
+	for gi, group := range groups {
+		ids := waiting[group]
+		for len(ids) > 0 {
+			n := policy.WaveSize
+			if n <= 0 || n > len(ids) {
+				n = len(ids)
+			}
+			wave := ids[:n]
+			if err := ctx.Err(); err != nil {
+				return report, errors.Wrap(err, "RunTasks")
+			}
+
+			report.Waves++
+			if policy.OnWave != nil {
+				policy.OnWave(group, report.Waves, wave)
+			}
+			executed += len(wave)
+			failed += c.runWave(ctx, policy.Wait, report.Waves, wave, results)
+			ids = ids[n:]
+			if err := ctx.Err(); err != nil {
+				return report, errors.Wrap(err, "RunTasks")
+			}
+
+			if float64(failed)/float64(executed) > policy.MaxFailureRatio {
+				report.Stopped = true
+				if policy.CancelRemaining {
+					left := append([]int(nil), ids...)
+					for _, g := range groups[gi+1:] {
+						left = append(left, waiting[g]...)
+					}
+					c.cancelTasks(ctx, left, results)
+				}
+				return report, nil
+			}
+		}
+	}
+	return report, nil
+}
+
+// runWave executes the tasks and waits for them, returning how many didn't
+// complete
+func (c CvpRestAPI) runWave(ctx context.Context, policy TaskWaitPolicy, wave int, ids []int,
+	results map[int]*TaskResult) int {
+	for _, id := range ids {
+		results[id].Wave = wave
+	}
+	if err := c.ExecuteTasksCtx(ctx, ids); err != nil {
+		for _, id := range ids {
+			results[id].Err = err
+		}
+		return len(ids)
+	}
+
+	summary, err := c.WaitForTasksCtx(ctx, policy, ids...)
+	failed := 0
+	for _, id := range ids {
+		res := results[id]
+		if task := summary.Tasks[id]; task != nil {
+			res.Task, res.Status = task, task.WorkOrderUserDefinedStatus
+		}
+		res.Logs = summary.Logs[id]
+		if res.Status != TaskCompleted {
+			failed++
+			if err != nil {
+				res.Err = err
+			}
+		}
+	}
+	return failed
+}
+
+// cancelTasks cancels the tasks left when a run stops
+func (c CvpRestAPI) cancelTasks(ctx context.Context, ids []int, results map[int]*TaskResult) {
+	if len(ids) == 0 {
+		return
+	}
+	err := c.CancelTasksCtx(ctx, ids)
+	for _, id := range ids {
+		if err != nil {
+			results[id].Err = err
+			continue
+		}
+		results[id].Status = TaskCancelled
+	}
+}
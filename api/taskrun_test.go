@@ -0,0 +1,255 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+// RunMockClient simulates tasks that are pending until executed, then in
+// progress for one poll and then done with their final status
+type RunMockClient struct {
+	MockClient
+	final      map[int]string
+	status     map[int]string
+	containers map[int]string
+	execErr    map[int]bool
+	executed   [][]int
+	cancelled  []int
+}
+
+// NewRunMockClient creates a RunMockClient for pending tasks ending with the
+// final statuses
+func NewRunMockClient(final map[int]string) *RunMockClient {
+	status := make(map[int]string, len(final))
+	for id := range final {
+		status[id] = TaskPending
+	}
+	return &RunMockClient{final: final, status: status, containers: make(map[int]string),
+		execErr: make(map[int]bool)}
+}
+
+// Get satisfies the api ClientInterface for Get operation
+func (c *RunMockClient) Get(path string, params *url.Values) ([]byte, error) {
+	if path == "/task/getLogsById.do" {
+		return []byte(`{"total": 1, "data": [{"logDetails": "failed"}]}`), nil
+	}
+	id, _ := strconv.Atoi(params.Get("taskId"))
+	status, found := c.status[id]
+	if !found {
+		return nil, errors.New("Client error")
+	}
+	if status == TaskInProgress {
+		c.status[id] = c.final[id]
+	}
+	return json.Marshal(CvpTask{WorkOrderID: strconv.Itoa(id), WorkOrderUserDefinedStatus: status,
+		Data: WorkData{NewparentContainerID: c.containers[id]}})
+}
+
+// Post satisfies the api ClientInterface for Post operation
+func (c *RunMockClient) Post(path string, params *url.Values, data interface{}) ([]byte, error) {
+	var ids []int
+	for _, s := range data.(map[string][]string)["data"] {
+		id, _ := strconv.Atoi(s)
+		ids = append(ids, id)
+	}
+	switch path {
+	case "/workflow/executeTask.do":
+		c.executed = append(c.executed, ids)
+		for _, id := range ids {
+			if c.execErr[id] {
+				return nil, errors.New("Client error")
+			}
+		}
+		for _, id := range ids {
+			c.status[id] = TaskInProgress
+		}
+	case "/task/cancelTask.do":
+		c.cancelled = append(c.cancelled, ids...)
+	}
+	return []byte(`{}`), nil
+}
+
+func completedTasks(ids ...int) map[int]string {
+	final := make(map[int]string)
+	for _, id := range ids {
+		final[id] = TaskCompleted
+	}
+	return final
+}
+
+func fastTaskRunPolicy() TaskRunPolicy {
+	return TaskRunPolicy{Wait: fastTaskWaitPolicy()}
+}
+
+func Test_CvpRunTasksWaves_UnitTest(t *testing.T) {
+	client := NewRunMockClient(completedTasks(1, 2, 3, 4, 5))
+	api := NewCvpRestAPI(client)
+
+	var waves []string
+	policy := fastTaskRunPolicy()
+	policy.WaveSize = 2
+	policy.OnWave = func(group string, wave int, ids []int) {
+		waves = append(waves, fmt.Sprintf("%d:%v", wave, ids))
+	}
+	report, err := api.RunTasks(policy, 1, 2, 3, 4, 5, 2)
+	ok(t, err)
+	assert(t, report.Succeeded(), "Run didn't succeed")
+	assert(t, !report.Stopped, "Run stopped")
+	equals(t, [][]int{{1, 2}, {3, 4}, {5}}, client.executed)
+	equals(t, []string{"1:[1 2]", "2:[3 4]", "3:[5]"}, waves)
+	equals(t, 3, report.Waves)
+	equals(t, 5, len(report.Results))
+	equals(t, []int{1, 2, 3, 4, 5}, report.Executed())
+	equals(t, 0, len(report.Failed()))
+	equals(t, 3, report.Result(5).Wave)
+	equals(t, TaskCompleted, report.Result(5).Status)
+	assert(t, report.Result(6) == nil, "Result for a task not run")
+}
+
+func Test_CvpRunTasksGroups_UnitTest(t *testing.T) {
+	client := NewRunMockClient(completedTasks(1, 2, 3, 4))
+	api := NewCvpRestAPI(client)
+
+	policy := fastTaskRunPolicy()
+	policy.GroupBy = func(task *CvpTask) string {
+		id, _ := strconv.Atoi(task.WorkOrderID)
+		return []string{"even", "odd"}[id%2]
+	}
+	report, err := api.RunTasks(policy, 2, 1, 3, 4)
+	ok(t, err)
+	assert(t, report.Succeeded(), "Run didn't succeed")
+	// groups run in the order of their first task
+	equals(t, [][]int{{2, 4}, {1, 3}}, client.executed)
+	equals(t, "even", report.Result(2).Group)
+	equals(t, 2, report.Result(3).Wave)
+}
+
+func Test_CvpRunTasksGroupBy_UnitTest(t *testing.T) {
+	task := &CvpTask{
+		WorkOrderDetails: WorkOrderDetail{NetElementID: "00:1c:73:00:00:01"},
+		Data:             WorkData{CurrentparentContainerID: "container_1"},
+	}
+	equals(t, "container_1", GroupByContainer(task))
+	task.Data.NewparentContainerID = "container_2"
+	equals(t, "container_2", GroupByContainer(task))
+
+	groupBy := GroupByDevice(map[string]string{"00:1c:73:00:00:01": "spines"})
+	equals(t, "spines", groupBy(task))
+	task.WorkOrderDetails.NetElementID = "00:1c:73:00:00:02"
+	equals(t, "", groupBy(task))
+}
+
+func Test_CvpRunTasksFailureRatio_UnitTest(t *testing.T) {
+	final := completedTasks(1, 2, 3, 4, 5, 6)
+	final[2] = TaskFailed
+	client := NewRunMockClient(final)
+	api := NewCvpRestAPI(client)
+
+	policy := fastTaskRunPolicy()
+	policy.WaveSize = 2
+	policy.MaxFailureRatio = 0.3
+	policy.CancelRemaining = true
+	report, err := api.RunTasks(policy, 1, 2, 3, 4, 5, 6)
+	ok(t, err)
+	assert(t, report.Stopped, "Run should have stopped")
+	assert(t, !report.Succeeded(), "Stopped run succeeded")
+	equals(t, [][]int{{1, 2}}, client.executed)
+	equals(t, []int{2}, report.Failed())
+	equals(t, TaskFailed, report.Result(2).Status)
+	equals(t, "failed", report.Result(2).Logs[0].LogDetails)
+	equals(t, []int{3, 4, 5, 6}, report.Skipped())
+	equals(t, []int{3, 4, 5, 6}, client.cancelled)
+	equals(t, TaskCancelled, report.Result(6).Status)
+}
+
+func Test_CvpRunTasksNoFailureLimit_UnitTest(t *testing.T) {
+	final := completedTasks(1, 2, 3)
+	final[1] = TaskFailed
+	client := NewRunMockClient(final)
+	api := NewCvpRestAPI(client)
+
+	policy := fastTaskRunPolicy()
+	policy.WaveSize = 1
+	policy.MaxFailureRatio = 1
+	report, err := api.RunTasks(policy, 1, 2, 3)
+	ok(t, err)
+	assert(t, !report.Stopped, "Run stopped")
+	equals(t, []int{1, 2, 3}, report.Executed())
+	equals(t, []int{1}, report.Failed())
+	equals(t, 0, len(client.cancelled))
+}
+
+func Test_CvpRunTasksNotPending_UnitTest(t *testing.T) {
+	client := NewRunMockClient(completedTasks(1, 2))
+	client.status[2] = TaskCompleted
+	api := NewCvpRestAPI(client)
+
+	report, err := api.RunTasks(fastTaskRunPolicy(), 1, 2)
+	ok(t, err)
+	equals(t, [][]int{{1}}, client.executed)
+	equals(t, []int{2}, report.Skipped())
+	equals(t, TaskCompleted, report.Result(2).Status)
+	assert(t, !report.Succeeded(), "Run with skipped tasks succeeded")
+}
+
+func Test_CvpRunTasksExecuteError_UnitTest(t *testing.T) {
+	client := NewRunMockClient(completedTasks(1, 2, 3))
+	client.execErr[2] = true
+	api := NewCvpRestAPI(client)
+
+	policy := fastTaskRunPolicy()
+	policy.WaveSize = 2
+	report, err := api.RunTasks(policy, 1, 2, 3)
+	ok(t, err)
+	assert(t, report.Stopped, "Run should have stopped")
+	equals(t, []int{1, 2}, report.Failed())
+	equals(t, "ExecuteTask: Client error", report.Result(1).Err.Error())
+	equals(t, TaskPending, report.Result(1).Status)
+	equals(t, []int{3}, report.Skipped())
+}
+
+func Test_CvpRunTasksRetError_UnitTest(t *testing.T) {
+	client := NewRunMockClient(completedTasks(1))
+	api := NewCvpRestAPI(client)
+
+	report, err := api.RunTasks(fastTaskRunPolicy(), 1, 2)
+	assert(t, err != nil, "Error should be returned")
+	equals(t, "RunTasks: Task 2: GetTaskByID: Client error", err.Error())
+	equals(t, 0, len(client.executed))
+	equals(t, 1, len(report.Results))
+}
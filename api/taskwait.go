@@ -0,0 +1,252 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Statuses of a task, as reported in CvpTask.WorkOrderUserDefinedStatus
+const (
+	TaskPending    = "Pending"
+	TaskInProgress = "In-Progress"
+	TaskCompleted  = "Completed"
+	TaskFailed     = "Failed"
+	TaskCancelled  = "Cancelled"
+)
+
+// IsTaskDone reports whether status is a terminal task status, i.e.
+// Completed, Failed or Cancelled.
+func IsTaskDone(status string) bool {
+	switch status {
+	case TaskCompleted, TaskFailed, TaskCancelled:
+		return true
+	}
+	return false
+}
+
+// TaskChange is a status change of a task seen by WaitForTasks. From is
+// empty the first time the task is polled.
+type TaskChange struct {
+	TaskID int
+	From   string
+	To     string
+	Task   *CvpTask
+}
+
+// TaskWaitPolicy configures how WaitForTasks polls the tasks. Zero values
+// use the value of DefaultTaskWaitPolicy.
+type TaskWaitPolicy struct {
+	// Interval is the delay between polls.
+	Interval time.Duration
+	// MaxInterval caps the delay between polls as it grows by Multiplier.
+	MaxInterval time.Duration
+	// Multiplier is applied to the delay after every poll that didn't see
+	// a task change. Values below 1 keep the delay constant.
+	Multiplier float64
+	// Timeout bounds the wait in addition to the context. Zero waits until
+	// the tasks are done or the context is.
+	Timeout time.Duration
+	// OnChange, if set, is called for every status change of a task.
+	OnChange func(TaskChange)
+}
+
+// DefaultTaskWaitPolicy returns the TaskWaitPolicy used for zero values.
+func DefaultTaskWaitPolicy() TaskWaitPolicy {
+	return TaskWaitPolicy{
+		Interval:    3 * time.Second,
+		MaxInterval: 30 * time.Second,
+		Multiplier:  1.5,
+	}
+}
+
+// withDefaults returns the policy with its zero values defaulted
+func (p TaskWaitPolicy) withDefaults() TaskWaitPolicy {
+	def := DefaultTaskWaitPolicy()
+	if p.Interval <= 0 {
+		p.Interval = def.Interval
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = def.MaxInterval
+	}
+	if p.MaxInterval < p.Interval {
+		p.MaxInterval = p.Interval
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = def.Multiplier
+	}
+	return p
+}
+
+// TaskWaitSummary is the outcome of WaitForTasks. The ID lists keep the
+// order the tasks were given in.
+type TaskWaitSummary struct {
+	// Tasks holds the last state polled of every task, nil if it wasn't polled
+	Tasks     map[int]*CvpTask
+	Completed []int
+	Failed    []int
+	Cancelled []int
+	// Pending are the tasks that weren't done when waiting stopped
+	Pending []int
+	// Logs holds the logs of the failed tasks
+	Logs map[int][]LogData
+}
+
+// Succeeded reports whether every task completed.
+func (s *TaskWaitSummary) Succeeded() bool {
+	return len(s.Completed) == len(s.Tasks)
+}
+
+// WaitForTasks polls the tasks with GetTaskByID until they are all done,
+// calling policy.OnChange on every status change. The logs of the tasks that
+// failed are fetched with GetLogsByID. Tasks failing or being cancelled isn't
+// an error, see TaskWaitSummary.Succeeded. A task without a status, e.g. one
+// that doesn't exist, is an error, as it would never be done. Other statuses,
+// e.g. ones added by a newer CVP release, are polled like Pending until the
+// wait times out. If polling fails or times out the summary so far is
+// returned along with the error.
+func (c CvpRestAPI) WaitForTasks(policy TaskWaitPolicy,
+	taskIDs ...int) (*TaskWaitSummary, error) {
+	return c.WaitForTasksCtx(context.Background(), policy, taskIDs...)
+}
+
+// WaitForTasksCtx is the context aware version of WaitForTasks.
+func (c CvpRestAPI) WaitForTasksCtx(ctx context.Context, policy TaskWaitPolicy,
+	taskIDs ...int) (*TaskWaitSummary, error) {
+	policy = policy.withDefaults()
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	ids := make([]int, 0, len(taskIDs))
+	summary := &TaskWaitSummary{
+		Tasks: make(map[int]*CvpTask, len(taskIDs)),
+		Logs:  make(map[int][]LogData),
+	}
+	for _, id := range taskIDs {
+		if _, dup := summary.Tasks[id]; !dup {
+			summary.Tasks[id] = nil
+			ids = append(ids, id)
+		}
+	}
+
+	pending := ids
+	interval := policy.Interval
+	for len(pending) > 0 {
+		changed := false
+		remaining := pending[:0:0]
+		for _, id := range pending {
+			task, err := c.GetTaskByIDCtx(ctx, id)
+			if err != nil {
+				summary.finish(ids)
+				return summary, errors.Wrapf(err, "WaitForTasks: Task %d", id)
+			}
+			if task.WorkOrderUserDefinedStatus == "" {
+				summary.Tasks[id] = task
+				summary.finish(ids)
+				return summary, errors.Wrapf(ErrEntityDoesNotExist,
+					"WaitForTasks: Task %d has no status", id)
+			}
+			var from string
+			if last := summary.Tasks[id]; last != nil {
+				from = last.WorkOrderUserDefinedStatus
+			}
+			summary.Tasks[id] = task
+			if to := task.WorkOrderUserDefinedStatus; from != to {
+				changed = true
+				if policy.OnChange != nil {
+					policy.OnChange(TaskChange{TaskID: id, From: from, To: to, Task: task})
+				}
+			}
+			if !IsTaskDone(task.WorkOrderUserDefinedStatus) {
+				remaining = append(remaining, id)
+			}
+		}
+		pending = remaining
+		if len(pending) == 0 {
+			break
+		}
+
+		if changed {
+			interval = policy.Interval
+		} else if policy.Multiplier > 1 {
+			interval = time.Duration(float64(interval) * policy.Multiplier)
+			if interval > policy.MaxInterval {
+				interval = policy.MaxInterval
+			}
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			summary.finish(ids)
+			return summary, errors.Wrapf(ctx.Err(), "WaitForTasks: %d of %d tasks not done",
+				len(pending), len(ids))
+		case <-timer.C:
+		}
+	}
+	summary.finish(ids)
+
+	for _, id := range summary.Failed {
+		logs, err := c.GetLogsByIDCtx(ctx, id)
+		if err != nil {
+			return summary, errors.Wrapf(err, "WaitForTasks: Task %d", id)
+		}
+		summary.Logs[id] = logs
+	}
+	return summary, nil
+}
+
+// finish sorts the tasks, in the order of ids, by their last status
+func (s *TaskWaitSummary) finish(ids []int) {
+	for _, id := range ids {
+		var status string
+		if task := s.Tasks[id]; task != nil {
+			status = task.WorkOrderUserDefinedStatus
+		}
+		switch status {
+		case TaskCompleted:
+			s.Completed = append(s.Completed, id)
+		case TaskFailed:
+			s.Failed = append(s.Failed, id)
+		case TaskCancelled:
+			s.Cancelled = append(s.Cancelled, id)
+		default:
+			s.Pending = append(s.Pending, id)
+		}
+	}
+}
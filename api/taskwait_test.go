@@ -0,0 +1,215 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TaskMockClient answers GetTaskByID with the next status scripted for the
+// task, repeating the last one, and GetLogsByID with a log naming the task
+type TaskMockClient struct {
+	MockClient
+	statuses map[int][]string
+	polls    map[int]int
+}
+
+// NewTaskMockClient creates a TaskMockClient with the statuses of each task
+func NewTaskMockClient(statuses map[int][]string) *TaskMockClient {
+	return &TaskMockClient{statuses: statuses, polls: make(map[int]int)}
+}
+
+// Get satisfies the api ClientInterface for Get operation
+func (c *TaskMockClient) Get(path string, params *url.Values) ([]byte, error) {
+	if path == "/task/getLogsById.do" {
+		return []byte(fmt.Sprintf(`{"total": 1, "data": [{"logDetails": "task %s"}]}`,
+			params.Get("id"))), nil
+	}
+	id, _ := strconv.Atoi(params.Get("taskId"))
+	statuses, found := c.statuses[id]
+	if !found {
+		return nil, errors.New("Client error")
+	}
+	status := statuses[len(statuses)-1]
+	if n := c.polls[id]; n < len(statuses) {
+		status = statuses[n]
+	}
+	c.polls[id]++
+	return []byte(fmt.Sprintf(`{"workOrderId": "%d", "workOrderUserDefinedStatus": "%s"}`,
+		id, status)), nil
+}
+
+func fastTaskWaitPolicy() TaskWaitPolicy {
+	return TaskWaitPolicy{Interval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+}
+
+func Test_CvpWaitForTasks_UnitTest(t *testing.T) {
+	client := NewTaskMockClient(map[int][]string{
+		1: {TaskPending, TaskInProgress, TaskCompleted},
+		2: {TaskInProgress, TaskInProgress, TaskInProgress, TaskFailed},
+		3: {TaskCancelled},
+	})
+	api := NewCvpRestAPI(client)
+
+	var changes []string
+	policy := fastTaskWaitPolicy()
+	policy.OnChange = func(c TaskChange) {
+		equals(t, c.To, c.Task.WorkOrderUserDefinedStatus)
+		changes = append(changes, fmt.Sprintf("%d:%s->%s", c.TaskID, c.From, c.To))
+	}
+	summary, err := api.WaitForTasks(policy, 3, 1, 2, 1)
+	ok(t, err)
+	equals(t, []string{
+		"3:->Cancelled", "1:->Pending", "2:->In-Progress",
+		"1:Pending->In-Progress",
+		"1:In-Progress->Completed",
+		"2:In-Progress->Failed",
+	}, changes)
+	equals(t, []int{1}, summary.Completed)
+	equals(t, []int{2}, summary.Failed)
+	equals(t, []int{3}, summary.Cancelled)
+	equals(t, 0, len(summary.Pending))
+	equals(t, 3, len(summary.Tasks))
+	assert(t, !summary.Succeeded(), "Summary with failed tasks succeeded")
+
+	// logs are only fetched for failed tasks
+	equals(t, 1, len(summary.Logs))
+	equals(t, "task 2", summary.Logs[2][0].LogDetails)
+	// done tasks aren't polled again
+	equals(t, map[int]int{1: 3, 2: 4, 3: 1}, client.polls)
+}
+
+func Test_CvpWaitForTasksSucceeded_UnitTest(t *testing.T) {
+	client := NewTaskMockClient(map[int][]string{
+		1: {TaskCompleted},
+		2: {TaskPending, TaskCompleted},
+	})
+	api := NewCvpRestAPI(client)
+
+	summary, err := api.WaitForTasks(fastTaskWaitPolicy(), 1, 2)
+	ok(t, err)
+	assert(t, summary.Succeeded(), "Summary of completed tasks didn't succeed")
+	equals(t, []int{1, 2}, summary.Completed)
+	equals(t, 0, len(summary.Logs))
+}
+
+func Test_CvpWaitForTasksTimeout_UnitTest(t *testing.T) {
+	client := NewTaskMockClient(map[int][]string{
+		1: {TaskCompleted},
+		2: {TaskPending},
+	})
+	api := NewCvpRestAPI(client)
+
+	policy := fastTaskWaitPolicy()
+	policy.Timeout = 20 * time.Millisecond
+	summary, err := api.WaitForTasks(policy, 1, 2)
+	assert(t, errors.Is(err, context.DeadlineExceeded), "Unexpected error: %v", err)
+	equals(t, []int{1}, summary.Completed)
+	equals(t, []int{2}, summary.Pending)
+	equals(t, TaskPending, summary.Tasks[2].WorkOrderUserDefinedStatus)
+	assert(t, client.polls[2] > 1, "Task polled %d times", client.polls[2])
+}
+
+func Test_CvpWaitForTasksCanceled_UnitTest(t *testing.T) {
+	client := NewTaskMockClient(map[int][]string{1: {TaskPending}})
+	api := NewCvpRestAPI(client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := TaskWaitPolicy{Interval: time.Hour}
+	policy.OnChange = func(TaskChange) { cancel() }
+	summary, err := api.WaitForTasksCtx(ctx, policy, 1)
+	assert(t, errors.Is(err, context.Canceled), "Unexpected error: %v", err)
+	equals(t, []int{1}, summary.Pending)
+}
+
+func Test_CvpWaitForTasksRetError_UnitTest(t *testing.T) {
+	client := NewTaskMockClient(map[int][]string{1: {TaskPending, TaskCompleted}})
+	api := NewCvpRestAPI(client)
+
+	summary, err := api.WaitForTasks(fastTaskWaitPolicy(), 1, 2)
+	assert(t, err != nil, "Error should be returned")
+	equals(t, "WaitForTasks: Task 2: GetTaskByID: Client error", err.Error())
+	equals(t, []int{1, 2}, summary.Pending)
+	assert(t, summary.Tasks[2] == nil, "Task 2 should not have been polled")
+}
+
+func Test_CvpWaitForTasksUnknownStatus_UnitTest(t *testing.T) {
+	client := NewTaskMockClient(map[int][]string{
+		1: {TaskPending},
+		2: {""},
+	})
+	api := NewCvpRestAPI(client)
+
+	// without a timeout a task that is never done would be polled forever
+	summary, err := api.WaitForTasks(TaskWaitPolicy{Interval: time.Hour}, 1, 2)
+	assert(t, errors.Is(err, ErrEntityDoesNotExist), "Unexpected error: %v", err)
+	equals(t, "WaitForTasks: Task 2 has no status: entity does not exist", err.Error())
+	equals(t, []int{1, 2}, summary.Pending)
+	equals(t, map[int]int{1: 1, 2: 1}, client.polls)
+
+	// a status unknown to this release is polled until it's done or the
+	// wait times out
+	client.statuses[3] = []string{"Paused", "Paused", TaskCompleted}
+	summary, err = api.WaitForTasks(fastTaskWaitPolicy(), 3)
+	ok(t, err)
+	equals(t, []int{3}, summary.Completed)
+	equals(t, 3, client.polls[3])
+
+	client.statuses[4] = []string{"Paused"}
+	policy := fastTaskWaitPolicy()
+	policy.Timeout = 20 * time.Millisecond
+	summary, err = api.WaitForTasks(policy, 4)
+	assert(t, errors.Is(err, context.DeadlineExceeded), "Unexpected error: %v", err)
+	equals(t, []int{4}, summary.Pending)
+}
+
+func Test_CvpTaskWaitPolicyDefaults_UnitTest(t *testing.T) {
+	equals(t, DefaultTaskWaitPolicy().Interval, TaskWaitPolicy{}.withDefaults().Interval)
+	equals(t, DefaultTaskWaitPolicy().Multiplier, TaskWaitPolicy{}.withDefaults().Multiplier)
+
+	p := TaskWaitPolicy{Interval: time.Minute, Multiplier: 1}.withDefaults()
+	equals(t, time.Minute, p.MaxInterval)
+	equals(t, 1.0, p.Multiplier)
+
+	for _, status := range []string{TaskCompleted, TaskFailed, TaskCancelled} {
+		assert(t, IsTaskDone(status), "%s should be done", status)
+	}
+	for _, status := range []string{TaskPending, TaskInProgress, ""} {
+		assert(t, !IsTaskDone(status), "%s should not be done", status)
+	}
+}
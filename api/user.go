@@ -32,6 +32,7 @@
 package cvpapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
 	"strconv"
@@ -64,6 +65,11 @@ type SingleUser struct {
 
 // GetAllUsers returns all the existing users in CVP
 func (c CvpRestAPI) GetAllUsers(start, end int) (*UserList, error) {
+	return c.GetAllUsersCtx(context.Background(), start, end)
+}
+
+// GetAllUsersCtx is the context aware version of GetAllUsers.
+func (c CvpRestAPI) GetAllUsersCtx(ctx context.Context, start, end int) (*UserList, error) {
 	var users UserList
 
 	query := &url.Values{
@@ -72,9 +78,9 @@ func (c CvpRestAPI) GetAllUsers(start, end int) (*UserList, error) {
 		"endIndex":   {strconv.Itoa(end)},
 	}
 
-	resp, err := c.client.Get("/user/getUsers.do", query)
+	resp, err := c.get(ctx, "/user/getUsers.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetAllUsers: %s", err)
+		return nil, wrapError("GetAllUsers", "/user/getUsers.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &users); err != nil {
@@ -83,23 +89,28 @@ func (c CvpRestAPI) GetAllUsers(start, end int) (*UserList, error) {
 
 	if err := users.Error(); err != nil {
 		// Entity does not exist
-		if users.ErrorCode == "132801" {
+		if users.ErrorCode == ENTITY_DOES_NOT_EXIST {
 			return nil, nil
 		}
-		return nil, errors.Errorf("GetAllUsers: %s", err)
+		return nil, wrapError("GetAllUsers", "/user/getUsers.do", err)
 	}
 	return &users, nil
 }
 
 // GetUser returns the user with ID 'userID' and the roles associated with user
 func (c CvpRestAPI) GetUser(userID string) (*SingleUser, error) {
+	return c.GetUserCtx(context.Background(), userID)
+}
+
+// GetUserCtx is the context aware version of GetUser.
+func (c CvpRestAPI) GetUserCtx(ctx context.Context, userID string) (*SingleUser, error) {
 	var info SingleUser
 
 	query := &url.Values{"userId": {userID}}
 
-	resp, err := c.client.Get("/user/getUser.do", query)
+	resp, err := c.get(ctx, "/user/getUser.do", query)
 	if err != nil {
-		return nil, errors.Errorf("GetUser: %s", err)
+		return nil, wrapError("GetUser", "/user/getUser.do", err)
 	}
 
 	if err = json.Unmarshal(resp, &info); err != nil {
@@ -107,19 +118,24 @@ func (c CvpRestAPI) GetUser(userID string) (*SingleUser, error) {
 	}
 
 	if err := info.Error(); err != nil {
-		return nil, errors.Errorf("GetUser: %s", info.String())
+		return nil, wrapError("GetUser", "/user/getUser.do", err)
 	}
 	return &info, nil
 }
 
 // AddUser adds 'user' and returns error if any
 func (c CvpRestAPI) AddUser(user *SingleUser) error {
+	return c.AddUserCtx(context.Background(), user)
+}
+
+// AddUserCtx is the context aware version of AddUser.
+func (c CvpRestAPI) AddUserCtx(ctx context.Context, user *SingleUser) error {
 	if user == nil {
 		return errors.New("AddUser: can not add nil user")
 	}
-	resp, err := c.client.Post("/user/addUser.do", nil, user)
+	resp, err := c.post(ctx, "/user/addUser.do", nil, user)
 	if err != nil {
-		return errors.Errorf("AddUser: %s", err)
+		return wrapError("AddUser", "/user/addUser.do", err)
 	}
 	var addedUser *SingleUser
 	if err = json.Unmarshal(resp, &addedUser); err != nil {
@@ -129,9 +145,10 @@ func (c CvpRestAPI) AddUser(user *SingleUser) error {
 		var retErr error
 		if addedUser.ErrorCode == USER_ALREADY_EXISTS ||
 			addedUser.ErrorCode == DATA_ALREADY_EXISTS {
-			retErr = errors.Errorf("AddUser: user '%s' already exists", addedUser.UserData.UserID)
+			retErr = wrapErrorf("AddUser", "/user/addUser.do", err,
+				"AddUser: user '%s' already exists", addedUser.UserData.UserID)
 		} else {
-			retErr = errors.Errorf("AddUser: %s", addedUser.String())
+			retErr = wrapError("AddUser", "/user/addUser.do", err)
 		}
 		return retErr
 	}
@@ -140,12 +157,17 @@ func (c CvpRestAPI) AddUser(user *SingleUser) error {
 
 // DeleteUsers deletes specified users
 func (c CvpRestAPI) DeleteUsers(userIds []string) error {
+	return c.DeleteUsersCtx(context.Background(), userIds)
+}
+
+// DeleteUsersCtx is the context aware version of DeleteUsers.
+func (c CvpRestAPI) DeleteUsersCtx(ctx context.Context, userIds []string) error {
 	if len(userIds) == 0 {
 		return errors.New("DeleteUsers: no user specified for deletion")
 	}
-	resp, err := c.client.Post("/user/deleteUsers.do", nil, userIds)
+	resp, err := c.post(ctx, "/user/deleteUsers.do", nil, userIds)
 	if err != nil {
-		return errors.Errorf("DeleteUsers: %s", err)
+		return wrapError("DeleteUsers", "/user/deleteUsers.do", err)
 	}
 	var msg struct {
 		ResponseMessage string `json:"data"`
@@ -158,11 +180,14 @@ func (c CvpRestAPI) DeleteUsers(userIds []string) error {
 	if err = msg.Error(); err != nil {
 		switch msg.ErrorCode {
 		case SUPERUSER_DELETE_ATTEMPT:
-			retErr = errors.Errorf("DeleteUsers: cannot delete superuser '%s'", defaultUser)
+			retErr = wrapErrorf("DeleteUsers", "/user/deleteUsers.do", err,
+				"DeleteUsers: cannot delete superuser '%s'", defaultUser)
 		case INVALID_USER:
-			retErr = errors.Errorf("DeleteUsers: one of the users in %v does not exist", userIds)
+			retErr = wrapErrorf("DeleteUsers", "/user/deleteUsers.do", err,
+				"DeleteUsers: one of the users in %v does not exist", userIds)
 		default:
-			retErr = errors.Errorf("DeleteUsers: Unexpected error: %v", err)
+			retErr = wrapErrorf("DeleteUsers", "/user/deleteUsers.do", err,
+				"DeleteUsers: Unexpected error: %v", err)
 		}
 	} else {
 		lowerCaseResp := strings.ToLower(msg.ResponseMessage)
@@ -175,24 +200,30 @@ func (c CvpRestAPI) DeleteUsers(userIds []string) error {
 
 // UpdateUser updates 'user' having userObj
 func (c CvpRestAPI) UpdateUser(user string, userObj *SingleUser) error {
+	return c.UpdateUserCtx(context.Background(), user, userObj)
+}
+
+// UpdateUserCtx is the context aware version of UpdateUser.
+func (c CvpRestAPI) UpdateUserCtx(ctx context.Context, user string, userObj *SingleUser) error {
 	param := &url.Values{"userId": {user}}
-	resp, err := c.client.Post("/user/updateUser.do", param, userObj)
+	resp, err := c.post(ctx, "/user/updateUser.do", param, userObj)
 	if err != nil {
-		return errors.Errorf("UpdateUser: %v", err)
+		return wrapError("UpdateUser", "/user/updateUser.do", err)
 	}
 	var msg struct {
 		ResponseMessage string `json:"data"`
 		ErrorResponse
 	}
 	if err = json.Unmarshal(resp, &msg); err != nil {
-		return errors.Errorf("UpdateUsers: JSON unmarshal error: \n%v", err)
+		return errors.Errorf("UpdateUser: JSON unmarshal error: \n%v", err)
 	}
 	var retErr error
 	if err = msg.Error(); err != nil {
 		if msg.ErrorCode == SUPERUSER_EDIT_ATTEMPT {
-			retErr = errors.Errorf("UpdateUsers: can not edit super user '%s'", defaultUser)
+			retErr = wrapErrorf("UpdateUser", "/user/updateUser.do", err,
+				"UpdateUsers: can not edit super user '%s'", defaultUser)
 		} else {
-			retErr = errors.Errorf("UpdateUsers: %v", err)
+			retErr = wrapError("UpdateUser", "/user/updateUser.do", err)
 		}
 	} else {
 		lowerCaseResp := strings.ToLower(msg.ResponseMessage)
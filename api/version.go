@@ -0,0 +1,260 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Version is a CVP release such as 2020.2.3. The zero Version means the
+// release is unknown.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a CVP version string of the form major.minor[.patch].
+// Anything following the numeric components, such as a build suffix, is
+// ignored.
+func ParseVersion(s string) (Version, error) {
+	var v Version
+	fields := strings.SplitN(strings.TrimSpace(s), ".", 3)
+	if len(fields) < 2 {
+		return v, errors.Errorf("ParseVersion: Invalid CVP version '%s'", s)
+	}
+	parts := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, field := range fields {
+		// drop suffixes like 2020.2.0-beta or 2021.1.0.1
+		if end := strings.IndexFunc(field, notDigit); end >= 0 && i == len(fields)-1 {
+			field = field[:end]
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return Version{}, errors.Errorf("ParseVersion: Invalid CVP version '%s'", s)
+		}
+		*parts[i] = n
+	}
+	return v, nil
+}
+
+func notDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+// MustParseVersion is like ParseVersion but panics if s can't be parsed.
+func MustParseVersion(s string) Version {
+	v, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// IsZero reports whether v is the unknown version.
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+// Compare returns -1, 0 or 1 depending on whether v is older than, the same
+// as or newer than o.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+	return 0
+}
+
+// AtLeast reports whether v is the same as or newer than o.
+func (v Version) AtLeast(o Version) bool {
+	return v.Compare(o) >= 0
+}
+
+// ParsedVersion returns the CVP release reported in Version.
+func (i CvpInfo) ParsedVersion() (Version, error) {
+	return ParseVersion(i.Version)
+}
+
+// versionState holds the CVP release the API talks to. It is shared by the
+// copies of a CvpRestAPI.
+type versionState struct {
+	mu     sync.RWMutex
+	v      Version
+	detect bool // detect v before the first call depending on it
+	// detectMu serializes the detection requests
+	detectMu sync.Mutex
+}
+
+// Version returns the CVP release set by SetVersion or detected, or the zero
+// Version if it is unknown.
+func (c CvpRestAPI) Version() Version {
+	if c.version == nil {
+		return Version{}
+	}
+	c.version.mu.RLock()
+	defer c.version.mu.RUnlock()
+	return c.version.v
+}
+
+// SetVersion sets the CVP release used to pick the endpoints of the API
+// calls. The zero Version makes every call use its newest endpoint.
+func (c *CvpRestAPI) SetVersion(v Version) {
+	c.setVersion(v, false)
+}
+
+// SetVersionDetection enables or disables detecting the CVP release, using
+// GetCvpInfo, before the first call whose endpoint depends on it. That call
+// fails if GetCvpInfo does. Enabling it forgets the current release.
+func (c *CvpRestAPI) SetVersionDetection(enable bool) {
+	if enable {
+		c.setVersion(Version{}, true)
+		return
+	}
+	c.setVersion(c.Version(), false)
+}
+
+func (c *CvpRestAPI) setVersion(v Version, detect bool) {
+	if c.version == nil {
+		c.version = &versionState{}
+	}
+	c.version.mu.Lock()
+	defer c.version.mu.Unlock()
+	c.version.v, c.version.detect = v, detect
+}
+
+// DetectVersion gets the CVP release from GetCvpInfo and uses it to pick
+// the endpoints of the API calls.
+func (c *CvpRestAPI) DetectVersion() (Version, error) {
+	return c.DetectVersionCtx(context.Background())
+}
+
+// DetectVersionCtx is the context aware version of DetectVersion.
+func (c *CvpRestAPI) DetectVersionCtx(ctx context.Context) (Version, error) {
+	info, err := c.GetCvpInfoCtx(ctx)
+	if err != nil {
+		return Version{}, errors.Wrap(err, "DetectVersion")
+	}
+	v, err := info.ParsedVersion()
+	if err != nil {
+		return Version{}, errors.Wrap(err, "DetectVersion")
+	}
+	c.SetVersion(v)
+	return v, nil
+}
+
+// negotiatedVersion returns the CVP release, detecting it first if
+// SetVersionDetection is enabled. A GetCvpInfo error is returned and
+// detection is tried again by the next call.
+func (c CvpRestAPI) negotiatedVersion(ctx context.Context) (Version, error) {
+	s := c.version
+	if s == nil {
+		return Version{}, nil
+	}
+	s.mu.RLock()
+	v, detect := s.v, s.detect
+	s.mu.RUnlock()
+	if !detect {
+		return v, nil
+	}
+
+	s.detectMu.Lock()
+	defer s.detectMu.Unlock()
+	s.mu.RLock()
+	v, detect = s.v, s.detect
+	s.mu.RUnlock()
+	if !detect {
+		// detected while waiting
+		return v, nil
+	}
+	info, err := c.GetCvpInfoCtx(ctx)
+	if err != nil {
+		return Version{}, errors.Wrap(err, "detecting CVP version")
+	}
+	// a release that can't be parsed, e.g. on CVaaS, is left unknown
+	v, _ = info.ParsedVersion()
+	s.mu.Lock()
+	s.v, s.detect = v, false
+	s.mu.Unlock()
+	return v, nil
+}
+
+// endpoint is the path of an API on the CVP releases from since onwards
+type endpoint struct {
+	since Version
+	path  string
+}
+
+// endpoints lists the paths an API had over the CVP releases, newest first
+type endpoints []endpoint
+
+// resolve returns the newest path of op supported by the CVP release. The
+// newest path is used when the release is unknown.
+func (c CvpRestAPI) resolve(ctx context.Context, op string, eps endpoints) (string, error) {
+	v, err := c.negotiatedVersion(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+	for _, ep := range eps {
+		if v.IsZero() || v.AtLeast(ep.since) {
+			return ep.path, nil
+		}
+	}
+	return "", &VersionError{Op: op, Required: eps[len(eps)-1].since, Version: v}
+}
+
+// requires returns a *VersionError if the CVP release is known to be older
+// than min.
+func (c CvpRestAPI) requires(ctx context.Context, op string, min Version) error {
+	v, err := c.negotiatedVersion(ctx)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if !v.IsZero() && !v.AtLeast(min) {
+		return &VersionError{Op: op, Required: min, Version: v}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvpapi
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// PathMockClient answers each path with its own response and records the
+// paths requested
+type PathMockClient struct {
+	MockClient
+	responses map[string]string
+	paths     []string
+}
+
+// NewPathMockClient creates a PathMockClient answering the paths in responses
+func NewPathMockClient(responses map[string]string) *PathMockClient {
+	return &PathMockClient{responses: responses}
+}
+
+func (c *PathMockClient) respond(path string) ([]byte, error) {
+	c.paths = append(c.paths, path)
+	resp, found := c.responses[path]
+	if !found {
+		return nil, errors.New("Status [404]")
+	}
+	return []byte(resp), nil
+}
+
+// Get satisfies the api ClientInterface for Get operation
+func (c *PathMockClient) Get(path string, params *url.Values) ([]byte, error) {
+	return c.respond(path)
+}
+
+// Post satisfies the api ClientInterface for Post operation
+func (c *PathMockClient) Post(path string, params *url.Values, data interface{}) ([]byte, error) {
+	return c.respond(path)
+}
+
+func Test_ParseVersion_UnitTest(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp Version
+	}{
+		{"2018.2.5", Version{2018, 2, 5}},
+		{"2020.1", Version{2020, 1, 0}},
+		{" 2020.2.3 ", Version{2020, 2, 3}},
+		{"2021.1.0-beta2", Version{2021, 1, 0}},
+		{"2021.2.1.4", Version{2021, 2, 1}},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.in)
+		ok(t, err)
+		equals(t, tt.exp, v)
+	}
+	for _, in := range []string{"", "2020", "cvaas", "2020.x", "v2020.1.0", "2020..1"} {
+		_, err := ParseVersion(in)
+		assert(t, err != nil, "Parsed invalid version '%s'", in)
+	}
+	equals(t, "2020.2.0", MustParseVersion("2020.2").String())
+}
+
+func Test_VersionCompare_UnitTest(t *testing.T) {
+	v := MustParseVersion("2019.1.2")
+	equals(t, 0, v.Compare(MustParseVersion("2019.1.2")))
+	equals(t, 1, v.Compare(MustParseVersion("2018.2.5")))
+	equals(t, 1, v.Compare(MustParseVersion("2019.1.1")))
+	equals(t, -1, v.Compare(MustParseVersion("2019.2.0")))
+	equals(t, -1, v.Compare(MustParseVersion("2020.1.0")))
+	assert(t, v.AtLeast(MustParseVersion("2019.1.0")), "2019.1.2 < 2019.1.0")
+	assert(t, !v.AtLeast(MustParseVersion("2020.1.0")), "2019.1.2 >= 2020.1.0")
+	assert(t, Version{}.IsZero() && !v.IsZero(), "IsZero")
+}
+
+func Test_CvpVersionUnknownUsesNewest_UnitTest(t *testing.T) {
+	client := NewPathMockClient(map[string]string{
+		"/inventory/devices":      `[{"fqdn": "leaf1"}]`,
+		"/ztp/v2/saveTopology.do": `{"data": {"taskIds": ["1"]}}`,
+	})
+	api := NewCvpRestAPI(client)
+
+	devices, err := api.GetInventory()
+	ok(t, err)
+	equals(t, "leaf1", devices[0].Fqdn)
+	_, err = api.SaveTopology()
+	ok(t, err)
+	equals(t, []string{"/inventory/devices", "/ztp/v2/saveTopology.do"}, client.paths)
+}
+
+func Test_CvpVersionLegacyEndpoints_UnitTest(t *testing.T) {
+	client := NewPathMockClient(map[string]string{
+		"/inventory/getInventory.do": `{"total": 1, "netElementList": [{"fqdn": "leaf1"}]}`,
+		"/ztp/saveTopology.do":       `{"data": {"taskIds": ["1"]}}`,
+	})
+	api := NewCvpRestAPI(client)
+	api.SetVersion(MustParseVersion("2018.1.4"))
+
+	devices, err := api.GetInventory()
+	ok(t, err)
+	equals(t, 1, len(devices))
+	equals(t, "leaf1", devices[0].Fqdn)
+	_, err = api.SaveTopology()
+	ok(t, err)
+	equals(t, []string{"/inventory/getInventory.do", "/ztp/saveTopology.do"}, client.paths)
+}
+
+func Test_CvpVersionUnsupported_UnitTest(t *testing.T) {
+	client := NewPathMockClient(nil)
+	api := NewCvpRestAPI(client)
+	api.SetVersion(MustParseVersion("2018.1.4"))
+
+	_, err := api.SaveInventory()
+	assert(t, errors.Is(err, ErrUnsupportedVersion), "Unexpected error: %v", err)
+	equals(t, "SaveInventory: requires CVP >= 2018.2.0, connected to CVP 2018.1.4", err.Error())
+	var verErr *VersionError
+	assert(t, errors.As(err, &verErr), "Expected a *VersionError")
+	equals(t, MustParseVersion("2018.2.0"), verErr.Required)
+
+	_, err = api.GetNonConnectedDeviceCount()
+	assert(t, errors.Is(err, ErrUnsupportedVersion), "Unexpected error: %v", err)
+	// calls failing fast don't reach CVP
+	equals(t, 0, len(client.paths))
+}
+
+func Test_CvpVersionDetection_UnitTest(t *testing.T) {
+	client := NewPathMockClient(map[string]string{
+		"/cvpInfo/getCvpInfo.do":     `{"version": "2018.2.5"}`,
+		"/inventory/getInventory.do": `{"total": 0, "netElementList": []}`,
+	})
+	api := NewCvpRestAPI(client)
+	api.SetVersionDetection(true)
+	equals(t, Version{}, api.Version())
+
+	_, err := api.GetInventory()
+	ok(t, err)
+	_, err = api.GetInventory()
+	ok(t, err)
+	equals(t, MustParseVersion("2018.2.5"), api.Version())
+	// the release is only detected once
+	equals(t, []string{"/cvpInfo/getCvpInfo.do", "/inventory/getInventory.do",
+		"/inventory/getInventory.do"}, client.paths)
+
+	v, err := api.DetectVersion()
+	ok(t, err)
+	equals(t, MustParseVersion("2018.2.5"), v)
+}
+
+func Test_CvpVersionDetectionFailure_UnitTest(t *testing.T) {
+	client := NewPathMockClient(map[string]string{
+		"/inventory/devices": `[]`,
+	})
+	api := NewCvpRestAPI(client)
+	api.SetVersionDetection(true)
+
+	// the GetCvpInfo error is returned and detection is retried
+	_, err := api.GetInventory()
+	assert(t, err != nil, "Expected the detection error")
+	assert(t, strings.HasPrefix(err.Error(), "GetInventory: detecting CVP version: "),
+		"Unexpected error: %v", err)
+	_, err = api.GetInventory()
+	assert(t, err != nil, "Expected the detection error")
+	equals(t, []string{"/cvpInfo/getCvpInfo.do", "/cvpInfo/getCvpInfo.do"}, client.paths)
+
+	// an unparsable release, e.g. CVaaS, is only asked for once
+	client.responses["/cvpInfo/getCvpInfo.do"] = `{"version": "cvaas"}`
+	client.paths = nil
+	_, err = api.GetInventory()
+	ok(t, err)
+	_, err = api.GetInventory()
+	ok(t, err)
+	equals(t, []string{"/cvpInfo/getCvpInfo.do", "/inventory/devices",
+		"/inventory/devices"}, client.paths)
+	_, err = api.DetectVersion()
+	assert(t, err != nil, "Detected an invalid release")
+}
@@ -32,11 +32,14 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -44,6 +47,7 @@ import (
 	cvprac "github.com/aristanetworks/go-cvprac"
 	cvpapi "github.com/aristanetworks/go-cvprac/api"
 
+	"io/ioutil"
 	"net/http"
 
 	resty "gopkg.in/resty.v1"
@@ -62,35 +66,57 @@ var (
 	DefaultProtocol = "https"
 	// DefaultHosts set to local host ip
 	DefaultHosts = []string{"127.0.0.1"}
-	// NumRetryRequests specifies the number for retries to attempt
+	// NumRetryRequests specifies the number of attempts per node when the
+	// RetryPolicy doesn't set MaxAttempts
 	NumRetryRequests = 3
 )
 
 // UNDEFPORT undefined port
 const UNDEFPORT = -1
 
-type authInfo struct {
-	Username string
-	Password string
-}
-
-// CvpClient represents a CVP client api connection
+// CvpClient represents a CVP client api connection. A CvpClient is safe for
+// concurrent use once connected; the exported session fields (Client,
+// SessID, HostPool) must not be modified while requests are in flight.
 type CvpClient struct {
 	cvpapi.ClientInterface
-	Hosts     []string
+	Hosts []string
+	// HostPool is no longer used to pick nodes, see HostSelector
 	HostPool  *HostIterator
 	Port      int
 	Protocol  string
-	authInfo  *authInfo
-	Timeout   time.Duration
-	Transport http.RoundTripper
-	Client    *resty.Client
-	SessID    string
-	url       string
-	API       *cvpapi.CvpRestAPI
-	Debug     bool
-	IsCvaas   bool
-	Tenant    string
+	creds     CredentialProvider
+	tokenAuth bool
+	// mu guards the session state (Client, SessID, url, host, HostPool and
+	// tokenAuth). gen is bumped every time the session is replaced so that
+	// concurrent requests failing on the same session only refresh it once.
+	mu         sync.RWMutex
+	gen        uint64
+	Timeout    time.Duration
+	Transport  http.RoundTripper
+	Client     *resty.Client
+	SessID     string
+	url        string
+	API        *cvpapi.CvpRestAPI
+	Debug      bool
+	IsCvaas    bool
+	Tenant     string
+	tlsOpts    tlsOptions
+	retry      RetryPolicy
+	selector   HostSelector
+	middleware middlewareChain
+	logger     Logger
+	cache      *sessionCache
+	host       string // node of the logged in session
+	// sessions holds the logged in session of every node, for Close
+	sessions map[string]*resty.Client
+	closed   bool
+	limiters map[EndpointClass]*limiter
+	classify ClassifierFunc
+	version  cvpapi.Version // set with CvpVersion
+	netOpts  netOptions
+	// roundTripper is the transport shared by the sessions, built from
+	// Transport and the TLS, proxy and dialer options
+	roundTripper http.RoundTripper
 }
 
 // Option is a Client Option...function that sets a value and returns
@@ -134,7 +160,7 @@ func Protocol(proto string) Option {
 			return errors.Errorf("Invalid protocol [%s]", proto)
 		}
 		c.Protocol = proto
-		return nil
+		return c.applyTransport()
 	}
 }
 
@@ -152,15 +178,155 @@ func ConnectTimeout(timeout int) Option {
 	}
 }
 
-// Transport sets the connection Transport for this Client
+// Transport sets the connection Transport for this Client. An
+// *http.Transport is copied before the TLS, proxy and dialer options are
+// applied; any other RoundTripper is used as is and handles TLS itself, so
+// those options fail with it.
 func Transport(transport http.RoundTripper) Option {
 	return func(c *CvpClient) error {
 		if transport != nil {
 			c.Transport = transport
-			if c.Client != nil {
-				c.Client.SetTransport(transport)
+			return c.applyTransport()
+		}
+		return nil
+	}
+}
+
+// InsecureSkipVerify disables verification of the CVP server certificate
+// chain and host name. Verification is enabled unless explicitly disabled.
+func InsecureSkipVerify(enable bool) Option {
+	return func(c *CvpClient) error {
+		c.tlsOpts.insecure = enable
+		return c.applyTransport()
+	}
+}
+
+// RootCAs sets the certificate pool used to verify the CVP server
+// certificate. The system pool is used if not set.
+func RootCAs(pool *x509.CertPool) Option {
+	return func(c *CvpClient) error {
+		if pool == nil {
+			return errors.New("RootCAs: nil certificate pool")
+		}
+		c.tlsOpts.rootCAs = pool
+		return c.applyTransport()
+	}
+}
+
+// RootCAFile sets the PEM encoded CA bundle used to verify the CVP server
+// certificate.
+func RootCAFile(path string) Option {
+	return func(c *CvpClient) error {
+		pool, err := loadCertPool(path)
+		if err != nil {
+			return errors.Wrap(err, "RootCAFile")
+		}
+		c.tlsOpts.rootCAs = pool
+		return c.applyTransport()
+	}
+}
+
+// ServerName sets the name used for SNI and for verifying the CVP server
+// certificate, for when it differs from the host being connected to.
+func ServerName(name string) Option {
+	return func(c *CvpClient) error {
+		c.tlsOpts.serverName = name
+		return c.applyTransport()
+	}
+}
+
+// ClientCertificate sets the certificate presented to CVP during the TLS
+// handshake.
+func ClientCertificate(cert tls.Certificate) Option {
+	return func(c *CvpClient) error {
+		c.tlsOpts.certs = []tls.Certificate{cert}
+		return c.applyTransport()
+	}
+}
+
+// ClientCertificateFile loads the PEM encoded certificate/key pair presented
+// to CVP during the TLS handshake.
+func ClientCertificateFile(certFile string, keyFile string) Option {
+	return func(c *CvpClient) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return errors.Wrap(err, "ClientCertificateFile")
+		}
+		c.tlsOpts.certs = []tls.Certificate{cert}
+		return c.applyTransport()
+	}
+}
+
+// PinCertificate only accepts CVP server certificates with the provided
+// SHA-256 fingerprint (hex, optionally colon separated). It may be given
+// multiple times to accept several certificates. Pinning is enforced in
+// addition to chain verification unless InsecureSkipVerify is enabled.
+func PinCertificate(fingerprint string) Option {
+	return func(c *CvpClient) error {
+		fp, err := parseFingerprint(fingerprint)
+		if err != nil {
+			return errors.Wrap(err, "PinCertificate")
+		}
+		c.tlsOpts.fingerprints = append(c.tlsOpts.fingerprints, fp)
+		return c.applyTransport()
+	}
+}
+
+// HostSelection sets the strategy used to pick the CVP node when a session
+// is created or fails over. See RoundRobin and HealthSelector.
+func HostSelection(selector HostSelector) Option {
+	return func(c *CvpClient) error {
+		if selector == nil {
+			return errors.New("HostSelection: nil selector")
+		}
+		// the selector replaced no longer probes with this client
+		if ps, ok := c.selector.(probingSelector); ok {
+			ps.removeProbe(c)
+		}
+		if ps, ok := selector.(probingSelector); ok {
+			ps.addProbe(c, c.probeHost)
+		}
+		c.selector = selector
+		return nil
+	}
+}
+
+// Middlewares adds middleware called around every request. See Middleware.
+func Middlewares(middleware ...Middleware) Option {
+	return func(c *CvpClient) error {
+		for _, mw := range middleware {
+			if mw == nil {
+				return errors.New("Middlewares: nil middleware")
 			}
 		}
+		c.middleware = append(c.middleware[:len(c.middleware):len(c.middleware)],
+			middleware...)
+		return nil
+	}
+}
+
+// Logging sets a structured logger for the requests, logins and failovers
+// of this Client. Passwords, session IDs and tokens are redacted. With a
+// logger set, Debug logs the request and response bodies to it instead of
+// enabling the resty debug output.
+func Logging(logger Logger) Option {
+	return func(c *CvpClient) error {
+		c.logger = logger
+		if c.Client != nil {
+			c.Client.SetDebug(c.restyDebug())
+		}
+		return nil
+	}
+}
+
+// Retry sets the policy used to retry failed requests. See RetryPolicy.
+func Retry(policy RetryPolicy) Option {
+	return func(c *CvpClient) error {
+		if err := policy.validate(); err != nil {
+			return errors.Wrap(err, "Retry")
+		}
+		policy.RetryableStatus = append([]int(nil), policy.RetryableStatus...)
+		c.retry = policy
 		return nil
 	}
 }
@@ -170,7 +336,7 @@ func Debug(enable bool) Option {
 	return func(c *CvpClient) error {
 		c.Debug = enable
 		if c.Client != nil {
-			c.Client.SetDebug(c.Debug)
+			c.Client.SetDebug(c.restyDebug())
 		}
 		return nil
 	}
@@ -187,6 +353,8 @@ func Cvaas(enable bool, tenant string) Option {
 
 // SetOption takes one or more option function and applies them in order
 func (c *CvpClient) SetOption(options ...Option) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, opt := range options {
 		if err := opt(c); err != nil {
 			return err
@@ -223,6 +391,57 @@ func (c *CvpClient) SetTransport(transport http.RoundTripper) error {
 	return c.SetOption(Transport(transport))
 }
 
+// SetInsecureSkipVerify enables or disables verification of the CVP
+// server certificate.
+func (c *CvpClient) SetInsecureSkipVerify(enable bool) error {
+	return c.SetOption(InsecureSkipVerify(enable))
+}
+
+// SetRootCAs sets the certificate pool used to verify the CVP server.
+func (c *CvpClient) SetRootCAs(pool *x509.CertPool) error {
+	return c.SetOption(RootCAs(pool))
+}
+
+// SetServerName sets the name used for SNI and server verification.
+func (c *CvpClient) SetServerName(name string) error {
+	return c.SetOption(ServerName(name))
+}
+
+// SetClientCertificate sets the client certificate presented to CVP.
+func (c *CvpClient) SetClientCertificate(cert tls.Certificate) error {
+	return c.SetOption(ClientCertificate(cert))
+}
+
+// SetHostSelector sets the strategy used to pick the CVP node
+func (c *CvpClient) SetHostSelector(selector HostSelector) error {
+	return c.SetOption(HostSelection(selector))
+}
+
+// Use adds middleware called around every request
+func (c *CvpClient) Use(middleware ...Middleware) error {
+	return c.SetOption(Middlewares(middleware...))
+}
+
+// SetLogger sets a structured logger for this Client, nil disables logging
+func (c *CvpClient) SetLogger(logger Logger) error {
+	return c.SetOption(Logging(logger))
+}
+
+// SetSessionCache enables saving and resuming the session, see SessionCache
+func (c *CvpClient) SetSessionCache(path string, ttl time.Duration) error {
+	return c.SetOption(SessionCache(path, ttl))
+}
+
+// SetRateLimit limits the requests of class, see RateLimit
+func (c *CvpClient) SetRateLimit(class EndpointClass, limit Limit) error {
+	return c.SetOption(RateLimit(class, limit))
+}
+
+// SetRetryPolicy sets the policy used to retry failed requests
+func (c *CvpClient) SetRetryPolicy(policy RetryPolicy) error {
+	return c.SetOption(Retry(policy))
+}
+
 // SetDebug enables or disables debugging.
 func (c *CvpClient) SetDebug(enable bool) error {
 	return c.SetOption(Debug(enable))
@@ -240,6 +459,8 @@ func NewCvpClient(options ...Option) (*CvpClient, error) {
 		Protocol: DefaultProtocol,
 		Timeout:  DefaultTimeOut,
 		Hosts:    DefaultHosts,
+		retry:    DefaultRetryPolicy(),
+		selector: RoundRobin(),
 	}
 
 	// Parse Options
@@ -250,6 +471,7 @@ func NewCvpClient(options ...Option) (*CvpClient, error) {
 	c.initSession(c.Hosts[0])
 
 	c.API = cvpapi.NewCvpRestAPI(c)
+	c.API.SetVersion(c.version)
 
 	return c, nil
 }
@@ -268,33 +490,152 @@ func (c *CvpClient) GetPort() int {
 
 // GetSessionID returns the current Session ID
 func (c *CvpClient) GetSessionID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.SessID
 }
 
 // Connect Login to CVP and get a session ID and cookie.
 func (c *CvpClient) Connect(username string, password string) error {
-	c.authInfo = &authInfo{username, password}
+	return c.ConnectCtx(context.Background(), username, password)
+}
+
+// ConnectCtx Login to CVP and get a session ID and cookie. The login attempts
+// across all nodes are abandoned once ctx is done.
+func (c *CvpClient) ConnectCtx(ctx context.Context, username string, password string) error {
+	return c.ConnectWithCredentialsCtx(ctx, StaticCredentials(username, password))
+}
+
+// ConnectWithToken authenticates to CVP using a service account token. The
+// token is sent as a bearer token (and access_token cookie) on every request
+// instead of logging in.
+func (c *CvpClient) ConnectWithToken(token string) error {
+	return c.ConnectWithTokenCtx(context.Background(), token)
+}
+
+// ConnectWithTokenCtx authenticates to CVP using a service account token.
+func (c *CvpClient) ConnectWithTokenCtx(ctx context.Context, token string) error {
+	if token == "" {
+		return errors.New("ConnectWithToken: Empty token")
+	}
+	return c.ConnectWithCredentialsCtx(ctx, StaticToken(token))
+}
+
+// ConnectWithCredentials authenticates to CVP using the credentials returned
+// by provider. The provider is called again whenever the client has to log
+// in, e.g. after a session expires or when failing over to another node.
+func (c *CvpClient) ConnectWithCredentials(provider CredentialProvider) error {
+	return c.ConnectWithCredentialsCtx(context.Background(), provider)
+}
+
+// ConnectWithCredentialsCtx authenticates to CVP using the credentials
+// returned by provider. Once connected the CVP release is detected when
+// first needed, unless set with CvpVersion.
+func (c *CvpClient) ConnectWithCredentialsCtx(ctx context.Context,
+	provider CredentialProvider) error {
+	if provider == nil {
+		return errors.New("ConnectWithCredentials: nil CredentialProvider")
+	}
+	if err := c.connect(ctx, provider); err != nil {
+		return err
+	}
+	c.negotiateVersion()
+	return nil
+}
+
+// connect sets the credentials and creates or resumes a session
+func (c *CvpClient) connect(ctx context.Context, provider CredentialProvider) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errors.Wrap(ErrClientClosed, "ConnectWithCredentials")
+	}
+	c.creds = provider
+
+	if c.cache != nil {
+		err := c.resumeSession(ctx)
+		if err == nil {
+			c.logInfo("CVP session resumed", "node", c.host)
+			return nil
+		}
+		c.logInfo("Unable to resume cached CVP session", "error", err)
+	}
+	return c.createSession(ctx, true)
+}
+
+// session returns the current session, its generation and node
+func (c *CvpClient) session() (*resty.Client, uint64, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Client, c.gen, c.host
+}
+
+// usingToken reports whether the current session uses token authentication
+func (c *CvpClient) usingToken() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tokenAuth
+}
+
+// relogin logs in again to the current node, unless the session of
+// generation gen has already been replaced by another request in which
+// case that session is reused.
+func (c *CvpClient) relogin(ctx context.Context, gen uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	if c.gen != gen {
+		return nil
+	}
+	c.logInfo("CVP session expired, logging in again", "node", c.host)
+	return c.resetSession(ctx)
+}
 
-	return c.createSession(true)
+// failover creates a session to another node, unless the session of
+// generation gen has already been replaced by another request in which
+// case that session is reused.
+func (c *CvpClient) failover(ctx context.Context, gen uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	if c.gen != gen {
+		return nil
+	}
+	c.logWarn("Failing over to another CVP node", "node", c.host)
+	return c.createSession(ctx, false)
 }
 
-func (c *CvpClient) createSession(allNodes bool) error {
+func (c *CvpClient) createSession(ctx context.Context, allNodes bool) error {
 	var errorMsg []string
 
-	numNodes := len(c.Hosts)
-	if !allNodes && numNodes > 1 {
-		numNodes--
+	hosts := c.selector.Select(c.Hosts, c.host)
+	if !allNodes && len(hosts) > 1 {
+		// failing over, so skip the current node
+		hosts = removeHost(hosts, c.host)
 	}
-	for nodeIter := 0; nodeIter < numNodes; nodeIter++ {
-		host := c.HostPool.Cycle()
+	for _, host := range hosts {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "createSession")
+		}
 
 		c.initSession(host)
 
-		if err := c.login(); err != nil {
+		if err := c.login(ctx); err != nil {
+			c.logWarn("CVP login failed", "node", host, "error", err)
+			c.selector.Report(host, err)
 			tmpMsg := fmt.Sprintf("createSession: Error: %s", err.Error())
 			errorMsg = append(errorMsg, tmpMsg)
 			continue
 		}
+		c.selector.Report(host, nil)
+		c.host = host
+		c.trackSession()
+		c.logInfo("CVP session created", "node", host)
+		c.cacheSession(ctx)
 		return nil
 	}
 	return errors.New(strings.Join(errorMsg, "\n"))
@@ -305,50 +646,121 @@ func (c *CvpClient) initSession(host string) error {
 		return errors.Errorf("initSession: No host provided")
 	}
 
-	c.url = fmt.Sprintf("%s://%s:%d", c.Protocol, host, c.GetPort())
-	if !c.IsCvaas {
-		c.url = c.url + "/web"
-	}
+	c.url = c.hostURL(host)
 
 	c.Client = resty.New()
+	c.gen++
 
-	// Make sure to set transport before SetTLSClientConfig()
-	// If Transport is nil, SetTransport() creates a default.
-	c.Client.SetTransport(c.Transport)
-
-	if c.Protocol == "https" {
-		c.Client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	if c.roundTripper == nil {
+		c.roundTripper = c.newTransport()
 	}
+	c.Client.SetTransport(c.roundTripper)
 	c.Client.SetHostURL(c.url)
 	c.Client.SetHeaders(headers)
 	c.Client.SetTimeout(c.Timeout)
-	c.Client.SetDebug(c.Debug)
+	c.Client.SetDebug(c.restyDebug())
 	return nil
 }
 
-func (c *CvpClient) resetSession() error {
+// removeHost returns hosts without the first occurrence of host, or
+// without the last entry if host isn't found
+func removeHost(hosts []string, host string) []string {
+	for i, h := range hosts {
+		if h == host {
+			return append(hosts[:i:i], hosts[i+1:]...)
+		}
+	}
+	return hosts[:len(hosts)-1]
+}
+
+// restyDebug reports whether to enable the resty debug output, which is
+// replaced by the logger when one is set
+func (c *CvpClient) restyDebug() bool {
+	return c.Debug && c.logger == nil
+}
+
+// hostURL returns the base URL of the API on host
+func (c *CvpClient) hostURL(host string) string {
+	url := fmt.Sprintf("%s://%s:%d", c.Protocol, host, c.GetPort())
+	if !c.IsCvaas {
+		url = url + "/web"
+	}
+	return url
+}
+
+// probeHost checks that the API on host responds using a separate
+// connection with the client's settings
+func (c *CvpClient) probeHost(ctx context.Context, host string) error {
+	c.mu.RLock()
+	client := resty.New()
+	if c.roundTripper != nil {
+		client.SetTransport(c.roundTripper)
+	} else {
+		client.SetTransport(c.newTransport())
+	}
+	client.SetHostURL(c.hostURL(host))
+	client.SetTimeout(c.Timeout)
+	c.mu.RUnlock()
+
+	url := "/cvpInfo/getCvpInfo.do"
+	resp, err := client.R().SetContext(ctx).Get(url)
+	if err != nil {
+		return errors.Wrap(err, "probeHost")
+	}
+	// any response short of a server error means the API is up
+	if resp.StatusCode() >= http.StatusInternalServerError {
+		return statusError(resp, resp.Body(), url)
+	}
+	return nil
+}
+
+// reportFailure marks the node of the session of generation gen as failed
+func (c *CvpClient) reportFailure(gen uint64, err error) {
+	c.mu.RLock()
+	host, current := c.host, c.gen == gen
+	c.mu.RUnlock()
+	if current {
+		c.selector.Report(host, err)
+	}
+}
+
+func (c *CvpClient) resetSession(ctx context.Context) error {
 	// reset session to the current host we are connected to
-	if err := c.initSession(c.HostPool.Value()); err != nil {
+	if err := c.initSession(c.host); err != nil {
 		return errors.Wrap(err, "resetSession")
 	}
 
-	if err := c.login(); err != nil {
+	if err := c.login(ctx); err != nil {
 		return errors.Wrap(err, "resetSession")
 	}
+	c.trackSession()
+	c.cacheSession(ctx)
 	return nil
 }
 
-func (c *CvpClient) login() error {
+func (c *CvpClient) login(ctx context.Context) error {
+	if c.creds == nil {
+		return errors.New("login: No credentials")
+	}
+	creds, err := c.creds.Credentials(ctx)
+	if err != nil {
+		return errors.Wrap(err, "login")
+	}
+
+	c.tokenAuth = creds.Token != ""
+	if c.tokenAuth {
+		return c.loginToken(creds)
+	}
 	if c.IsCvaas {
-		return c.loginCvaas()
+		return c.loginCvaas(ctx, creds)
 	}
-	return c.loginOnPrem()
+	return c.loginOnPrem(ctx, creds)
 }
 
-func (c *CvpClient) loginCvaas() error {
-	request := c.Client.R()
-	auth := `{"org":"` + c.Tenant + `", "name":"` + c.authInfo.Username +
-		`", "password":"` + c.authInfo.Password + `"}`
+func (c *CvpClient) loginCvaas(ctx context.Context, creds *Credentials) error {
+	request := c.Client.R().SetContext(ctx)
+	auth := `{"org":"` + c.Tenant + `", "name":"` + creds.Username +
+		`", "password":"` + creds.Password + `"}`
 	resp, err := request.SetBody(auth).Post("/api/v1/oauth?provider=local&next=false")
 	if err != nil {
 		return errors.Wrap(err, "login")
@@ -362,14 +774,23 @@ func (c *CvpClient) loginCvaas() error {
 	return nil
 }
 
-func (c *CvpClient) loginOnPrem() error {
+// loginToken sets up the session to authenticate using the service account
+// token. No login request is made.
+func (c *CvpClient) loginToken(creds *Credentials) error {
+	c.SessID = ""
+	c.Client.SetAuthToken(creds.Token)
+	c.Client.SetCookie(&http.Cookie{Name: "access_token", Value: creds.Token})
+	return nil
+}
+
+func (c *CvpClient) loginOnPrem(ctx context.Context, creds *Credentials) error {
 	var loginResp cvpapi.LoginResp
 
 	c.SessID = ""
-	request := c.Client.R()
+	request := c.Client.R().SetContext(ctx)
 
-	auth := "{\"userId\":\"" + c.authInfo.Username +
-		"\", \"password\":\"" + c.authInfo.Password + "\"}"
+	auth := "{\"userId\":\"" + creds.Username +
+		"\", \"password\":\"" + creds.Password + "\"}"
 
 	resp, err := request.SetBody(auth).Post("/login/authenticate.do")
 	if err != nil {
@@ -390,30 +811,75 @@ func (c *CvpClient) loginOnPrem() error {
 	return nil
 }
 
-func (c *CvpClient) makeRequest(reqType string, url string, params *url.Values,
-	data interface{}) ([]byte, error) {
-	var err error
-	var resp *resty.Response
-	var formattedParams map[string]string
+func (c *CvpClient) makeRequest(ctx context.Context, reqType string, url string,
+	params *url.Values, data interface{}) ([]byte, error) {
+	req := &Request{Method: reqType, Path: url, Query: cloneValues(params), Body: data}
+	resp, err := c.execute(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body(), nil
+}
 
-	if c.Client == nil {
-		return nil, errors.Errorf("makeRequest: No valid session to CVP [%s]", c.url)
+// execute makes req through the middleware. With stream set the body of a
+// successful response is left unread in RawBody.
+func (c *CvpClient) execute(ctx context.Context, req *Request,
+	stream bool) (*resty.Response, error) {
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return nil, errors.Wrap(ErrClientClosed, "makeRequest")
+	}
+	middleware := c.middleware
+	if c.logger != nil {
+		// log last so the final request is logged
+		middleware = append(middleware[:len(middleware):len(middleware)],
+			&logMiddleware{logger: c.logger, debug: c.Debug})
 	}
+	c.mu.RUnlock()
 
-	retryCnt := NumRetryRequests
+	call := &CallInfo{Context: ctx, Method: req.Method, Path: req.Path}
+	if err := middleware.beforeCall(call); err != nil {
+		return nil, errors.Wrap(err, "makeRequest")
+	}
+	start := time.Now()
+	resp, err := c.doRequest(call, req, stream, middleware)
+	call.Duration = time.Since(start)
+	call.Err = err
+	middleware.afterCall(call)
+	return resp, err
+}
 
-	if params != nil {
-		formattedParams, err = parseURLValues(params)
-		if err != nil {
-			return nil, err
-		}
+// doRequest makes the request for call, retrying, logging in again and
+// failing over to other nodes as needed.
+func (c *CvpClient) doRequest(call *CallInfo, req *Request, stream bool,
+	middleware middlewareChain) (*resty.Response, error) {
+	var err error
+	var resp *resty.Response
+
+	client, gen, host := c.session()
+	if client == nil {
+		return nil, errors.New("makeRequest: No valid session to CVP")
 	}
 
+	ctx, reqType, url := call.Context, call.Method, call.Path
+
+	c.mu.RLock()
+	policy := c.retry
 	nodeCnt := len(c.Hosts)
-	for nodeCnt > 0 {
+	c.mu.RUnlock()
+	maxAttempts := policy.maxAttempts()
+	// POSTs and PATCHes may already have been acted on by CVP so are only
+	// replayed when the policy allows it
+	replayable := idempotent(reqType) || policy.RetryNonIdempotent
+	attempt := 0
+	reason := AttemptFirst
 
-		request := c.Client.R()
-		request.SetQueryParams(formattedParams)
+	for nodeCnt > 0 {
+		// Stop retrying/failing over as soon as the caller gives up
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, errors.Wrap(ctxErr, "makeRequest")
+		}
 
 		// If we've seen an error
 		if err != nil {
@@ -425,34 +891,92 @@ func (c *CvpClient) makeRequest(reqType string, url string, params *url.Values,
 			}
 			// Not the first time through the loop. Retrying request so
 			// create a session to another CVP node...but exclude this one.
-			if err := c.createSession(false); err != nil {
+			if err := c.failover(ctx, gen); err != nil {
 				return nil, err
 			}
-			retryCnt = NumRetryRequests
+			client, gen, host = c.session()
+			attempt = 0
+			reason = AttemptFailover
+		}
+		attempt++
+		call.Attempts++
+
+		release, limitErr := c.acquireLimit(ctx, reqType, url)
+		if limitErr != nil {
+			return nil, errors.Wrap(limitErr, "makeRequest")
+		}
+
+		info := &RequestInfo{
+			Context: ctx,
+			Method:  reqType,
+			Path:    url,
+			Query:   cloneValues(&req.Query),
+			Header:  cloneHeader(req.Header),
+			Body:    req.Body,
+			Host:    host,
+			Attempt: call.Attempts,
+			Reason:  reason,
+		}
+		if len(middleware) > 0 {
+			if err = middleware.before(info); err != nil {
+				release()
+				return nil, errors.Wrap(err, "makeRequest")
+			}
+		}
+
+		request := client.R()
+		request.SetContext(info.Context)
+		request.SetDoNotParseResponse(stream)
+		// keys may be repeated, e.g. to filter on several devices
+		request.SetMultiValueQueryParams(info.Query)
+		for key, values := range info.Header {
+			request.Header[key] = values
 		}
 
 		// Clear our errors
 		err = nil
 
 		// Check reqType
+		start := time.Now()
 		switch reqType {
 		case "GET":
 			resp, err = request.Get(url)
 		case "POST":
-			resp, err = request.SetBody(data).Post(url)
+			resp, err = request.SetBody(info.Body).Post(url)
 		case "DELETE":
-			resp, err = request.SetBody(data).Delete(url)
+			resp, err = request.SetBody(info.Body).Delete(url)
+		case "PUT":
+			resp, err = request.SetBody(info.Body).Put(url)
+		case "PATCH":
+			resp, err = request.SetBody(info.Body).Patch(url)
 		default:
-			return nil, errors.Errorf("Invalid. Request type [%s] not implemented", reqType)
+			err = errors.Errorf("Invalid. Request type [%s] not implemented", reqType)
+		}
+		body := responseBody(resp, stream)
+		if stream && body == nil && resp != nil && resp.RawResponse != nil {
+			// the slot is held until the caller is done with the body
+			resp.RawResponse.Body = &releaseOnClose{resp.RawResponse.Body, release}
+		} else {
+			release()
+		}
+		if len(middleware) > 0 {
+			middleware.after(info, newResponseInfo(resp, body, err, time.Since(start)))
 		}
 
-		if err != nil {
-			return nil, err
+		// Underlying request issue, e.g. connection refused, reset or a
+		// dial timeout
+		if err == nil && resp.RawResponse == nil {
+			err = errors.New("RawResponse error")
 		}
-		// Underlying request issue. Could be getsockopt error (like network not reachable)
-		if resp.RawResponse == nil {
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, errors.Wrap(ctxErr, "makeRequest")
+			}
+			c.reportFailure(gen, err)
+			if !replayable {
+				return nil, err
+			}
 			// retry another session
-			err = errors.New("RawResponse error")
 			continue
 		}
 
@@ -460,7 +984,7 @@ func (c *CvpClient) makeRequest(reqType string, url string, params *url.Values,
 
 		if status == 301 {
 			// retry another session
-			err = errors.Errorf("Status [%d]", status)
+			err = statusError(resp, body, url)
 			continue
 		}
 		// From 2018.2.0 onwards, a '401' response is returned for
@@ -469,55 +993,148 @@ func (c *CvpClient) makeRequest(reqType string, url string, params *url.Values,
 		// if the session expires, which is after 12 hours of inactivity.
 		// In this case, the session must be refreshed. Retry same host.
 		if status == 401 {
-			retryCnt--
-			if retryCnt > 0 {
-				// reset our session
-				if err := c.resetSession(); err != nil {
+			// A rejected token won't get any better by logging in again
+			if c.usingToken() {
+				return nil, errors.Wrap(statusError(resp, body, url),
+					"makeRequest: Token authentication rejected")
+			}
+			if attempt < maxAttempts {
+				// reset our session, or pick up the one another
+				// request already reset
+				if resetErr := c.relogin(ctx, gen); resetErr != nil {
 					// try another session
-					err = errors.Wrap(err, "makeRequest")
+					err = errors.Wrap(resetErr, "makeRequest")
 				}
+				client, gen, host = c.session()
+				reason = AttemptRelogin
 			} else {
-				err = errors.Errorf("Status [%d]", status)
+				err = statusError(resp, body, url)
 			}
 			continue
 		}
 
 		// client error
-		if status != http.StatusOK {
+		if !success(status) {
+			err = statusError(resp, body, url)
+			if status == http.StatusBadGateway || status == http.StatusServiceUnavailable ||
+				status == http.StatusGatewayTimeout {
+				c.reportFailure(gen, err)
+			}
+			if !replayable {
+				return nil, err
+			}
+			// back off and retry the same session for transient errors
+			if policy.retryable(status) && attempt < maxAttempts {
+				if sleepErr := sleepCtx(ctx, policy.backoff(attempt, resp)); sleepErr != nil {
+					return nil, errors.Wrap(sleepErr, "makeRequest")
+				}
+				err = nil
+				reason = AttemptRetry
+				continue
+			}
 			// retry another session
-			err = errors.Errorf("Status [%d]", status)
 			continue
 		}
 		break
 	}
-	return resp.Body(), nil
+	return resp, nil
 }
 
 // Get implemented as part of cvprac api client interface
 func (c *CvpClient) Get(url string, params *url.Values) ([]byte, error) {
-	return c.makeRequest("GET", url, params, nil)
+	return c.GetCtx(context.Background(), url, params)
 }
 
 // Post implemented as part of cvprac api client interface
 func (c *CvpClient) Post(url string, params *url.Values, data interface{}) ([]byte, error) {
-	return c.makeRequest("POST", url, params, data)
+	return c.PostCtx(context.Background(), url, params, data)
 }
 
 // Delete implemented as part of cvprac api client interface
 func (c *CvpClient) Delete(url string, params *url.Values, data interface{}) ([]byte, error) {
-	return c.makeRequest("DELETE", url, params, data)
+	return c.DeleteCtx(context.Background(), url, params, data)
 }
 
-func parseURLValues(params *url.Values) (map[string]string, error) {
-	newMap := make(map[string]string)
-	for k, v := range *params {
-		if len(v) > 1 {
-			return nil, errors.Errorf("Parsing URL Values: Multiple values for "+
-				"param %s. Values: %v", k, v)
-		}
-		newMap[k] = params.Get(k)
+// Put implemented as part of cvprac api update client interface
+func (c *CvpClient) Put(url string, params *url.Values, data interface{}) ([]byte, error) {
+	return c.PutCtx(context.Background(), url, params, data)
+}
+
+// Patch implemented as part of cvprac api update client interface
+func (c *CvpClient) Patch(url string, params *url.Values, data interface{}) ([]byte, error) {
+	return c.PatchCtx(context.Background(), url, params, data)
+}
+
+// GetCtx implemented as part of cvprac api context client interface
+func (c *CvpClient) GetCtx(ctx context.Context, url string, params *url.Values) ([]byte, error) {
+	return c.makeRequest(ctx, "GET", url, params, nil)
+}
+
+// PostCtx implemented as part of cvprac api context client interface
+func (c *CvpClient) PostCtx(ctx context.Context, url string, params *url.Values,
+	data interface{}) ([]byte, error) {
+	return c.makeRequest(ctx, "POST", url, params, data)
+}
+
+// DeleteCtx implemented as part of cvprac api context client interface
+func (c *CvpClient) DeleteCtx(ctx context.Context, url string, params *url.Values,
+	data interface{}) ([]byte, error) {
+	return c.makeRequest(ctx, "DELETE", url, params, data)
+}
+
+// PutCtx implemented as part of cvprac api context update client interface
+func (c *CvpClient) PutCtx(ctx context.Context, url string, params *url.Values,
+	data interface{}) ([]byte, error) {
+	return c.makeRequest(ctx, "PUT", url, params, data)
+}
+
+// PatchCtx implemented as part of cvprac api context update client interface
+func (c *CvpClient) PatchCtx(ctx context.Context, url string, params *url.Values,
+	data interface{}) ([]byte, error) {
+	return c.makeRequest(ctx, "PATCH", url, params, data)
+}
+
+// idempotent reports whether repeating a request of reqType has the same
+// effect as making it once
+func idempotent(reqType string) bool {
+	return reqType != "POST" && reqType != "PATCH"
+}
+
+// responseBody returns the body of resp. For a streamed response the body is
+// only read, and closed, if the request failed as the caller only gets the
+// body of a successful response.
+func responseBody(resp *resty.Response, stream bool) []byte {
+	if resp == nil || resp.RawResponse == nil {
+		return nil
+	}
+	if !stream {
+		return resp.Body()
+	}
+	if success(resp.StatusCode()) {
+		return nil
+	}
+	defer resp.RawBody().Close()
+	body, _ := ioutil.ReadAll(resp.RawBody())
+	return body
+}
+
+// success reports whether status is a 2xx status
+func success(status int) bool {
+	return status >= 200 && status < 300
+}
+
+// statusError returns a CvpError for an unexpected HTTP status, including the
+// CVP error code and message if the response body carries one.
+func statusError(resp *resty.Response, body []byte, url string) error {
+	cvpErr := &cvpapi.CvpError{StatusCode: resp.StatusCode(), Endpoint: url,
+		Header: resp.Header()}
+
+	var info cvpapi.ErrorResponse
+	if err := json.Unmarshal(body, &info); err == nil {
+		cvpErr.Code = info.ErrorCode
+		cvpErr.Message = info.ErrorMessage
 	}
-	return newMap, nil
+	return cvpErr
 }
 
 func checkResponseStatus(resp *resty.Response) error {
@@ -32,17 +32,23 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
 )
 
 var debugFlag = flag.Bool("debug", false, "Enable debug")
@@ -78,6 +84,12 @@ func TestCvpRac_ClientOptions_UnitTest(t *testing.T) {
 	ok(t, err)
 	_, err = NewCvpClient(Hosts(nil...))
 	assert(t, err != nil, "Nil host list should return error")
+
+	cvpClient, err := NewCvpClient(CvpVersion("2020.1.2"))
+	ok(t, err)
+	equals(t, cvpapi.MustParseVersion("2020.1.2"), cvpClient.Version())
+	_, err = NewCvpClient(CvpVersion("cvaas"))
+	assert(t, err != nil, "Invalid CVP version should return error")
 	_, err = NewCvpClient(Hosts([]string{}...))
 	assert(t, err != nil, "Empty host list should return error")
 
@@ -208,6 +220,11 @@ func TestCvpRac_ClientStatusBadRequest_UnitTest(t *testing.T) {
 	assert(t, err != nil, "POST returned no error when it should have "+
 		"returned StatusBadRequest(400)")
 	assert(t, err.Error() == "Status [400]", "Got: %s", err)
+
+	var cvpErr *cvpapi.CvpError
+	assert(t, errors.As(err, &cvpErr), "Expected CvpError. Got: %T", err)
+	equals(t, http.StatusBadRequest, cvpErr.StatusCode)
+	equals(t, "/StatusBadRequest-test", cvpErr.Endpoint)
 }
 
 func TestCvpRac_ClientRetrySingleHost_UnitTest(t *testing.T) {
@@ -316,6 +333,200 @@ func TestCvpRac_ClientRetry_MultiHost_UnitTest(t *testing.T) {
 	assert(t, err.Error() == "Status [500]", "Got: %s", err)
 }
 
+func TestCvpRac_ClientContextDeadline_UnitTest(t *testing.T) {
+	ts := createServer(t)
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	hosts := []string{host, host, host}
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(hosts...),
+		Port(port),
+		Debug(*debugFlag))
+
+	err = cvpClient.Connect("cvpadmin", "cvp123")
+	ok(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = cvpClient.PostCtx(ctx, "/slow-test", nil, nil)
+	assert(t, err != nil, "POST returned no error when context deadline exceeded")
+	assert(t, errors.Is(err, context.DeadlineExceeded), "Got: %s", err)
+	assert(t, time.Since(start) < 2*time.Second, "Request not abandoned on deadline")
+}
+
+func TestCvpRac_ClientContextCanceled_UnitTest(t *testing.T) {
+	ts := createServer(t)
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(host),
+		Port(port),
+		Debug(*debugFlag))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = cvpClient.ConnectCtx(ctx, "cvpadmin", "cvp123")
+	assert(t, errors.Is(err, context.Canceled), "Got: %v", err)
+
+	_, err = cvpClient.GetCtx(ctx, "/retrycount-test", nil)
+	assert(t, errors.Is(err, context.Canceled), "Got: %v", err)
+}
+
+func TestCvpRac_ClientToken_UnitTest(t *testing.T) {
+	var logins int32
+
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			atomic.AddInt32(&logins, 1)
+		}
+		cookie, err := r.Cookie("access_token")
+		if r.Header.Get("Authorization") != "Bearer valid-token" ||
+			err != nil || cookie.Value != "valid-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{ "message": "Accepted" }`)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(host, host),
+		Port(port),
+		Debug(*debugFlag))
+
+	err = cvpClient.ConnectWithToken("")
+	assert(t, err != nil, "Empty token should return error")
+
+	ok(t, cvpClient.ConnectWithToken("valid-token"))
+	_, err = cvpClient.Get("/cvpInfo/getCvpInfo.do", nil)
+	ok(t, err)
+
+	ok(t, cvpClient.ConnectWithToken("bogus-token"))
+	_, err = cvpClient.Get("/cvpInfo/getCvpInfo.do", nil)
+	assert(t, err != nil, "GET with rejected token should return error")
+
+	var cvpErr *cvpapi.CvpError
+	assert(t, errors.As(err, &cvpErr), "Expected CvpError. Got: %T", err)
+	equals(t, http.StatusUnauthorized, cvpErr.StatusCode)
+	equals(t, int32(0), atomic.LoadInt32(&logins))
+}
+
+func TestCvpRac_ClientPutPatch_UnitTest(t *testing.T) {
+	var failures int32
+
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		if r.URL.Path == "/web/fail" {
+			atomic.AddInt32(&failures, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, `{ "method": "%s", "body": %s }`, r.Method, body)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(host, host, host),
+		Port(port),
+		Debug(*debugFlag))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	var _ cvpapi.ContextUpdateClientInterface = cvpClient
+
+	data, err := cvpClient.Put("/resource", nil, map[string]int{"value": 1})
+	ok(t, err)
+	equals(t, `{ "method": "PUT", "body": {"value":1} }`, string(data))
+
+	data, err = cvpClient.Patch("/resource", nil, map[string]int{"value": 2})
+	ok(t, err)
+	equals(t, `{ "method": "PATCH", "body": {"value":2} }`, string(data))
+
+	// PUT is idempotent so is failed over to the other nodes, PATCH isn't
+	_, err = cvpClient.Put("/fail", nil, nil)
+	assert(t, err != nil, "PUT should return error")
+	equals(t, int32(3), atomic.LoadInt32(&failures))
+
+	_, err = cvpClient.Patch("/fail", nil, nil)
+	assert(t, err != nil, "PATCH should return error")
+	equals(t, int32(4), atomic.LoadInt32(&failures))
+}
+
+func TestCvpRac_ClientMultiValueParams_UnitTest(t *testing.T) {
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		query := r.URL.Query()
+		fmt.Fprintf(w, `{ "ids": "%s", "tag": "%s", "raw": "%s" }`,
+			strings.Join(query["id"], ","), query.Get("tag"), r.URL.RawQuery)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(host),
+		Port(port),
+		Debug(*debugFlag))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	params := &url.Values{
+		"id":  {"00:1c:73:00:00:01", "00:1c:73:00:00:02", "00:1c:73:00:00:03"},
+		"tag": {"leaf"},
+	}
+	expected := `{ "ids": "00:1c:73:00:00:01,00:1c:73:00:00:02,00:1c:73:00:00:03", ` +
+		`"tag": "leaf", "raw": "id=00%3A1c%3A73%3A00%3A00%3A01&id=00%3A1c%3A73%3A00%3A00%3A02&` +
+		`id=00%3A1c%3A73%3A00%3A00%3A03&tag=leaf" }`
+
+	data, err := cvpClient.Get("/inventory/devices", params)
+	ok(t, err)
+	equals(t, expected, string(data))
+
+	data, err = cvpClient.Post("/inventory/devices", params, nil)
+	ok(t, err)
+	equals(t, expected, string(data))
+
+	// The caller's values are left untouched
+	equals(t, 3, len((*params)["id"]))
+}
+
 func createServer(t *testing.T) *httptest.Server {
 	var attempt int32
 
@@ -361,6 +572,9 @@ func createServer(t *testing.T) *httptest.Server {
 					time.Sleep(time.Second * 6)
 				}
 				fmt.Fprintf(w, `{ "message": "ClientRetry", "attempt": %d }`, attp)
+			} else if r.URL.Path == "/web/slow-test" {
+				time.Sleep(time.Second * 3)
+				fmt.Fprintf(w, `{ "message": "Slow" }`)
 			} else if r.URL.Path == "/web/StatusMovedPermanently-test" {
 				attp := atomic.AddInt32(&attempt, 1)
 				t.Logf("Attempt: %d", attp)
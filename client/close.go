@@ -0,0 +1,119 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"net/http/cookiejar"
+	"strings"
+
+	"github.com/pkg/errors"
+	resty "gopkg.in/resty.v1"
+)
+
+// ErrClientClosed is returned by calls made after Close
+var ErrClientClosed = errors.New("client closed")
+
+// trackSession records the current session as the one of its node so Close
+// can log out of it. Token sessions have nothing to log out of. Must be
+// called with c.mu held.
+func (c *CvpClient) trackSession() {
+	if c.tokenAuth {
+		return
+	}
+	if c.sessions == nil {
+		c.sessions = make(map[string]*resty.Client)
+	}
+	c.sessions[c.host] = c.Client
+}
+
+// Close logs out of every node the client holds a session on, drops the
+// session cookies and closes idle connections. Any later call returns
+// ErrClientClosed. With a SessionCache the current session is kept so it
+// can be resumed. Closing a closed client does nothing.
+func (c *CvpClient) Close() error {
+	return c.CloseCtx(context.Background())
+}
+
+// CloseCtx is the context aware version of Close
+func (c *CvpClient) CloseCtx(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	var errorMsg []string
+	for host, session := range c.sessions {
+		keep := c.cache != nil && session == c.Client
+		if !keep && !c.IsCvaas {
+			if err := logout(ctx, session); err != nil {
+				errorMsg = append(errorMsg, host+": "+err.Error())
+			}
+		}
+		releaseSession(session)
+	}
+	c.sessions = nil
+	if c.Client != nil {
+		releaseSession(c.Client)
+	}
+	c.SessID = ""
+
+	// a selector shared with other clients goes on probing with theirs
+	if ps, ok := c.selector.(probingSelector); ok {
+		ps.removeProbe(c)
+	}
+
+	if len(errorMsg) > 0 {
+		return errors.Errorf("Close: Logout failed: %s", strings.Join(errorMsg, "; "))
+	}
+	return nil
+}
+
+// logout ends the session on CVP
+func logout(ctx context.Context, session *resty.Client) error {
+	resp, err := session.R().SetContext(ctx).Post("/login/logout.do")
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp)
+}
+
+// releaseSession drops the cookies of session and closes its idle
+// connections
+func releaseSession(session *resty.Client) {
+	session.Cookies = nil
+	jar, _ := cookiejar.New(nil)
+	session.SetCookieJar(jar)
+	session.GetClient().CloseIdleConnections()
+}
@@ -0,0 +1,148 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// dialTo returns a transport connecting every host to addr so a single
+// test server can act as several nodes
+func dialTo(addr string) *http.Transport {
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// logoutServer acts as nodes node1 and node2 where node1 fails requests
+// other than logins, and counts the logouts per node
+type logoutServer struct {
+	mu      sync.Mutex
+	logouts map[string]int
+}
+
+func (s *logoutServer) handler(w http.ResponseWriter, r *http.Request) {
+	node := strings.Split(r.Host, ":")[0]
+	switch r.URL.Path {
+	case "/web/login/authenticate.do":
+		http.SetCookie(w, &http.Cookie{Name: "session_id", Value: node})
+		fmt.Fprintf(w, `{ "sessionId": "%s" }`, node)
+	case "/web/login/logout.do":
+		s.mu.Lock()
+		s.logouts[node]++
+		s.mu.Unlock()
+		fmt.Fprintf(w, `{ "data": "success" }`)
+	default:
+		if node == "node1" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{ "version": "2020.1.0" }`)
+	}
+}
+
+func TestCvpRac_ClientClose_UnitTest(t *testing.T) {
+	server := &logoutServer{logouts: map[string]int{}}
+	ts := createTestServer(server.handler)
+	defer ts.Close()
+
+	_, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	policy := fastRetryPolicy()
+	policy.MaxAttempts = 1
+	cvpClient, err := NewCvpClient(
+		Protocol("http"),
+		Hosts("node1", "node2"),
+		Port(port),
+		Transport(dialTo(ts.Listener.Addr().String())),
+		Retry(policy),
+		Debug(*debugFlag))
+	ok(t, err)
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	// fail over from node1 so both nodes hold a session
+	_, err = cvpClient.Get("/cvpInfo/getCvpInfo.do", nil)
+	ok(t, err)
+
+	ok(t, cvpClient.Close())
+	equals(t, map[string]int{"node1": 1, "node2": 1}, server.logouts)
+	equals(t, "", cvpClient.GetSessionID())
+
+	_, err = cvpClient.Get("/cvpInfo/getCvpInfo.do", nil)
+	assert(t, errors.Is(err, ErrClientClosed), "Expected ErrClientClosed. Got: %v", err)
+	err = cvpClient.Connect("cvpadmin", "cvp123")
+	assert(t, errors.Is(err, ErrClientClosed), "Expected ErrClientClosed. Got: %v", err)
+
+	// closing again does nothing
+	ok(t, cvpClient.Close())
+	equals(t, map[string]int{"node1": 1, "node2": 1}, server.logouts)
+}
+
+func TestCvpRac_ClientCloseSessionCache_UnitTest(t *testing.T) {
+	server := &logoutServer{logouts: map[string]int{}}
+	ts := createTestServer(server.handler)
+	defer ts.Close()
+
+	_, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	dir, err := ioutil.TempDir("", "cvprac")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+
+	cvpClient, err := NewCvpClient(
+		Protocol("http"),
+		Hosts("node2"),
+		Port(port),
+		Transport(dialTo(ts.Listener.Addr().String())),
+		SessionCache(filepath.Join(dir, "session.json"), 0),
+		Debug(*debugFlag))
+	ok(t, err)
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	// the cached session is kept for the next run
+	ok(t, cvpClient.Close())
+	equals(t, 0, len(server.logouts))
+}
@@ -0,0 +1,121 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Credentials used to authenticate to CVP. If Token is set, it is used as a
+// service account token and Username/Password are ignored.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// CredentialProvider supplies the Credentials used each time the client
+// has to (re)authenticate to CVP. Implementations may fetch them from a
+// secrets store so that rotated passwords are picked up without rebuilding
+// the client.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// CredentialProviderFunc adapts a function to a CredentialProvider
+type CredentialProviderFunc func(ctx context.Context) (*Credentials, error)
+
+// Credentials calls f(ctx)
+func (f CredentialProviderFunc) Credentials(ctx context.Context) (*Credentials, error) {
+	return f(ctx)
+}
+
+// StaticCredentials returns a provider for a fixed username and password
+func StaticCredentials(username string, password string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (*Credentials, error) {
+		return &Credentials{Username: username, Password: password}, nil
+	})
+}
+
+// StaticToken returns a provider for a fixed service account token
+func StaticToken(token string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (*Credentials, error) {
+		return &Credentials{Token: token}, nil
+	})
+}
+
+// EnvCredentials returns a provider reading the credentials from the named
+// environment variables every time they are needed. If tokenVar is
+// non-empty and set, the token is used instead of username/password.
+func EnvCredentials(usernameVar, passwordVar, tokenVar string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (*Credentials, error) {
+		if tokenVar != "" {
+			if token := os.Getenv(tokenVar); token != "" {
+				return &Credentials{Token: token}, nil
+			}
+		}
+		username, found := os.LookupEnv(usernameVar)
+		if !found {
+			return nil, errors.Errorf("EnvCredentials: [%s] not set", usernameVar)
+		}
+		password, found := os.LookupEnv(passwordVar)
+		if !found {
+			return nil, errors.Errorf("EnvCredentials: [%s] not set", passwordVar)
+		}
+		return &Credentials{Username: username, Password: password}, nil
+	})
+}
+
+// FileCredentials returns a provider reading the credentials from a JSON
+// file every time they are needed. The file holds an object with
+// "username"/"password" or "token" keys.
+func FileCredentials(path string) CredentialProvider {
+	return CredentialProviderFunc(func(context.Context) (*Credentials, error) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "FileCredentials")
+		}
+		var creds Credentials
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return nil, errors.Wrapf(err, "FileCredentials: [%s]", path)
+		}
+		if creds.Token == "" && creds.Username == "" {
+			return nil, errors.Errorf("FileCredentials: No credentials in [%s]", path)
+		}
+		return &creds, nil
+	})
+}
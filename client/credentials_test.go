@@ -0,0 +1,149 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCvpRac_EnvCredentials_UnitTest(t *testing.T) {
+	os.Setenv("CVPRAC_TEST_USER", "cvpadmin")
+	os.Setenv("CVPRAC_TEST_PASS", "cvp123")
+	os.Unsetenv("CVPRAC_TEST_TOKEN")
+	defer os.Unsetenv("CVPRAC_TEST_USER")
+	defer os.Unsetenv("CVPRAC_TEST_PASS")
+
+	provider := EnvCredentials("CVPRAC_TEST_USER", "CVPRAC_TEST_PASS", "CVPRAC_TEST_TOKEN")
+	creds, err := provider.Credentials(context.Background())
+	ok(t, err)
+	equals(t, &Credentials{Username: "cvpadmin", Password: "cvp123"}, creds)
+
+	os.Setenv("CVPRAC_TEST_TOKEN", "token")
+	defer os.Unsetenv("CVPRAC_TEST_TOKEN")
+	creds, err = provider.Credentials(context.Background())
+	ok(t, err)
+	equals(t, &Credentials{Token: "token"}, creds)
+
+	provider = EnvCredentials("CVPRAC_TEST_BOGUS", "CVPRAC_TEST_PASS", "")
+	_, err = provider.Credentials(context.Background())
+	assert(t, err != nil, "Unset variable should return error")
+}
+
+func TestCvpRac_FileCredentials_UnitTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvprac")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "creds.json")
+	provider := FileCredentials(path)
+
+	_, err = provider.Credentials(context.Background())
+	assert(t, err != nil, "Missing file should return error")
+
+	ok(t, ioutil.WriteFile(path, []byte(`{"username": "cvpadmin", "password": "cvp123"}`),
+		0600))
+	creds, err := provider.Credentials(context.Background())
+	ok(t, err)
+	equals(t, &Credentials{Username: "cvpadmin", Password: "cvp123"}, creds)
+
+	ok(t, ioutil.WriteFile(path, []byte(`{}`), 0600))
+	_, err = provider.Credentials(context.Background())
+	assert(t, err != nil, "Empty credentials should return error")
+}
+
+func TestCvpRac_CredentialProviderRotation_UnitTest(t *testing.T) {
+	var password atomic.Value
+	password.Store("old")
+
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			var creds map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+				t.Errorf("Decoding login: %s", err)
+			}
+			if creds["password"] != password.Load().(string) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session_id", Value: creds["password"]})
+			fmt.Fprintf(w, `{ "sessionId": "%s" }`, creds["password"])
+			return
+		}
+		cookie, err := r.Cookie("session_id")
+		if err != nil || cookie.Value != password.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{ "message": "Accepted" }`)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	var calls int32
+	provider := CredentialProviderFunc(func(context.Context) (*Credentials, error) {
+		atomic.AddInt32(&calls, 1)
+		return &Credentials{Username: "cvpadmin", Password: password.Load().(string)}, nil
+	})
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(host),
+		Port(port),
+		Debug(*debugFlag))
+
+	err = cvpClient.ConnectWithCredentials(nil)
+	assert(t, err != nil, "Nil provider should return error")
+
+	ok(t, cvpClient.ConnectWithCredentials(provider))
+	_, err = cvpClient.Get("/test", nil)
+	ok(t, err)
+
+	// Rotate the password; the session expires and the client logs in again
+	// with the new password fetched from the provider.
+	password.Store("new")
+	_, err = cvpClient.Get("/test", nil)
+	ok(t, err)
+	equals(t, "new", cvpClient.GetSessionID())
+	equals(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,115 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Request is a request made with Do
+type Request struct {
+	// Method is one of GET, POST, PUT, PATCH or DELETE, in any case
+	Method string
+	// Path is the API path, relative to the base URL of the node
+	Path   string
+	Query  url.Values
+	Header http.Header
+	// Body is sent as JSON, or as is if it is an io.Reader or []byte. An
+	// io.Reader is read before the first attempt so retries send it again.
+	Body interface{}
+}
+
+// Response is the response to a request made with Do. The caller must
+// close Body.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       io.ReadCloser
+}
+
+// Do makes req and returns the response without reading the body, for
+// access to the response headers or to stream large bodies. The request is
+// retried, logged in again and failed over like any other. A response with a
+// status other than 2xx is returned as a *cvpapi.CvpError holding its status
+// and headers. Note that the client Timeout also bounds reading the body.
+func (c *CvpClient) Do(req *Request) (*Response, error) {
+	return c.DoCtx(context.Background(), req)
+}
+
+// DoCtx is the context aware version of Do. The context also bounds reading
+// the body.
+func (c *CvpClient) DoCtx(ctx context.Context, req *Request) (*Response, error) {
+	if req == nil || req.Path == "" {
+		return nil, errors.New("Do: Request path required")
+	}
+	// checked before limits and middleware see the request
+	method := strings.ToUpper(req.Method)
+	switch method {
+	case "GET", "POST", "PUT", "PATCH", "DELETE":
+	default:
+		return nil, errors.Errorf("Do: Invalid. Request type [%s] not implemented",
+			req.Method)
+	}
+	body := req.Body
+	if reader, ok := body.(io.Reader); ok {
+		data, err := ioutil.ReadAll(reader)
+		if closer, ok := reader.(io.Closer); ok {
+			closer.Close()
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Do: Reading request body")
+		}
+		body = data
+	}
+	resp, err := c.execute(ctx, &Request{
+		Method: method,
+		Path:   req.Path,
+		Query:  req.Query,
+		Header: req.Header,
+		Body:   body,
+	}, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "Do")
+	}
+	return &Response{
+		StatusCode: resp.StatusCode(),
+		Header:     resp.Header(),
+		Body:       resp.RawBody(),
+	}, nil
+}
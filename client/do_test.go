@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
+)
+
+func TestCvpRac_ClientDo_UnitTest(t *testing.T) {
+	var hits, uploads, calls int32
+	payload := strings.Repeat("0123456789", 100000)
+
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/web/login/authenticate.do":
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+		case "/web/dump":
+			// fail the first attempt to check the retry path closes it
+			if atomic.AddInt32(&hits, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprintf(w, `{ "errorCode": "1", "errorMessage": "busy" }`)
+				return
+			}
+			w.Header().Set("X-Next-Cursor", "page2")
+			fmt.Fprint(w, payload)
+		case "/web/busy":
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintf(w, `{ "errorCode": "1", "errorMessage": "busy" }`)
+		case "/web/created":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{ "id": "1" }`)
+		case "/web/empty":
+			w.WriteHeader(http.StatusNoContent)
+		case "/web/upload":
+			body, _ := ioutil.ReadAll(r.Body)
+			// fail the first upload to check the body is sent again
+			if atomic.AddInt32(&uploads, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(w, "%s %s %s %s", r.Method, r.URL.RawQuery,
+				r.Header.Get("X-Test"), body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{ "errorCode": "132801", "errorMessage": "Entity does not exist" }`)
+		}
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(host),
+		Port(port),
+		Retry(fastRetryPolicy()),
+		Middlewares(Hooks{Before: func(req *RequestInfo) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		}}),
+		Debug(*debugFlag))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	resp, err := cvpClient.Do(&Request{Method: "GET", Path: "/dump"})
+	ok(t, err)
+	equals(t, http.StatusOK, resp.StatusCode)
+	equals(t, "page2", resp.Header.Get("X-Next-Cursor"))
+	data, err := ioutil.ReadAll(resp.Body)
+	ok(t, err)
+	ok(t, resp.Body.Close())
+	equals(t, len(payload), len(data))
+	equals(t, int32(2), atomic.LoadInt32(&hits))
+
+	resp, err = cvpClient.Do(&Request{
+		Method: "put",
+		Path:   "/upload",
+		Query:  url.Values{"id": {"1", "2"}},
+		Header: http.Header{"X-Test": {"yes"}},
+		Body:   bytes.NewReader([]byte("raw body")),
+	})
+	ok(t, err)
+	data, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	equals(t, "PUT id=1&id=2 yes raw body", string(data))
+	equals(t, int32(2), atomic.LoadInt32(&uploads))
+
+	_, err = cvpClient.Do(&Request{Method: "GET", Path: "/missing"})
+	assert(t, err != nil, "Expected error for missing path")
+	var cvpErr *cvpapi.CvpError
+	assert(t, errors.As(err, &cvpErr), "Expected CvpError. Got: %T", err)
+	equals(t, http.StatusNotFound, cvpErr.StatusCode)
+	assert(t, errors.Is(err, cvpapi.ErrEntityDoesNotExist), "Expected ErrEntityDoesNotExist")
+
+	// any 2xx succeeds
+	resp, err = cvpClient.Do(&Request{Method: "PUT", Path: "/created"})
+	ok(t, err)
+	data, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	equals(t, http.StatusCreated, resp.StatusCode)
+	equals(t, `{ "id": "1" }`, string(data))
+	resp, err = cvpClient.Do(&Request{Method: "PATCH", Path: "/empty"})
+	ok(t, err)
+	resp.Body.Close()
+	equals(t, http.StatusNoContent, resp.StatusCode)
+
+	// the status and headers of a failed response are in the error
+	_, err = cvpClient.Do(&Request{Method: "GET", Path: "/busy"})
+	cvpErr = nil
+	assert(t, errors.As(err, &cvpErr), "Expected CvpError. Got: %T", err)
+	equals(t, http.StatusTooManyRequests, cvpErr.StatusCode)
+	equals(t, "0", cvpErr.Header.Get("Retry-After"))
+
+	_, err = cvpClient.Do(&Request{Method: "GET"})
+	assert(t, err != nil, "Expected error for empty path")
+	called := atomic.LoadInt32(&calls)
+	_, err = cvpClient.Do(&Request{Method: "HEAD", Path: "/dump"})
+	assert(t, err != nil, "Expected error for unsupported method")
+	equals(t, called, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,300 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCooldown is how long a failed node is avoided by a
+	// HealthSelector before it is tried again
+	DefaultCooldown = 30 * time.Second
+	// DefaultProbeInterval is how often a HealthSelector probes unhealthy
+	// nodes
+	DefaultProbeInterval = 10 * time.Second
+)
+
+// HostSelector chooses the order in which CVP nodes are tried when a session
+// is created or fails over. Implementations must be safe for concurrent use.
+type HostSelector interface {
+	// Select returns the hosts to try, most preferred first. current is the
+	// node of the session being replaced, or empty if there is none.
+	Select(hosts []string, current string) []string
+	// Report records the outcome of using host. err is nil on success.
+	Report(host string, err error)
+}
+
+// ProbeFunc checks whether a CVP node is reachable
+type ProbeFunc func(ctx context.Context, host string) error
+
+// probingSelector is implemented by selectors that probe nodes using the
+// connection settings of the clients sharing them. Each client adds its
+// probe and removes it once closed.
+type probingSelector interface {
+	addProbe(owner interface{}, probe ProbeFunc)
+	removeProbe(owner interface{})
+}
+
+// probeEntry is the probe of a client using a HealthSelector
+type probeEntry struct {
+	owner interface{}
+	probe ProbeFunc
+}
+
+type roundRobinSelector struct{}
+
+// RoundRobin returns a HostSelector that tries the nodes in order, starting
+// with the one after the current node. This is the default.
+func RoundRobin() HostSelector {
+	return roundRobinSelector{}
+}
+
+func (roundRobinSelector) Select(hosts []string, current string) []string {
+	start := 0
+	for i, host := range hosts {
+		if host == current {
+			start = i + 1
+			break
+		}
+	}
+	ordered := make([]string, 0, len(hosts))
+	for i := range hosts {
+		ordered = append(ordered, hosts[(start+i)%len(hosts)])
+	}
+	return ordered
+}
+
+func (roundRobinSelector) Report(string, error) {}
+
+type nodeHealth struct {
+	healthy bool
+	until   time.Time
+}
+
+// HealthSelector is a HostSelector that keeps track of the health of each
+// node. A node that fails is avoided for Cooldown and probed every
+// ProbeInterval in the background until it responds again. The last node
+// that worked is preferred. Nodes that are down are still tried as a last
+// resort so a selection is never empty. The zero value is ready to use, zero
+// durations using DefaultCooldown and DefaultProbeInterval.
+type HealthSelector struct {
+	Cooldown      time.Duration
+	ProbeInterval time.Duration
+
+	mu        sync.Mutex
+	nodes     map[string]*nodeHealth
+	preferred string
+	probes    []probeEntry
+	probing   bool
+}
+
+// NewHealthSelector returns a HealthSelector. Zero values use
+// DefaultCooldown and DefaultProbeInterval.
+func NewHealthSelector(cooldown time.Duration, probeInterval time.Duration) *HealthSelector {
+	if cooldown <= 0 {
+		cooldown = DefaultCooldown
+	}
+	if probeInterval <= 0 {
+		probeInterval = DefaultProbeInterval
+	}
+	return &HealthSelector{
+		Cooldown:      cooldown,
+		ProbeInterval: probeInterval,
+		nodes:         make(map[string]*nodeHealth),
+	}
+}
+
+// cooldown returns Cooldown, or DefaultCooldown if it isn't set
+func (h *HealthSelector) cooldown() time.Duration {
+	if h.Cooldown <= 0 {
+		return DefaultCooldown
+	}
+	return h.Cooldown
+}
+
+// probeInterval returns ProbeInterval, or DefaultProbeInterval if it isn't
+// set
+func (h *HealthSelector) probeInterval() time.Duration {
+	if h.ProbeInterval <= 0 {
+		return DefaultProbeInterval
+	}
+	return h.ProbeInterval
+}
+
+// Healthy reports whether host is currently considered healthy
+func (h *HealthSelector) Healthy(host string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.healthy(host, time.Now())
+}
+
+func (h *HealthSelector) healthy(host string, now time.Time) bool {
+	node, found := h.nodes[host]
+	return !found || node.healthy || now.After(node.until)
+}
+
+// Select implements HostSelector
+func (h *HealthSelector) Select(hosts []string, current string) []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	var preferred, healthy, down []string
+	for _, host := range RoundRobin().Select(hosts, current) {
+		switch {
+		case !h.healthy(host, now):
+			down = append(down, host)
+		case host == h.preferred:
+			preferred = append(preferred, host)
+		default:
+			healthy = append(healthy, host)
+		}
+	}
+	// of the nodes that are down, try the ones that should be back first
+	sort.SliceStable(down, func(i, j int) bool {
+		return h.nodes[down[i]].until.Before(h.nodes[down[j]].until)
+	})
+	return append(append(preferred, healthy...), down...)
+}
+
+// Report implements HostSelector
+func (h *HealthSelector) Report(host string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.nodes == nil {
+		h.nodes = make(map[string]*nodeHealth)
+	}
+	if err == nil {
+		h.nodes[host] = &nodeHealth{healthy: true}
+		h.preferred = host
+		return
+	}
+	h.nodes[host] = &nodeHealth{until: time.Now().Add(h.cooldown())}
+	if h.preferred == host {
+		h.preferred = ""
+	}
+	h.startProbing()
+}
+
+// addProbe adds the probe of owner, replacing the one it had
+func (h *HealthSelector) addProbe(owner interface{}, probe ProbeFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.dropProbe(owner)
+	h.probes = append(h.probes, probeEntry{owner: owner, probe: probe})
+	for _, node := range h.nodes {
+		if !node.healthy {
+			h.startProbing()
+			break
+		}
+	}
+}
+
+// removeProbe removes the probe of owner. The other clients sharing the
+// selector go on probing with theirs.
+func (h *HealthSelector) removeProbe(owner interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.dropProbe(owner)
+}
+
+func (h *HealthSelector) dropProbe(owner interface{}) {
+	for i, entry := range h.probes {
+		if entry.owner == owner {
+			h.probes = append(h.probes[:i:i], h.probes[i+1:]...)
+			return
+		}
+	}
+}
+
+// currentProbe returns the probe of the client added last, nil if there is
+// none. Must be called with h.mu held.
+func (h *HealthSelector) currentProbe() ProbeFunc {
+	if len(h.probes) == 0 {
+		return nil
+	}
+	return h.probes[len(h.probes)-1].probe
+}
+
+// startProbing starts the probe loop unless it's running or there is no
+// probe. Must be called with h.mu held.
+func (h *HealthSelector) startProbing() {
+	if len(h.probes) > 0 && !h.probing {
+		h.probing = true
+		go h.probeLoop()
+	}
+}
+
+// probeLoop probes the unhealthy nodes until they have all recovered
+func (h *HealthSelector) probeLoop() {
+	interval := h.probeInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		h.mu.Lock()
+		var down []string
+		for host, node := range h.nodes {
+			if !node.healthy {
+				down = append(down, host)
+			}
+		}
+		// stop once every node is back, or every client is closed
+		probe := h.currentProbe()
+		if len(down) == 0 || probe == nil {
+			h.probing = false
+			h.mu.Unlock()
+			return
+		}
+		h.mu.Unlock()
+
+		for _, host := range down {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := probe(ctx, host)
+			cancel()
+
+			h.mu.Lock()
+			if node := h.nodes[host]; node != nil && !node.healthy {
+				if err == nil {
+					node.healthy = true
+				} else {
+					node.until = time.Now().Add(h.cooldown())
+				}
+			}
+			h.mu.Unlock()
+		}
+	}
+}
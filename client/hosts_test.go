@@ -0,0 +1,195 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCvpRac_RoundRobinSelect_UnitTest(t *testing.T) {
+	hosts := []string{"host1", "host2", "host3"}
+	selector := RoundRobin()
+	equals(t, hosts, selector.Select(hosts, ""))
+	equals(t, []string{"host2", "host3", "host1"}, selector.Select(hosts, "host1"))
+	equals(t, []string{"host1", "host2", "host3"}, selector.Select(hosts, "host3"))
+}
+
+func TestCvpRac_HealthSelectorSelect_UnitTest(t *testing.T) {
+	hosts := []string{"host1", "host2", "host3"}
+	selector := NewHealthSelector(50*time.Millisecond, time.Hour)
+
+	// last known good node is tried first
+	selector.Report("host2", nil)
+	equals(t, []string{"host2", "host1", "host3"}, selector.Select(hosts, ""))
+
+	// failed nodes are tried last, the longest failed one first
+	selector.Report("host2", errors.New("down"))
+	selector.Report("host1", errors.New("down"))
+	equals(t, []string{"host3", "host2", "host1"}, selector.Select(hosts, ""))
+	assert(t, !selector.Healthy("host1"), "Expected host1 to be unhealthy")
+
+	// and are tried again once the cooldown expires
+	time.Sleep(60 * time.Millisecond)
+	assert(t, selector.Healthy("host1"), "Expected host1 cooldown to expire")
+	equals(t, []string{"host1", "host2", "host3"}, selector.Select(hosts, ""))
+}
+
+func TestCvpRac_HealthSelectorZero_UnitTest(t *testing.T) {
+	hosts := []string{"host1", "host2"}
+	selector := &HealthSelector{}
+	equals(t, hosts, selector.Select(hosts, ""))
+
+	// a probing client starts the probe loop on the first failure
+	selector.addProbe(t, func(ctx context.Context, host string) error {
+		return nil
+	})
+	defer selector.removeProbe(t)
+	selector.Report("host1", errors.New("down"))
+	assert(t, !selector.Healthy("host1"), "Expected host1 to be unhealthy")
+	equals(t, []string{"host2", "host1"}, selector.Select(hosts, ""))
+	selector.Report("host2", nil)
+	equals(t, []string{"host2", "host1"}, selector.Select(hosts, ""))
+}
+
+func TestCvpRac_HealthSelectorClient_UnitTest(t *testing.T) {
+	var downLogins, upLogins, down int32 = 0, 0, 1
+
+	// requests for node1 act as a node being upgraded, requests for node2
+	// as a healthy node
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		isDown := strings.HasPrefix(r.Host, "node1") && atomic.LoadInt32(&down) == 1
+		if r.URL.Path == "/web/login/authenticate.do" {
+			if isDown {
+				atomic.AddInt32(&downLogins, 1)
+			} else {
+				atomic.AddInt32(&upLogins, 1)
+			}
+		}
+		if isDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		fmt.Fprintf(w, `{ "version": "2020.1.0" }`)
+	})
+	defer ts.Close()
+
+	_, port, err := parseURL(ts.URL)
+	ok(t, err)
+	transport := dialTo(ts.Listener.Addr().String())
+
+	selector := NewHealthSelector(time.Hour, 10*time.Millisecond)
+	newClient := func() *CvpClient {
+		cvpClient, err := NewCvpClient(
+			Protocol("http"),
+			Hosts("node1", "node2"),
+			Port(port),
+			Transport(transport),
+			HostSelection(selector))
+		ok(t, err)
+		return cvpClient
+	}
+
+	ok(t, newClient().Connect("cvpadmin", "cvp123"))
+	equals(t, int32(1), atomic.LoadInt32(&downLogins))
+	equals(t, int32(1), atomic.LoadInt32(&upLogins))
+
+	// the failed node is skipped by the next client
+	ok(t, newClient().Connect("cvpadmin", "cvp123"))
+	equals(t, int32(1), atomic.LoadInt32(&downLogins))
+	equals(t, int32(2), atomic.LoadInt32(&upLogins))
+
+	// and marked healthy again by the background probe once it's back
+	atomic.StoreInt32(&down, 0)
+	deadline := time.Now().Add(2 * time.Second)
+	for !selector.Healthy("node1") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert(t, selector.Healthy("node1"), "Expected probe to mark node healthy")
+}
+
+func TestCvpRac_HealthSelectorSharedClose_UnitTest(t *testing.T) {
+	var down int32 = 1
+
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Host, "node1") && atomic.LoadInt32(&down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		fmt.Fprintf(w, `{ "version": "2020.1.0" }`)
+	})
+	defer ts.Close()
+
+	_, port, err := parseURL(ts.URL)
+	ok(t, err)
+	transport := dialTo(ts.Listener.Addr().String())
+
+	selector := NewHealthSelector(time.Hour, 10*time.Millisecond)
+	newClient := func() *CvpClient {
+		cvpClient, err := NewCvpClient(
+			Protocol("http"),
+			Hosts("node1", "node2"),
+			Port(port),
+			Transport(transport),
+			HostSelection(selector))
+		ok(t, err)
+		ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+		return cvpClient
+	}
+	first, second := newClient(), newClient()
+	defer second.Close()
+	assert(t, !selector.Healthy("node1"), "Expected node1 to be unhealthy")
+
+	// closing one client leaves the selector probing with the other
+	ok(t, first.Close())
+	atomic.StoreInt32(&down, 0)
+	deadline := time.Now().Add(2 * time.Second)
+	for !selector.Healthy("node1") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert(t, selector.Healthy("node1"), "Expected probe to mark node healthy")
+}
@@ -0,0 +1,199 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EndpointClass groups the requests sharing a Limit
+type EndpointClass string
+
+// Endpoint classes used by the default classifier
+const (
+	// ReadEndpoints are GET requests
+	ReadEndpoints EndpointClass = "read"
+	// WriteEndpoints are all other requests, e.g. provisioning changes
+	WriteEndpoints EndpointClass = "write"
+)
+
+// ClassifierFunc returns the class of a request
+type ClassifierFunc func(method string, path string) EndpointClass
+
+// defaultClassifier classifies GETs as reads and everything else as writes
+func defaultClassifier(method string, path string) EndpointClass {
+	if method == "GET" {
+		return ReadEndpoints
+	}
+	return WriteEndpoints
+}
+
+// Limit caps the requests of an EndpointClass. Zero values are unlimited.
+type Limit struct {
+	// Rate is the sustained number of requests per second
+	Rate float64
+	// Burst is the number of requests that can be made at once above
+	// Rate. Defaults to 1.
+	Burst int
+	// MaxInFlight is the number of requests waiting on CVP at once
+	MaxInFlight int
+}
+
+// RateLimit limits the requests of class. Requests over the limit wait
+// (bounded by their context) before being sent. Every attempt of a request
+// counts, including retries.
+func RateLimit(class EndpointClass, limit Limit) Option {
+	return func(c *CvpClient) error {
+		if limit.Rate < 0 || limit.Burst < 0 || limit.MaxInFlight < 0 {
+			return errors.Errorf("RateLimit: Invalid limit %+v", limit)
+		}
+		if c.limiters == nil {
+			c.limiters = make(map[EndpointClass]*limiter)
+		}
+		c.limiters[class] = newLimiter(limit)
+		return nil
+	}
+}
+
+// EndpointClassifier sets the function classifying requests for RateLimit.
+// By default GET requests are ReadEndpoints and others WriteEndpoints.
+func EndpointClassifier(classify ClassifierFunc) Option {
+	return func(c *CvpClient) error {
+		if classify == nil {
+			return errors.New("EndpointClassifier: nil classifier")
+		}
+		c.classify = classify
+		return nil
+	}
+}
+
+// limiter enforces a Limit
+type limiter struct {
+	slots chan struct{}
+
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limit Limit) *limiter {
+	l := &limiter{rate: limit.Rate, burst: math.Max(float64(limit.Burst), 1)}
+	l.tokens = l.burst
+	if limit.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+// reserve takes a token and returns how long to wait for it
+func (l *limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// unreserve gives back a token the caller didn't use
+func (l *limiter) unreserve() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = math.Min(l.burst, l.tokens+1)
+}
+
+// acquire waits for the rate and a free slot. The returned function
+// releases the slot.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l.rate > 0 {
+		if err := sleepCtx(ctx, l.reserve(time.Now())); err != nil {
+			l.unreserve()
+			return nil, err
+		}
+	}
+	if l.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-l.slots }) }, nil
+}
+
+// acquireLimit waits for the limit of the class of the request, if any
+func (c *CvpClient) acquireLimit(ctx context.Context, method string,
+	path string) (func(), error) {
+	// the limiter is looked up under the lock as RateLimit may be adding
+	// one concurrently
+	c.mu.RLock()
+	var l *limiter
+	if len(c.limiters) > 0 {
+		classify := c.classify
+		if classify == nil {
+			classify = defaultClassifier
+		}
+		l = c.limiters[classify(method, path)]
+	}
+	c.mu.RUnlock()
+	if l == nil {
+		return func() {}, nil
+	}
+	return l.acquire(ctx)
+}
+
+// releaseOnClose releases the limit of a streamed response once its body
+// is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
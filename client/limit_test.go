@@ -0,0 +1,189 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// createBlockingServer returns a server holding POSTs to /test until unblock
+// is closed, recording the maximum number of POSTs in flight.
+func createBlockingServer(unblock chan struct{}, maxInFlight *int32) (string, int, func()) {
+	var inFlight int32
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		if r.Method == "POST" {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(maxInFlight)
+				if n <= max || atomic.CompareAndSwapInt32(maxInFlight, max, n) {
+					break
+				}
+			}
+			<-unblock
+		}
+		fmt.Fprintf(w, `{ "message": "Accepted" }`)
+	})
+	host, port, _ := parseURL(ts.URL)
+	return host, port, ts.Close
+}
+
+func TestCvpRac_LimitMaxInFlight_UnitTest(t *testing.T) {
+	var maxInFlight int32
+	unblock := make(chan struct{})
+	host, port, closeFn := createBlockingServer(unblock, &maxInFlight)
+	defer closeFn()
+
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		RateLimit(WriteEndpoints, Limit{MaxInFlight: 2}))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cvpClient.Post("/test", nil, nil)
+			errs <- err
+		}()
+	}
+	// reads are not limited by the write class
+	time.Sleep(50 * time.Millisecond)
+	_, err := cvpClient.Get("/test", nil)
+	ok(t, err)
+
+	close(unblock)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		ok(t, err)
+	}
+	equals(t, int32(2), atomic.LoadInt32(&maxInFlight))
+}
+
+func TestCvpRac_LimitRate_UnitTest(t *testing.T) {
+	var hits int32
+	host, port, closeFn := createStatusServer(&hits, "")
+	defer closeFn()
+
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		RateLimit(ReadEndpoints, Limit{Rate: 20, Burst: 2}))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_, err := cvpClient.Get("/test", nil)
+		ok(t, err)
+	}
+	// the first 2 requests use the burst, the other 4 wait 50ms each
+	elapsed := time.Since(start)
+	assert(t, elapsed >= 190*time.Millisecond, "Expected rate limit wait, got %s", elapsed)
+	equals(t, int32(6), atomic.LoadInt32(&hits))
+}
+
+func TestCvpRac_LimitContext_UnitTest(t *testing.T) {
+	var maxInFlight int32
+	unblock := make(chan struct{})
+	host, port, closeFn := createBlockingServer(unblock, &maxInFlight)
+	defer closeFn()
+
+	classify := func(method string, path string) EndpointClass {
+		return "provisioning"
+	}
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		EndpointClassifier(classify),
+		RateLimit("provisioning", Limit{MaxInFlight: 1}))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	done := make(chan error)
+	go func() {
+		_, err := cvpClient.Post("/test", nil, nil)
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// the custom class also holds back GETs
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := cvpClient.GetCtx(ctx, "/test", nil)
+	assert(t, err != nil, "Expected limit wait to time out")
+	equals(t, context.DeadlineExceeded, errors.Cause(err))
+
+	close(unblock)
+	ok(t, <-done)
+}
+
+func TestCvpRac_LimitStream_UnitTest(t *testing.T) {
+	var hits int32
+	host, port, closeFn := createStatusServer(&hits, "")
+	defer closeFn()
+
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		RateLimit(ReadEndpoints, Limit{MaxInFlight: 1}))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	resp, err := cvpClient.Do(&Request{Method: "GET", Path: "/test"})
+	ok(t, err)
+
+	// the slot is held until the body is closed
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = cvpClient.GetCtx(ctx, "/test", nil)
+	equals(t, context.DeadlineExceeded, errors.Cause(err))
+
+	ok(t, resp.Body.Close())
+	_, err = cvpClient.Get("/test", nil)
+	ok(t, err)
+}
+
+func TestCvpRac_LimitInvalid_UnitTest(t *testing.T) {
+	_, err := NewCvpClient(RateLimit(ReadEndpoints, Limit{Rate: -1}))
+	assert(t, err != nil, "Expected error for negative rate")
+	_, err = NewCvpClient(EndpointClassifier(nil))
+	assert(t, err != nil, "Expected error for nil classifier")
+
+	cvpClient, _ := NewCvpClient()
+	ok(t, cvpClient.SetRateLimit(WriteEndpoints, Limit{Rate: 5, MaxInFlight: 4}))
+}
@@ -0,0 +1,169 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Logger is a structured logger taking a message and alternating key/value
+// pairs. *slog.Logger satisfies it.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Redacted replaces secrets in log lines
+const Redacted = "[REDACTED]"
+
+var (
+	// secretKeys are the (lower case) parameter names whose values are
+	// never logged
+	secretKeys = map[string]bool{
+		"password":     true,
+		"sessionid":    true,
+		"session_id":   true,
+		"access_token": true,
+		"token":        true,
+		"cookie":       true,
+	}
+	secretNames  = `password|sessionId|session_id|access_token|token|cookie`
+	secretJSON   = regexp.MustCompile(`(?i)("(?:` + secretNames + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	secretParam  = regexp.MustCompile(`(?i)\b(` + secretNames + `)=[^&\s;,"]+`)
+	secretBearer = regexp.MustCompile(`(?i)\b(Bearer\s+)[^\s"]+`)
+)
+
+// redact replaces passwords, session IDs and tokens found in s
+func redact(s string) string {
+	s = secretJSON.ReplaceAllString(s, `$1"`+Redacted+`"`)
+	s = secretParam.ReplaceAllString(s, "$1="+Redacted)
+	return secretBearer.ReplaceAllString(s, "${1}"+Redacted)
+}
+
+// redactBody returns the request body with its secrets redacted. Strings and
+// []byte, e.g. the body of API.Login, are sent as is so they're redacted
+// before being marshalled, which would escape their quotes.
+func redactBody(body interface{}) string {
+	switch v := body.(type) {
+	case string:
+		body = redact(v)
+	case []byte:
+		body = redact(string(v))
+	}
+	data, _ := json.Marshal(body)
+	return redact(string(data))
+}
+
+// redactQuery encodes query with the values of secret parameters replaced
+func redactQuery(query url.Values) string {
+	redacted := url.Values{}
+	for key, values := range query {
+		if secretKeys[strings.ToLower(key)] {
+			values = []string{Redacted}
+		}
+		redacted[key] = values
+	}
+	return redacted.Encode()
+}
+
+// logMiddleware logs every attempt. With debug enabled the request and
+// response bodies are logged too.
+type logMiddleware struct {
+	logger Logger
+	debug  bool
+}
+
+func (l *logMiddleware) BeforeRequest(req *RequestInfo) error {
+	return nil
+}
+
+func (l *logMiddleware) AfterResponse(req *RequestInfo, resp *ResponseInfo) {
+	args := []interface{}{
+		"method", req.Method,
+		"path", req.Path,
+		"node", req.Host,
+		"attempt", req.Attempt,
+		"reason", string(req.Reason),
+		"status", resp.Status,
+		"latency", resp.Duration,
+	}
+	if len(req.Query) > 0 {
+		args = append(args, "query", redactQuery(req.Query))
+	}
+	if l.debug {
+		if req.Body != nil {
+			args = append(args, "request", redactBody(req.Body))
+		}
+		args = append(args, "response", redact(string(resp.Body)))
+	}
+
+	switch {
+	case resp.Err != nil:
+		l.logger.Error("CVP request failed", append(args, "error", redact(resp.Err.Error()))...)
+	case resp.Status >= 400 || resp.Status < 200:
+		l.logger.Warn("CVP request failed", args...)
+	default:
+		l.logger.Debug("CVP request", args...)
+	}
+}
+
+// logInfo logs to the client's logger, if any, with secrets redacted from
+// string values
+func (c *CvpClient) logInfo(msg string, args ...interface{}) {
+	if c.logger != nil {
+		c.logger.Info(msg, redactArgs(args)...)
+	}
+}
+
+// logWarn is logInfo at warning level
+func (c *CvpClient) logWarn(msg string, args ...interface{}) {
+	if c.logger != nil {
+		c.logger.Warn(msg, redactArgs(args)...)
+	}
+}
+
+func redactArgs(args []interface{}) []interface{} {
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			args[i] = redact(v)
+		case error:
+			args[i] = redact(v.Error())
+		}
+	}
+	return args
+}
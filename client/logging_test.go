@@ -0,0 +1,169 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type logEntry struct {
+	level string
+	msg   string
+	args  map[string]interface{}
+}
+
+type testLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *testLogger) log(level string, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := logEntry{level: level, msg: msg, args: map[string]interface{}{}}
+	for i := 0; i+1 < len(args); i += 2 {
+		entry.args[args[i].(string)] = args[i+1]
+	}
+	l.entries = append(l.entries, entry)
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("debug", msg, args) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("info", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("warn", msg, args) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("error", msg, args) }
+
+func (l *testLogger) String() string {
+	return fmt.Sprintf("%v", l.entries)
+}
+
+func TestCvpRac_Logging_UnitTest(t *testing.T) {
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "secret-session"})
+			fmt.Fprintf(w, `{ "sessionId": "secret-session" }`)
+			return
+		}
+		if r.URL.Path == "/web/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{ "sessionId": "secret-session", "name": "dev1" }`)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	logger := &testLogger{}
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		Debug(true), Logging(logger))
+	// the resty debug dump is replaced by the logger
+	assert(t, !cvpClient.Client.Debug, "Expected resty debug to be disabled")
+	ok(t, cvpClient.Connect("cvpadmin", "secret-password"))
+
+	params := &url.Values{"token": {"secret-token"}, "name": {"dev1"}}
+	_, err = cvpClient.Post("/test", params, map[string]string{"password": "secret-password"})
+	ok(t, err)
+	_, err = cvpClient.Get("/missing", nil)
+	assert(t, err != nil, "Expected error for missing path")
+
+	all := logger.String()
+	for _, secret := range []string{"secret-password", "secret-session", "secret-token"} {
+		assert(t, !strings.Contains(all, secret), "Secret %s logged: %s", secret, all)
+	}
+
+	equals(t, 3, len(logger.entries))
+	equals(t, "info", logger.entries[0].level)
+	equals(t, "CVP session created", logger.entries[0].msg)
+
+	entry := logger.entries[1]
+	equals(t, "debug", entry.level)
+	equals(t, "POST", entry.args["method"])
+	equals(t, "/test", entry.args["path"])
+	equals(t, host, entry.args["node"])
+	equals(t, 1, entry.args["attempt"])
+	equals(t, http.StatusOK, entry.args["status"])
+	equals(t, "name=dev1&token=%5BREDACTED%5D", entry.args["query"])
+	equals(t, `{"password":"[REDACTED]"}`, entry.args["request"])
+	_, found := entry.args["latency"]
+	assert(t, found, "Expected latency to be logged")
+
+	equals(t, "warn", logger.entries[2].level)
+	equals(t, http.StatusNotFound, logger.entries[2].args["status"])
+}
+
+func TestCvpRac_LoggingAPILogin_UnitTest(t *testing.T) {
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{ "sessionId": "secret-session" }`)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	logger := &testLogger{}
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		Debug(true), Logging(logger))
+	ok(t, cvpClient.Connect("cvpadmin", "secret-password"))
+
+	// API.Login sends its body as a string, which is logged quoted
+	_, err = cvpClient.API.Login("admin", "SuperSecret")
+	ok(t, err)
+	all := logger.String()
+	for _, secret := range []string{"SuperSecret", "secret-password", "secret-session"} {
+		assert(t, !strings.Contains(all, secret), "Secret %s logged: %s", secret, all)
+	}
+	entry := logger.entries[len(logger.entries)-1]
+	equals(t, "/login/authenticate.do", entry.args["path"])
+	equals(t, `"{\"userId\":\"admin\", \"password\":\"[REDACTED]\"}"`,
+		entry.args["request"])
+}
+
+func TestCvpRac_Redact_UnitTest(t *testing.T) {
+	tests := map[string]string{
+		`{"userId":"cvpadmin", "password":"p\"w"}`: `{"userId":"cvpadmin", ` +
+			`"password":"[REDACTED]"}`,
+		`{ "sessionId": "abc" }`:            `{ "sessionId": "[REDACTED]" }`,
+		`GET /x?access_token=abc&name=dev1`: `GET /x?access_token=[REDACTED]&name=dev1`,
+		`Cookie: session_id=abc; other=1`:   `Cookie: session_id=[REDACTED]; other=1`,
+		`Authorization: Bearer abc.def`:     `Authorization: Bearer [REDACTED]`,
+		`nothing secret here`:               `nothing secret here`,
+	}
+	for in, expected := range tests {
+		equals(t, expected, redact(in))
+	}
+}
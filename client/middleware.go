@@ -0,0 +1,211 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	resty "gopkg.in/resty.v1"
+)
+
+// AttemptReason tells why a request is being attempted
+type AttemptReason string
+
+// Reasons for attempting a request
+const (
+	AttemptFirst    AttemptReason = "first"
+	AttemptRelogin  AttemptReason = "relogin"
+	AttemptRetry    AttemptReason = "retry"
+	AttemptFailover AttemptReason = "failover"
+)
+
+// RequestInfo describes a request about to be sent to CVP. BeforeRequest
+// hooks may modify Context, Header, Query and Body; the changes are used for
+// the request.
+type RequestInfo struct {
+	Context context.Context
+	Method  string
+	// Path is the API path, relative to the base URL of the node
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   interface{}
+	// Host is the CVP node the request is sent to
+	Host string
+	// Attempt counts the attempts for this call, starting at 1, across
+	// retries, re-logins and failovers
+	Attempt int
+	// Reason is why this attempt is made: the first attempt, after logging
+	// in again, after a retryable status or after failing over to Host
+	Reason AttemptReason
+}
+
+// ResponseInfo describes the outcome of a request sent to CVP. Err is set
+// if no response was received. Body is nil for a successful response
+// streamed by Do.
+type ResponseInfo struct {
+	Status   int
+	Header   http.Header
+	Body     []byte
+	Duration time.Duration
+	Err      error
+}
+
+// Middleware observes or alters the requests made by a CvpClient. The logins
+// made by Connect and on session expiry aren't passed to middleware, but calls
+// such as API.Login are, so middleware must not expose request bodies.
+type Middleware interface {
+	// BeforeRequest is called before every attempt. Returning an error
+	// aborts the request with that error.
+	BeforeRequest(req *RequestInfo) error
+	// AfterResponse is called after every attempt, including failed ones.
+	AfterResponse(req *RequestInfo, resp *ResponseInfo)
+}
+
+// CallInfo describes an API call made through a CvpClient. A call is made
+// of one or more attempts, each passed to Middleware.
+type CallInfo struct {
+	// Context is the parent context of the attempts. BeforeCall may
+	// replace it, for example to start a span.
+	Context  context.Context
+	Method   string
+	Path     string
+	Attempts int
+	Duration time.Duration
+	Err      error
+}
+
+// CallMiddleware is a Middleware that is also told about whole calls
+type CallMiddleware interface {
+	Middleware
+	// BeforeCall is called once before the first attempt. Returning an
+	// error aborts the call with that error.
+	BeforeCall(call *CallInfo) error
+	// AfterCall is called once the call has completed
+	AfterCall(call *CallInfo)
+}
+
+// Hooks is a Middleware made of optional functions
+type Hooks struct {
+	Before func(req *RequestInfo) error
+	After  func(req *RequestInfo, resp *ResponseInfo)
+}
+
+// BeforeRequest implements Middleware
+func (h Hooks) BeforeRequest(req *RequestInfo) error {
+	if h.Before == nil {
+		return nil
+	}
+	return h.Before(req)
+}
+
+// AfterResponse implements Middleware
+func (h Hooks) AfterResponse(req *RequestInfo, resp *ResponseInfo) {
+	if h.After != nil {
+		h.After(req, resp)
+	}
+}
+
+type middlewareChain []Middleware
+
+// before runs the BeforeRequest hooks in order
+func (m middlewareChain) before(req *RequestInfo) error {
+	for _, mw := range m {
+		if err := mw.BeforeRequest(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// beforeCall runs the BeforeCall hooks in order
+func (m middlewareChain) beforeCall(call *CallInfo) error {
+	for _, mw := range m {
+		if cm, ok := mw.(CallMiddleware); ok {
+			if err := cm.BeforeCall(call); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// afterCall runs the AfterCall hooks in reverse order
+func (m middlewareChain) afterCall(call *CallInfo) {
+	for i := len(m) - 1; i >= 0; i-- {
+		if cm, ok := m[i].(CallMiddleware); ok {
+			cm.AfterCall(call)
+		}
+	}
+}
+
+// after runs the AfterResponse hooks in reverse order so the first
+// middleware wraps all the others
+func (m middlewareChain) after(req *RequestInfo, resp *ResponseInfo) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].AfterResponse(req, resp)
+	}
+}
+
+func newResponseInfo(resp *resty.Response, body []byte, err error,
+	duration time.Duration) *ResponseInfo {
+	info := &ResponseInfo{Duration: duration, Err: err, Body: body}
+	if resp != nil && resp.RawResponse != nil {
+		info.Status = resp.StatusCode()
+		info.Header = resp.Header()
+	}
+	return info
+}
+
+// cloneHeader returns a copy of header that middleware can safely modify
+func cloneHeader(header http.Header) http.Header {
+	clone := http.Header{}
+	for k, v := range header {
+		clone[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
+// cloneValues returns a copy of params that middleware can safely modify
+func cloneValues(params *url.Values) url.Values {
+	values := url.Values{}
+	if params == nil {
+		return values
+	}
+	for k, v := range *params {
+		values[k] = append([]string(nil), v...)
+	}
+	return values
+}
@@ -0,0 +1,201 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCvpRac_Middleware_UnitTest(t *testing.T) {
+	var hits int32
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		// first request fails to check hooks see every attempt
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, `{ "id": "%s", "query": "%s", "body": %s }`,
+			r.Header.Get("X-Request-Id"), r.URL.RawQuery, body)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	var calls []string
+	var statuses []int
+	var attempts []int
+	var reasons []AttemptReason
+	first := Hooks{
+		Before: func(req *RequestInfo) error {
+			calls = append(calls, "before1")
+			attempts = append(attempts, req.Attempt)
+			reasons = append(reasons, req.Reason)
+			equals(t, "POST", req.Method)
+			equals(t, "/test", req.Path)
+			equals(t, host, req.Host)
+			req.Header.Set("X-Request-Id", "abc")
+			req.Query.Set("added", "yes")
+			return nil
+		},
+		After: func(req *RequestInfo, resp *ResponseInfo) {
+			calls = append(calls, "after1")
+			statuses = append(statuses, resp.Status)
+		},
+	}
+	second := Hooks{
+		Before: func(req *RequestInfo) error {
+			calls = append(calls, "before2")
+			req.Body = map[string]string{"replaced": "true"}
+			return nil
+		},
+		After: func(req *RequestInfo, resp *ResponseInfo) {
+			calls = append(calls, "after2")
+		},
+	}
+
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		Middlewares(first, second))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	params := &url.Values{"key": {"value"}}
+	data, err := cvpClient.Post("/test", params, map[string]string{"orig": "true"})
+	ok(t, err)
+	equals(t, `{ "id": "abc", "query": "added=yes&key=value", "body": {"replaced":"true"} }`,
+		string(data))
+	equals(t, []string{"before1", "before2", "after2", "after1",
+		"before1", "before2", "after2", "after1"}, calls)
+	equals(t, []int{http.StatusUnauthorized, http.StatusOK}, statuses)
+	equals(t, []int{1, 2}, attempts)
+	equals(t, []AttemptReason{AttemptFirst, AttemptRelogin}, reasons)
+	// the caller's parameters aren't modified
+	equals(t, &url.Values{"key": {"value"}}, params)
+}
+
+func TestCvpRac_MiddlewareAbort_UnitTest(t *testing.T) {
+	var hits int32
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/web/login/authenticate.do" {
+			atomic.AddInt32(&hits, 1)
+		}
+		fmt.Fprintf(w, `{ "sessionId": "1" }`)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+	ok(t, cvpClient.Use(Hooks{Before: func(req *RequestInfo) error {
+		return errors.New("blocked")
+	}}))
+
+	_, err = cvpClient.Get("/test", nil)
+	assert(t, err != nil && strings.Contains(err.Error(), "blocked"),
+		"Expected middleware error, got: %v", err)
+	equals(t, int32(0), atomic.LoadInt32(&hits))
+
+	_, err = NewCvpClient(Middlewares(nil))
+	assert(t, err != nil, "Expected error for nil middleware")
+}
+
+type callRecorder struct {
+	Hooks
+	calls []*CallInfo
+	ctxOK bool
+}
+
+type ctxKey struct{}
+
+func (r *callRecorder) BeforeCall(call *CallInfo) error {
+	call.Context = context.WithValue(call.Context, ctxKey{}, "call")
+	return nil
+}
+
+func (r *callRecorder) AfterCall(call *CallInfo) {
+	r.calls = append(r.calls, call)
+}
+
+func TestCvpRac_CallMiddleware_UnitTest(t *testing.T) {
+	var hits int32
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, `{}`)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	recorder := &callRecorder{}
+	recorder.Before = func(req *RequestInfo) error {
+		// attempts run under the context set by BeforeCall
+		recorder.ctxOK = req.Context.Value(ctxKey{}) == "call"
+		return nil
+	}
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		Retry(fastRetryPolicy()), Middlewares(recorder))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	_, err = cvpClient.Get("/test", nil)
+	ok(t, err)
+	assert(t, recorder.ctxOK, "Expected attempt context derived from call context")
+	equals(t, 1, len(recorder.calls))
+	call := recorder.calls[0]
+	equals(t, "GET", call.Method)
+	equals(t, "/test", call.Path)
+	equals(t, 2, call.Attempts)
+	ok(t, call.Err)
+	assert(t, call.Duration > 0, "Expected call duration")
+}
@@ -0,0 +1,162 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type sessionServer struct {
+	*httptest.Server
+	valid int32
+}
+
+// createSessionServer returns a server that issues a new session on every
+// login and only accepts requests carrying the latest session.
+func createSessionServer(t *testing.T, logins *int32) *sessionServer {
+	ss := &sessionServer{}
+	ss.Server = createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			session := strconv.Itoa(int(atomic.AddInt32(logins, 1)))
+			atomic.StoreInt32(&ss.valid, atomic.LoadInt32(logins))
+			http.SetCookie(w, &http.Cookie{Name: "session_id", Value: session})
+			fmt.Fprintf(w, `{ "sessionId": "%s" }`, session)
+			return
+		}
+		cookie, err := r.Cookie("session_id")
+		if err != nil || cookie.Value != strconv.Itoa(int(atomic.LoadInt32(&ss.valid))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{ "message": "Accepted" }`)
+	})
+	return ss
+}
+
+func TestCvpRac_ClientConcurrentRelogin_RaceTest(t *testing.T) {
+	var logins int32
+
+	ts := createSessionServer(t, &logins)
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(host, host, host),
+		Port(port),
+		Debug(*debugFlag))
+
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	runConcurrent := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := cvpClient.Get("/test", nil); err != nil {
+					t.Errorf("Concurrent GET: %s", err)
+				}
+				_ = cvpClient.GetSessionID()
+			}()
+		}
+		wg.Wait()
+	}
+
+	runConcurrent()
+	equals(t, int32(1), atomic.LoadInt32(&logins))
+
+	// Expire the session. All in-flight requests see a 401 but only one of
+	// them should log in again, the others reuse the new session.
+	atomic.StoreInt32(&ts.valid, 0)
+	runConcurrent()
+	equals(t, int32(2), atomic.LoadInt32(&logins))
+	equals(t, "2", cvpClient.GetSessionID())
+}
+
+func TestCvpRac_ClientRateLimitChange_RaceTest(t *testing.T) {
+	var logins int32
+
+	ts := createSessionServer(t, &logins)
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	cvpClient, _ := NewCvpClient(
+		Protocol("http"),
+		Hosts(host),
+		Port(port),
+		Debug(*debugFlag))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	ok(t, cvpClient.SetRateLimit(ReadEndpoints, Limit{MaxInFlight: 10}))
+
+	// limits change while requests are being sent
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if _, err := cvpClient.Get("/test", nil); err != nil {
+					t.Errorf("Concurrent GET: %s", err)
+				}
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for i := 0; ; i++ {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		class := EndpointClass(fmt.Sprintf("class%d", i))
+		ok(t, cvpClient.SetRateLimit(class, Limit{MaxInFlight: 1}))
+		ok(t, cvpClient.SetRateLimit(ReadEndpoints, Limit{MaxInFlight: 10 + i%10}))
+	}
+}
@@ -0,0 +1,194 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	resty "gopkg.in/resty.v1"
+)
+
+// RetryPolicy controls how a request is retried when CVP returns an error
+// status.
+//
+// A 401 is retried on the same node after logging in again, and a 301 is
+// retried on another node, as the request was not processed in either case.
+// Statuses in RetryableStatus are retried on the same node after a backoff
+// and then on the other nodes. Any other error status is retried on the
+// other nodes. Requests that aren't idempotent (POST, PATCH) are only
+// retried for 401/301 unless RetryNonIdempotent is set, as CVP may already
+// have acted on them.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts per node. Zero uses
+	// NumRetryRequests.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry on the same node.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between retries on the same node.
+	MaxBackoff time.Duration
+	// Multiplier is applied to the backoff after every retry.
+	Multiplier float64
+	// Jitter randomizes each backoff by +/- this fraction (0 to 1).
+	Jitter float64
+	// RetryableStatus are the statuses retried on the same node. A
+	// Retry-After header on a 429 or 503 response overrides the backoff.
+	RetryableStatus []int
+	// MaxRetryAfter caps the delay asked for by a Retry-After header. Zero
+	// caps it at MaxBackoff.
+	MaxRetryAfter time.Duration
+	// RetryNonIdempotent allows POST and PATCH requests to be retried or
+	// replayed on another node after an error status.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used unless overridden with the
+// Retry option.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		MaxRetryAfter:  30 * time.Second,
+		RetryableStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (p *RetryPolicy) validate() error {
+	if p.MaxAttempts < 0 {
+		return errors.New("MaxAttempts must be >= 0")
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 || p.MaxRetryAfter < 0 {
+		return errors.New("Backoff must be >= 0")
+	}
+	if p.Multiplier != 0 && p.Multiplier < 1 {
+		return errors.New("Multiplier must be >= 1")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return errors.New("Jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+	return NumRetryRequests
+}
+
+func (p *RetryPolicy) retryable(status int) bool {
+	for _, s := range p.RetryableStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before retrying after the given attempt
+// (starting at 1) failed with resp.
+func (p *RetryPolicy) backoff(attempt int, resp *resty.Response) time.Duration {
+	status := resp.StatusCode()
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		if delay, ok := retryAfter(resp.Header().Get("Retry-After")); ok {
+			limit := p.MaxRetryAfter
+			if limit == 0 {
+				limit = p.MaxBackoff
+			}
+			if limit > 0 && delay > limit {
+				delay = limit
+			}
+			return delay
+		}
+	}
+
+	multiplier := p.Multiplier
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil && secs >= 0 {
+		if secs > int64(math.MaxInt64/time.Second) {
+			return time.Duration(math.MaxInt64), true
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleepCtx waits for delay or until ctx is done
+func sleepCtx(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
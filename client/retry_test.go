@@ -0,0 +1,216 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	resty "gopkg.in/resty.v1"
+)
+
+// createStatusServer returns a server that answers the first len(statuses)
+// requests to /test with the given statuses and 200 afterwards.
+func createStatusServer(hits *int32, retryAfter string, statuses ...int) (string, int, func()) {
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		hit := int(atomic.AddInt32(hits, 1))
+		if hit <= len(statuses) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(statuses[hit-1])
+			return
+		}
+		fmt.Fprintf(w, `{ "message": "Accepted" }`)
+	})
+	host, port, _ := parseURL(ts.URL)
+	return host, port, ts.Close
+}
+
+func fastRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 10 * time.Millisecond
+	return policy
+}
+
+func TestCvpRac_RetryTransientStatus_UnitTest(t *testing.T) {
+	var hits int32
+	host, port, closeFn := createStatusServer(&hits, "",
+		http.StatusServiceUnavailable, http.StatusBadGateway)
+	defer closeFn()
+
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		Retry(fastRetryPolicy()))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	_, err := cvpClient.Get("/test", nil)
+	ok(t, err)
+	equals(t, int32(3), atomic.LoadInt32(&hits))
+}
+
+func TestCvpRac_RetryMaxAttempts_UnitTest(t *testing.T) {
+	var hits int32
+	host, port, closeFn := createStatusServer(&hits, "",
+		http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout)
+	defer closeFn()
+
+	policy := fastRetryPolicy()
+	policy.MaxAttempts = 2
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port), Retry(policy))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	_, err := cvpClient.Get("/test", nil)
+	assert(t, err != nil, "Expected error after MaxAttempts")
+	equals(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestCvpRac_RetryAfter_UnitTest(t *testing.T) {
+	var hits int32
+	host, port, closeFn := createStatusServer(&hits, "1", http.StatusTooManyRequests)
+	defer closeFn()
+
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		Retry(fastRetryPolicy()))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	start := time.Now()
+	_, err := cvpClient.Get("/test", nil)
+	ok(t, err)
+	assert(t, time.Since(start) >= time.Second, "Retry-After not honored")
+	equals(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestCvpRac_RetryNonIdempotent_UnitTest(t *testing.T) {
+	var hits int32
+	host, port, closeFn := createStatusServer(&hits, "",
+		http.StatusInternalServerError, http.StatusServiceUnavailable)
+	defer closeFn()
+
+	// POSTs are neither replayed on another node nor retried by default
+	cvpClient, _ := NewCvpClient(Protocol("http"), Hosts(host, host, host), Port(port),
+		Retry(fastRetryPolicy()))
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+
+	_, err := cvpClient.Post("/test", nil, nil)
+	assert(t, err != nil, "Expected error for POST")
+	assert(t, strings.Contains(err.Error(), "Status [500]"), "Unexpected error: %s", err)
+	equals(t, int32(1), atomic.LoadInt32(&hits))
+
+	_, err = cvpClient.Post("/test", nil, nil)
+	assert(t, err != nil, "Expected error for POST")
+	equals(t, int32(2), atomic.LoadInt32(&hits))
+
+	// Unless the policy allows it
+	atomic.StoreInt32(&hits, 0)
+	policy := fastRetryPolicy()
+	policy.RetryNonIdempotent = true
+	ok(t, cvpClient.SetRetryPolicy(policy))
+
+	_, err = cvpClient.Post("/test", nil, nil)
+	ok(t, err)
+	equals(t, int32(3), atomic.LoadInt32(&hits))
+}
+
+func TestCvpRac_RetryPolicyValidate_UnitTest(t *testing.T) {
+	invalid := []RetryPolicy{
+		{MaxAttempts: -1},
+		{InitialBackoff: -time.Second},
+		{Multiplier: 0.5},
+		{Jitter: 2},
+	}
+	for _, policy := range invalid {
+		_, err := NewCvpClient(Retry(policy))
+		assert(t, err != nil, "Expected error for policy %+v", policy)
+	}
+}
+
+func TestCvpRac_RetryBackoff_UnitTest(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	resp := &resty.Response{RawResponse: &http.Response{StatusCode: http.StatusBadGateway}}
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+	}
+	for i, delay := range expected {
+		equals(t, delay, policy.backoff(i+1, resp))
+	}
+
+	// Jitter stays within the configured fraction
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(1, resp)
+		assert(t, delay >= 50*time.Millisecond && delay <= 150*time.Millisecond,
+			"Backoff %s out of range", delay)
+	}
+
+	delay, found := retryAfter("3")
+	assert(t, found, "Expected Retry-After seconds to parse")
+	equals(t, 3*time.Second, delay)
+	_, found = retryAfter("soon")
+	assert(t, !found, "Expected invalid Retry-After to be ignored")
+}
+
+func TestCvpRac_RetryAfterCap_UnitTest(t *testing.T) {
+	resp := &resty.Response{RawResponse: &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": []string{"86400"}},
+	}}
+
+	// a large Retry-After is capped at MaxRetryAfter
+	policy := DefaultRetryPolicy()
+	equals(t, 30*time.Second, policy.backoff(1, resp))
+
+	// or at MaxBackoff when MaxRetryAfter is not set
+	policy.MaxRetryAfter = 0
+	equals(t, 5*time.Second, policy.backoff(1, resp))
+
+	// and doesn't overflow when nothing caps it
+	policy.MaxBackoff = 0
+	resp.RawResponse.Header.Set("Retry-After", "99999999999999999")
+	assert(t, policy.backoff(1, resp) > 0, "Expected Retry-After not to overflow")
+}
@@ -0,0 +1,201 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultSessionTTL is how long a cached session is reused. CVP expires
+// sessions after 12 hours of inactivity.
+const DefaultSessionTTL = 12 * time.Hour
+
+// sessionCache is where the session is saved, see SessionCache
+type sessionCache struct {
+	path string
+	ttl  time.Duration
+}
+
+// cachedSession is the session saved to the cache file
+type cachedSession struct {
+	URL       string         `json:"url"`
+	Host      string         `json:"host"`
+	Username  string         `json:"username"`
+	SessionID string         `json:"sessionId"`
+	Cookies   []*http.Cookie `json:"cookies"`
+	Expiry    time.Time      `json:"expiry"`
+}
+
+// SessionCache saves the session to path, readable only by the owner, after
+// every login. Connect resumes the saved session if it was created for the
+// same user and node, hasn't expired and is still accepted by CVP.
+// Otherwise it logs in as usual. ttl is how long a saved session is reused,
+// zero uses DefaultSessionTTL. Sessions using tokens aren't saved.
+func SessionCache(path string, ttl time.Duration) Option {
+	return func(c *CvpClient) error {
+		if path == "" {
+			return errors.New("SessionCache: path required")
+		}
+		if ttl <= 0 {
+			ttl = DefaultSessionTTL
+		}
+		c.cache = &sessionCache{path: path, ttl: ttl}
+		return nil
+	}
+}
+
+// resumeSession restores the session saved in the cache and checks that
+// CVP still accepts it. Must be called with c.mu held.
+func (c *CvpClient) resumeSession(ctx context.Context) error {
+	creds, err := c.creds.Credentials(ctx)
+	if err != nil {
+		return errors.Wrap(err, "resumeSession")
+	}
+	if creds.Token != "" {
+		return errors.New("resumeSession: Token sessions aren't cached")
+	}
+
+	info, err := os.Stat(c.cache.path)
+	if err != nil {
+		return errors.Wrap(err, "resumeSession")
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return errors.Errorf("resumeSession: %s is accessible by other users", c.cache.path)
+	}
+	data, err := ioutil.ReadFile(c.cache.path)
+	if err != nil {
+		return errors.Wrap(err, "resumeSession")
+	}
+	var session cachedSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return errors.Wrap(err, "resumeSession")
+	}
+
+	switch {
+	case time.Now().After(session.Expiry):
+		return errors.New("resumeSession: Session expired")
+	case session.Username != creds.Username:
+		return errors.New("resumeSession: Session is for another user")
+	case !c.knownHost(session.Host) || session.URL != c.hostURL(session.Host):
+		return errors.New("resumeSession: Session is for another node")
+	}
+
+	c.initSession(session.Host)
+	c.tokenAuth = false
+	c.SessID = session.SessionID
+	c.Client.SetCookies(session.Cookies)
+
+	// a cheap call to check the session is still valid
+	resp, err := c.Client.R().SetContext(ctx).Get("/cvpInfo/getCvpInfo.do")
+	if err == nil {
+		err = checkResponse(resp)
+	}
+	if err != nil {
+		c.SessID = ""
+		return errors.Wrap(err, "resumeSession")
+	}
+	c.host = session.Host
+	c.trackSession()
+	c.selector.Report(session.Host, nil)
+	return nil
+}
+
+// saveSession writes the current session to the cache. Must be called with
+// c.mu held.
+func (c *CvpClient) saveSession(ctx context.Context) error {
+	if c.tokenAuth {
+		return nil
+	}
+	creds, err := c.creds.Credentials(ctx)
+	if err != nil {
+		return errors.Wrap(err, "saveSession")
+	}
+	data, err := json.Marshal(&cachedSession{
+		URL:       c.url,
+		Host:      c.host,
+		Username:  creds.Username,
+		SessionID: c.SessID,
+		Cookies:   c.Client.Cookies,
+		Expiry:    time.Now().Add(c.cache.ttl),
+	})
+	if err != nil {
+		return errors.Wrap(err, "saveSession")
+	}
+
+	// write to a temporary file first so the cache is never partially
+	// written
+	tmp, err := ioutil.TempFile(filepath.Dir(c.cache.path), ".cvpsession")
+	if err != nil {
+		return errors.Wrap(err, "saveSession")
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "saveSession")
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "saveSession")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "saveSession")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), c.cache.path), "saveSession")
+}
+
+// cacheSession saves the session if caching is enabled, logging failures
+// as they don't affect the session itself
+func (c *CvpClient) cacheSession(ctx context.Context) {
+	if c.cache == nil {
+		return
+	}
+	if err := c.saveSession(ctx); err != nil {
+		c.logWarn("Unable to cache CVP session", "error", err)
+	}
+}
+
+func (c *CvpClient) knownHost(host string) bool {
+	for _, h := range c.Hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
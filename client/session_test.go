@@ -0,0 +1,134 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCvpRac_SessionCache_UnitTest(t *testing.T) {
+	var logins, valid int32
+
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			session := strconv.Itoa(int(atomic.AddInt32(&logins, 1)))
+			atomic.StoreInt32(&valid, atomic.LoadInt32(&logins))
+			http.SetCookie(w, &http.Cookie{Name: "session_id", Value: session})
+			fmt.Fprintf(w, `{ "sessionId": "%s" }`, session)
+			return
+		}
+		cookie, err := r.Cookie("session_id")
+		if err != nil || cookie.Value != strconv.Itoa(int(atomic.LoadInt32(&valid))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{ "version": "2020.1.0" }`)
+	})
+	defer ts.Close()
+
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	dir, err := ioutil.TempDir("", "cvprac")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "session.json")
+
+	connect := func(user string, ttl time.Duration) *CvpClient {
+		cvpClient, err := NewCvpClient(
+			Protocol("http"),
+			Hosts(host),
+			Port(port),
+			SessionCache(path, ttl),
+			Debug(*debugFlag))
+		ok(t, err)
+		ok(t, cvpClient.Connect(user, "cvp123"))
+		_, err = cvpClient.Get("/cvpInfo/getCvpInfo.do", nil)
+		ok(t, err)
+		return cvpClient
+	}
+
+	connect("cvpadmin", 0)
+	equals(t, int32(1), atomic.LoadInt32(&logins))
+
+	info, err := os.Stat(path)
+	ok(t, err)
+	equals(t, os.FileMode(0600), info.Mode().Perm())
+	data, err := ioutil.ReadFile(path)
+	ok(t, err)
+	assert(t, !strings.Contains(string(data), "cvp123"), "Password cached: %s", data)
+
+	// the cached session is resumed
+	cvpClient := connect("cvpadmin", 0)
+	equals(t, int32(1), atomic.LoadInt32(&logins))
+	equals(t, "1", cvpClient.GetSessionID())
+
+	// but not for another user
+	connect("other", 0)
+	equals(t, int32(2), atomic.LoadInt32(&logins))
+
+	// nor once it's rejected by CVP
+	atomic.StoreInt32(&valid, 0)
+	connect("cvpadmin", 0)
+	equals(t, int32(3), atomic.LoadInt32(&logins))
+
+	// nor if other users can read it
+	ok(t, os.Chmod(path, 0644))
+	connect("cvpadmin", 0)
+	equals(t, int32(4), atomic.LoadInt32(&logins))
+
+	// nor once expired
+	atomic.StoreInt32(&valid, 0)
+	connect("cvpadmin", time.Nanosecond)
+	equals(t, int32(5), atomic.LoadInt32(&logins))
+	connect("cvpadmin", 0)
+	equals(t, int32(6), atomic.LoadInt32(&logins))
+
+	// a corrupt cache falls back to logging in
+	ok(t, ioutil.WriteFile(path, []byte("garbage"), 0600))
+	connect("cvpadmin", 0)
+	equals(t, int32(7), atomic.LoadInt32(&logins))
+
+	_, err = NewCvpClient(SessionCache("", 0))
+	assert(t, err != nil, "Expected error for empty path")
+}
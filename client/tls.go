@@ -0,0 +1,113 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// tlsOptions holds the TLS settings used for https connections to CVP
+type tlsOptions struct {
+	insecure     bool
+	rootCAs      *x509.CertPool
+	serverName   string
+	certs        []tls.Certificate
+	fingerprints [][]byte
+}
+
+// set reports whether any of the options is set
+func (t *tlsOptions) set() bool {
+	return t.insecure || t.rootCAs != nil || t.serverName != "" || len(t.certs) > 0 ||
+		len(t.fingerprints) > 0
+}
+
+// tlsConfig builds the tls.Config for this Client from its TLS options
+func (c *CvpClient) tlsConfig() *tls.Config {
+	cfg := &tls.Config{
+		InsecureSkipVerify: c.tlsOpts.insecure,
+		RootCAs:            c.tlsOpts.rootCAs,
+		ServerName:         c.tlsOpts.serverName,
+		Certificates:       c.tlsOpts.certs,
+	}
+	if len(c.tlsOpts.fingerprints) > 0 {
+		fingerprints := c.tlsOpts.fingerprints
+		cfg.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyFingerprint(rawCerts, fingerprints)
+		}
+	}
+	return cfg
+}
+
+func verifyFingerprint(rawCerts [][]byte, fingerprints [][]byte) error {
+	if len(rawCerts) == 0 {
+		return errors.New("verifyFingerprint: No server certificate")
+	}
+	sum := sha256.Sum256(rawCerts[0])
+	for _, fp := range fingerprints {
+		if bytes.Equal(sum[:], fp) {
+			return nil
+		}
+	}
+	return errors.Errorf("verifyFingerprint: Server certificate fingerprint [%x] not pinned",
+		sum)
+}
+
+func parseFingerprint(fingerprint string) ([]byte, error) {
+	fp, err := hex.DecodeString(strings.Replace(fingerprint, ":", "", -1))
+	if err != nil {
+		return nil, err
+	}
+	if len(fp) != sha256.Size {
+		return nil, errors.Errorf("Invalid SHA-256 fingerprint [%s]", fingerprint)
+	}
+	return fp, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("No certificates found in [%s]", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,119 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func createTLSServer(t *testing.T) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		t.Logf("Method: %v Path: %v", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{ "message": "Accepted" }`)
+	}))
+}
+
+func connectTLS(t *testing.T, ts *httptest.Server, options ...Option) error {
+	host, port, err := parseURL(ts.URL)
+	if err != nil {
+		t.Fatalf("Parsing test server URL: %s", err)
+	}
+
+	options = append([]Option{Protocol("https"), Hosts(host), Port(port),
+		Debug(*debugFlag)}, options...)
+	cvpClient, err := NewCvpClient(options...)
+	ok(t, err)
+
+	return cvpClient.Connect("cvpadmin", "cvp123")
+}
+
+func TestCvpRac_TLSVerifyDefault_UnitTest(t *testing.T) {
+	ts := createTLSServer(t)
+	defer ts.Close()
+
+	err := connectTLS(t, ts)
+	assert(t, err != nil, "Connect to untrusted server should fail by default")
+}
+
+func TestCvpRac_TLSInsecure_UnitTest(t *testing.T) {
+	ts := createTLSServer(t)
+	defer ts.Close()
+
+	ok(t, connectTLS(t, ts, InsecureSkipVerify(true)))
+}
+
+func TestCvpRac_TLSRootCAs_UnitTest(t *testing.T) {
+	ts := createTLSServer(t)
+	defer ts.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+
+	ok(t, connectTLS(t, ts, RootCAs(pool)))
+	ok(t, connectTLS(t, ts, RootCAs(pool), ServerName("example.com")))
+
+	err := connectTLS(t, ts, RootCAs(pool), ServerName("bogus.example.net"))
+	assert(t, err != nil, "Connect with mismatched ServerName should fail")
+
+	_, err = NewCvpClient(RootCAs(nil))
+	assert(t, err != nil, "Nil pool should return error")
+	_, err = NewCvpClient(RootCAFile("/nonexistent/ca.pem"))
+	assert(t, err != nil, "Missing CA file should return error")
+}
+
+func TestCvpRac_TLSPinCertificate_UnitTest(t *testing.T) {
+	ts := createTLSServer(t)
+	defer ts.Close()
+
+	sum := sha256.Sum256(ts.Certificate().Raw)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	ok(t, connectTLS(t, ts, InsecureSkipVerify(true), PinCertificate(fingerprint)))
+
+	wrong := sha256.Sum256([]byte("bogus"))
+	err := connectTLS(t, ts, InsecureSkipVerify(true),
+		PinCertificate(hex.EncodeToString(wrong[:])))
+	assert(t, err != nil, "Connect with wrong pinned fingerprint should fail")
+
+	_, err = NewCvpClient(PinCertificate("not-hex"))
+	assert(t, err != nil, "Invalid fingerprint should return error")
+	_, err = NewCvpClient(PinCertificate("ab:cd"))
+	assert(t, err != nil, "Short fingerprint should return error")
+}
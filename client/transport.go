@@ -0,0 +1,285 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DialFunc opens the network connections to CVP, see net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network string, addr string) (net.Conn, error)
+
+// netOptions holds the proxy and dialer settings used for connections to CVP
+type netOptions struct {
+	proxySet  bool
+	proxy     *url.URL
+	noProxy   []string
+	dial      DialFunc
+	localAddr *net.TCPAddr
+}
+
+// set reports whether any of the options is set
+func (n *netOptions) set() bool {
+	return n.proxySet || n.dial != nil || n.localAddr != nil
+}
+
+// Proxy sends the requests to CVP through proxyURL, e.g.
+// "http://proxy:3128" for an HTTP CONNECT proxy or "socks5://jump:1080".
+// Hosts matching a noProxy entry are connected to directly. Entries follow
+// the NO_PROXY conventions: "*", IP addresses, CIDR ranges and domain names
+// matching themselves and their subdomains, optionally with a port. An
+// empty proxyURL connects directly to every host. Without this option the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+func Proxy(proxyURL string, noProxy ...string) Option {
+	return func(c *CvpClient) error {
+		var proxy *url.URL
+		if proxyURL != "" {
+			var err error
+			if proxy, err = url.Parse(proxyURL); err != nil {
+				return errors.Wrap(err, "Proxy")
+			}
+			switch proxy.Scheme {
+			case "http", "https", "socks5":
+			default:
+				return errors.Errorf("Proxy: Invalid proxy scheme [%s]", proxy.Scheme)
+			}
+			if proxy.Host == "" {
+				return errors.Errorf("Proxy: No host in proxy URL [%s]", proxyURL)
+			}
+		}
+		var patterns []string
+		for _, entry := range noProxy {
+			for _, pattern := range strings.Split(entry, ",") {
+				if pattern = strings.TrimSpace(pattern); pattern != "" {
+					patterns = append(patterns, strings.ToLower(pattern))
+				}
+			}
+		}
+		c.netOpts.proxySet = true
+		c.netOpts.proxy = proxy
+		c.netOpts.noProxy = patterns
+		return c.applyTransport()
+	}
+}
+
+// DialContext sets the function opening the connections to CVP, e.g. to
+// go through a jump host. Connections to a proxy are opened with it too.
+func DialContext(dial DialFunc) Option {
+	return func(c *CvpClient) error {
+		if dial == nil {
+			return errors.New("DialContext: nil dial function")
+		}
+		if c.netOpts.localAddr != nil {
+			return errors.New("DialContext: Not supported with SourceAddress")
+		}
+		c.netOpts.dial = dial
+		return c.applyTransport()
+	}
+}
+
+// SourceAddress binds the connections to CVP to a local address, given as
+// an IP address or as the name of an interface whose first address is used.
+func SourceAddress(addr string) Option {
+	return func(c *CvpClient) error {
+		if c.netOpts.dial != nil {
+			return errors.New("SourceAddress: Not supported with DialContext")
+		}
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			var err error
+			if ip, err = interfaceIP(addr); err != nil {
+				return errors.Wrap(err, "SourceAddress")
+			}
+		}
+		c.netOpts.localAddr = &net.TCPAddr{IP: ip}
+		return c.applyTransport()
+	}
+}
+
+// SetProxy sets the proxy used to connect to CVP, see Proxy
+func (c *CvpClient) SetProxy(proxyURL string, noProxy ...string) error {
+	return c.SetOption(Proxy(proxyURL, noProxy...))
+}
+
+// SetDialContext sets the function opening the connections to CVP
+func (c *CvpClient) SetDialContext(dial DialFunc) error {
+	return c.SetOption(DialContext(dial))
+}
+
+// SetSourceAddress binds the connections to CVP to a local address
+func (c *CvpClient) SetSourceAddress(addr string) error {
+	return c.SetOption(SourceAddress(addr))
+}
+
+// interfaceIP returns the first address of the named interface, preferring
+// IPv4
+func interfaceIP(name string) (net.IP, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	var found net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+		if found == nil {
+			found = ipNet.IP
+		}
+	}
+	if found == nil {
+		return nil, errors.Errorf("No address on interface [%s]", name)
+	}
+	return found, nil
+}
+
+// newTransport builds the RoundTripper of the sessions to CVP. The TLS,
+// proxy and dialer options are applied to a copy of the Transport option,
+// or of http.DefaultTransport, leaving the caller's transport untouched.
+// Any other RoundTripper handles its own connections and is used as is.
+func (c *CvpClient) newTransport() http.RoundTripper {
+	base, ok := c.Transport.(*http.Transport)
+	if c.Transport != nil && !ok {
+		return c.Transport
+	}
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	transport := base.Clone()
+	if c.Protocol == "https" {
+		transport.TLSClientConfig = c.tlsConfig()
+	}
+	if c.netOpts.proxySet {
+		transport.Proxy = c.netOpts.proxyFunc()
+	}
+	if c.netOpts.dial != nil {
+		transport.DialContext = c.netOpts.dial
+	} else if c.netOpts.localAddr != nil {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			LocalAddr: c.netOpts.localAddr,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	return transport
+}
+
+// applyTransport updates the transport of an already initialized session
+func (c *CvpClient) applyTransport() error {
+	// a custom RoundTripper would silently ignore them, e.g. a pinned
+	// certificate
+	if _, ok := c.Transport.(*http.Transport); c.Transport != nil && !ok {
+		if c.netOpts.set() {
+			return errors.New("Proxy and dialer options require an *http.Transport")
+		}
+		if c.tlsOpts.set() {
+			return errors.New("TLS options require an *http.Transport")
+		}
+	}
+	c.roundTripper = nil
+	if c.Client != nil {
+		c.roundTripper = c.newTransport()
+		c.Client.SetTransport(c.roundTripper)
+	}
+	return nil
+}
+
+// proxyFunc returns the Proxy function of the transport
+func (n *netOptions) proxyFunc() func(*http.Request) (*url.URL, error) {
+	if n.proxy == nil {
+		return nil
+	}
+	proxy, noProxy := n.proxy, n.noProxy
+	return func(req *http.Request) (*url.URL, error) {
+		port := req.URL.Port()
+		if port == "" {
+			port = "80"
+			if req.URL.Scheme == "https" {
+				port = "443"
+			}
+		}
+		if bypassProxy(noProxy, strings.ToLower(req.URL.Hostname()), port) {
+			return nil, nil
+		}
+		return proxy, nil
+	}
+}
+
+// bypassProxy reports whether host:port matches one of the NO_PROXY patterns
+func bypassProxy(patterns []string, host string, port string) bool {
+	ip := net.ParseIP(host)
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(pattern); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		patternHost, patternPort, err := net.SplitHostPort(pattern)
+		if err != nil {
+			patternHost, patternPort = pattern, ""
+		}
+		if patternPort != "" && patternPort != port {
+			continue
+		}
+		if patternIP := net.ParseIP(patternHost); patternIP != nil {
+			if ip != nil && patternIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		domain := strings.TrimPrefix(strings.TrimPrefix(patternHost, "*"), ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// createProxyServer returns an HTTP proxy forwarding plain requests and
+// tunneling CONNECT requests, counting the requests it handles.
+func createProxyServer(hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if r.Method == http.MethodConnect {
+			target, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			go func() {
+				io.Copy(target, conn)
+				target.Close()
+			}()
+			io.Copy(conn, target)
+			conn.Close()
+			return
+		}
+		r.RequestURI = ""
+		resp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+}
+
+func TestCvpRac_TransportProxy_UnitTest(t *testing.T) {
+	var hits int32
+	proxy := createProxyServer(&hits)
+	defer proxy.Close()
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{ "sessionId": "1" }`)
+	})
+	defer ts.Close()
+	host, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	cvpClient, err := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		Proxy(proxy.URL))
+	ok(t, err)
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+	_, err = cvpClient.Get("/test", nil)
+	ok(t, err)
+	equals(t, int32(2), atomic.LoadInt32(&hits))
+
+	// hosts matching NO_PROXY are connected to directly
+	ok(t, cvpClient.SetProxy(proxy.URL, "example.com, "+host+"/32"))
+	_, err = cvpClient.Get("/test", nil)
+	ok(t, err)
+	equals(t, int32(2), atomic.LoadInt32(&hits))
+}
+
+func TestCvpRac_TransportProxyTLS_UnitTest(t *testing.T) {
+	var hits int32
+	proxy := createProxyServer(&hits)
+	defer proxy.Close()
+	ts := createTLSServer(t)
+	defer ts.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	transport := &http.Transport{}
+
+	// the TLS options apply through the CONNECT tunnel
+	ok(t, connectTLS(t, ts, Transport(transport), RootCAs(pool), Proxy(proxy.URL)))
+	equals(t, int32(1), atomic.LoadInt32(&hits))
+	err := connectTLS(t, ts, ServerName("bogus.example.net"), RootCAs(pool),
+		Proxy(proxy.URL))
+	assert(t, err != nil, "Expected certificate verification to fail")
+
+	// the caller's transport isn't modified
+	assert(t, transport.TLSClientConfig == nil || transport.TLSClientConfig.RootCAs == nil,
+		"Expected transport to be left untouched")
+	assert(t, transport.Proxy == nil, "Expected transport to be left untouched")
+}
+
+func TestCvpRac_TransportDialContext_UnitTest(t *testing.T) {
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{ "sessionId": "1" }`)
+	})
+	defer ts.Close()
+	_, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	var dials int32
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		equals(t, fmt.Sprintf("cvp1.example.com:%d", port), addr)
+		return (&net.Dialer{}).DialContext(ctx, network, ts.Listener.Addr().String())
+	}
+	cvpClient, err := NewCvpClient(Protocol("http"), Hosts("cvp1.example.com"),
+		Port(port), DialContext(dial), Proxy(""))
+	ok(t, err)
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+	assert(t, atomic.LoadInt32(&dials) > 0, "Expected custom dialer to be used")
+}
+
+func TestCvpRac_TransportSourceAddress_UnitTest(t *testing.T) {
+	var remote atomic.Value
+	ts := createTestServer(func(w http.ResponseWriter, r *http.Request) {
+		remote.Store(r.RemoteAddr)
+		fmt.Fprintf(w, `{ "sessionId": "1" }`)
+	})
+	defer ts.Close()
+	host, port, err := parseURL(ts.URL)
+	ok(t, err)
+
+	cvpClient, err := NewCvpClient(Protocol("http"), Hosts(host), Port(port),
+		SourceAddress("127.0.0.1"))
+	ok(t, err)
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+	assert(t, strings.HasPrefix(remote.Load().(string), "127.0.0.1:"),
+		"Expected connection from 127.0.0.1, got %v", remote.Load())
+}
+
+func TestCvpRac_TransportInvalid_UnitTest(t *testing.T) {
+	_, err := NewCvpClient(Proxy("ftp://proxy:21"))
+	assert(t, err != nil, "Expected error for invalid proxy scheme")
+	_, err = NewCvpClient(Proxy("http://"))
+	assert(t, err != nil, "Expected error for proxy without host")
+	_, err = NewCvpClient(DialContext(nil))
+	assert(t, err != nil, "Expected error for nil dial function")
+	_, err = NewCvpClient(SourceAddress("no-such-interface0"))
+	assert(t, err != nil, "Expected error for unknown interface")
+	_, err = NewCvpClient(SourceAddress("127.0.0.1"), DialContext((&net.Dialer{}).DialContext))
+	assert(t, err != nil, "Expected error for SourceAddress with DialContext")
+
+	roundTripper := http.RoundTripper(&requestCounter{})
+	_, err = NewCvpClient(Transport(roundTripper), Proxy("http://proxy:3128"))
+	assert(t, err != nil, "Expected error for proxy with a custom RoundTripper")
+
+	// TLS options would be ignored by a custom RoundTripper, whatever the
+	// order they are given in
+	fingerprint := strings.Repeat("ab", sha256.Size)
+	for _, opt := range []Option{PinCertificate(fingerprint), RootCAs(x509.NewCertPool()),
+		InsecureSkipVerify(true), ServerName("cvp.example.com"),
+		ClientCertificate(tls.Certificate{})} {
+		_, err = NewCvpClient(Transport(roundTripper), opt)
+		assert(t, err != nil, "Expected error for TLS option with a custom RoundTripper")
+		_, err = NewCvpClient(opt, Transport(roundTripper))
+		assert(t, err != nil, "Expected error for TLS option before a custom RoundTripper")
+	}
+	_, err = NewCvpClient(Transport(roundTripper), InsecureSkipVerify(false))
+	ok(t, err)
+}
+
+func TestCvpRac_BypassProxy_UnitTest(t *testing.T) {
+	patterns := []string{"example.com", ".corp.net", "10.0.0.0/8", "192.168.1.1",
+		"cvp.lab:8443", "::1"}
+	tests := []struct {
+		host   string
+		port   string
+		bypass bool
+	}{
+		{"example.com", "443", true},
+		{"cvp.example.com", "443", true},
+		{"badexample.com", "443", false},
+		{"corp.net", "443", true},
+		{"cvp.corp.net", "443", true},
+		{"10.1.2.3", "443", true},
+		{"11.1.2.3", "443", false},
+		{"192.168.1.1", "80", true},
+		{"cvp.lab", "8443", true},
+		{"cvp.lab", "443", false},
+		{"::1", "443", true},
+	}
+	for _, test := range tests {
+		equals(t, test.bypass, bypassProxy(patterns, test.host, test.port))
+	}
+	equals(t, true, bypassProxy([]string{"*"}, "anything", "443"))
+}
+
+// requestCounter is a RoundTripper which isn't an *http.Transport
+type requestCounter struct {
+	count int32
+}
+
+func (r *requestCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&r.count, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -0,0 +1,75 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package client
+
+import (
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
+	"github.com/pkg/errors"
+)
+
+// CvpVersion sets the CVP release (e.g. "2020.2.3") instead of detecting it
+// when connecting. The release picks the endpoints used by the API calls.
+func CvpVersion(version string) Option {
+	return func(c *CvpClient) error {
+		v, err := cvpapi.ParseVersion(version)
+		if err != nil {
+			return errors.Wrap(err, "CvpVersion")
+		}
+		c.version = v
+		if c.API != nil {
+			c.API.SetVersion(v)
+		}
+		return nil
+	}
+}
+
+// SetCvpVersion sets the CVP release, see CvpVersion
+func (c *CvpClient) SetCvpVersion(version string) error {
+	return c.SetOption(CvpVersion(version))
+}
+
+// Version returns the CVP release the client is connected to, or the zero
+// Version if it is unknown or not detected yet.
+func (c *CvpClient) Version() cvpapi.Version {
+	return c.API.Version()
+}
+
+// negotiateVersion has the API detect the CVP release before the first call
+// whose endpoint depends on it, unless it was set with CvpVersion
+func (c *CvpClient) negotiateVersion() {
+	c.mu.RLock()
+	pinned := !c.version.IsZero()
+	c.mu.RUnlock()
+	if !pinned {
+		c.API.SetVersionDetection(true)
+	}
+}
@@ -33,7 +33,7 @@ package main
 import (
 	"log"
 
-	"gopkg.in/aristanetworks/go-cvprac.v2/client"
+	"github.com/aristanetworks/go-cvprac/client"
 )
 
 func main() {
@@ -42,6 +42,9 @@ func main() {
 		client.Protocol("https"),
 		client.Port(443),
 		client.Hosts(hosts...),
+		// lab use only: the lab nodes use self-signed certificates. Use
+		// client.RootCAFile with the CA of the nodes anywhere else.
+		client.InsecureSkipVerify(true),
 		client.Debug(false))
 
 	if err := cvpClient.Connect("cvpadmin", "cvp123"); err != nil {
@@ -34,8 +34,8 @@ import (
 	"log"
 	"strconv"
 
-	cvpapi "gopkg.in/aristanetworks/go-cvprac.v2/api"
-	"gopkg.in/aristanetworks/go-cvprac.v2/client"
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
+	"github.com/aristanetworks/go-cvprac/client"
 )
 
 func main() {
@@ -44,6 +44,9 @@ func main() {
 		client.Protocol("https"),
 		client.Port(443),
 		client.Hosts(hosts...),
+		// lab use only: the lab nodes use self-signed certificates. Use
+		// client.RootCAFile with the CA of the nodes anywhere else.
+		client.InsecureSkipVerify(true),
 		client.Debug(false))
 
 	if err := cvpClient.Connect("cvpadmin", "cvp123"); err != nil {
@@ -0,0 +1,452 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvptest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
+	"github.com/aristanetworks/go-cvprac/client"
+)
+
+// connect returns a client logged in with the default credentials
+func connect(t *testing.T, opts ...client.Option) *client.CvpClient {
+	cvpClient, err := client.NewCvpClient(opts...)
+	ok(t, err)
+	ok(t, cvpClient.Connect("cvpadmin", "cvp123"))
+	return cvpClient
+}
+
+// seed adds a Leafs container, a device in the Undefined container and a
+// configlet
+func seed(t *testing.T, s *State) (cvpapi.NetElement, cvpapi.Configlet) {
+	_, err := s.AddContainer("Leafs", "Tenant")
+	ok(t, err)
+	dev, err := s.AddDevice(cvpapi.NetElement{SystemMacAddress: "00:1c:73:00:00:01",
+		Fqdn: "leaf1.example.com", IPAddress: "192.0.2.1"}, "Undefined")
+	ok(t, err)
+	configlet, err := s.AddConfiglet("leaf1-base", "hostname leaf1\n")
+	ok(t, err)
+	return dev, configlet
+}
+
+func TestDeployDevice_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	seed(t, srv.State)
+
+	cvpClient := connect(t, srv.ClientOptions()...)
+	api := cvpClient.API
+
+	devices, err := api.GetInventory()
+	ok(t, err)
+	equals(t, 1, len(devices))
+	dev := devices[0]
+	equals(t, UndefinedContainerKey, dev.ParentContainerKey)
+
+	cont, err := api.GetContainerByName("Leafs")
+	ok(t, err)
+	assert(t, cont != nil, "No Leafs container")
+	configlet, err := api.GetConfigletByName("leaf1-base")
+	ok(t, err)
+	assert(t, configlet != nil, "No leaf1-base configlet")
+
+	info, err := api.DeployDevice("cvptest", &dev, "192.0.2.1", cont, *configlet)
+	ok(t, err)
+	equals(t, 1, len(info.TaskIDs))
+	taskID, err := strconv.Atoi(info.TaskIDs[0])
+	ok(t, err)
+
+	// The topology changes as soon as it is saved
+	dev2, _ := srv.State.Device(dev.SystemMacAddress)
+	equals(t, cont.Key, dev2.ParentContainerKey)
+	equals(t, []string{"leaf1-base"}, srv.State.DeviceConfiglets(dev.SystemMacAddress))
+	actions, err := api.GetAllTempActions(0, 0)
+	ok(t, err)
+	equals(t, 0, len(actions))
+
+	task, err := api.GetTaskByID(taskID)
+	ok(t, err)
+	equals(t, TaskPending, task.WorkOrderUserDefinedStatus)
+	equals(t, dev.SystemMacAddress, task.WorkOrderDetails.NetElementID)
+	equals(t, cont.Key, task.Data.NewparentContainerID)
+
+	ok(t, api.AddNoteToTask(taskID, "deploy leaf1"))
+	ok(t, api.ExecuteTask(taskID))
+
+	task, err = api.GetTaskByID(taskID)
+	ok(t, err)
+	equals(t, TaskCompleted, task.WorkOrderUserDefinedStatus)
+	equals(t, "deploy leaf1", task.Note)
+
+	pending, err := api.GetTaskByStatus(TaskPending)
+	ok(t, err)
+	equals(t, 0, len(pending))
+	logs, err := api.GetLogsByID(taskID)
+	ok(t, err)
+	equals(t, 3, len(logs))
+
+	// Executing it again fails like on CVP
+	err = api.ExecuteTask(taskID)
+	assert(t, err != nil, "Executed a completed task")
+
+	_, err = api.GetTaskByID(taskID + 100)
+	assert(t, err != nil, "Got a task that doesn't exist")
+}
+
+func TestTaskPolls_UnitTest(t *testing.T) {
+	srv := NewServer(TaskPolls(2))
+	defer srv.Close()
+	dev, _ := seed(t, srv.State)
+	api := connect(t, srv.ClientOptions()...).API
+
+	cont, err := api.GetContainerByName("Leafs")
+	ok(t, err)
+	info, err := api.MoveDeviceToContainer("cvptest", &dev, cont, true)
+	ok(t, err)
+	taskID, _ := strconv.Atoi(info.TaskIDs[0])
+	ok(t, api.ExecuteTask(taskID))
+
+	for _, exp := range []string{TaskInProgress, TaskInProgress, TaskCompleted} {
+		task, err := api.GetTaskByID(taskID)
+		ok(t, err)
+		equals(t, exp, task.WorkOrderUserDefinedStatus)
+	}
+
+	ok(t, srv.State.SetTaskStatus(taskID, TaskFailed))
+	task, err := api.GetTaskByID(taskID)
+	ok(t, err)
+	equals(t, TaskFailed, task.WorkOrderUserDefinedStatus)
+	equals(t, "FAILED", task.WorkOrderState)
+}
+
+func TestWaitForTasks_UnitTest(t *testing.T) {
+	srv := NewServer(TaskPolls(2))
+	defer srv.Close()
+	dev, _ := seed(t, srv.State)
+	api := connect(t, srv.ClientOptions()...).API
+
+	cont, err := api.GetContainerByName("Leafs")
+	ok(t, err)
+	info, err := api.MoveDeviceToContainer("cvptest", &dev, cont, true)
+	ok(t, err)
+	taskID, _ := strconv.Atoi(info.TaskIDs[0])
+	ok(t, api.ExecuteTask(taskID))
+
+	var changes []string
+	policy := cvpapi.TaskWaitPolicy{Interval: time.Millisecond}
+	policy.OnChange = func(c cvpapi.TaskChange) {
+		changes = append(changes, c.To)
+	}
+	summary, err := api.WaitForTasks(policy, taskID)
+	ok(t, err)
+	assert(t, summary.Succeeded(), "Task %d didn't complete", taskID)
+	equals(t, []string{TaskInProgress, TaskCompleted}, changes)
+
+	// failed tasks come with their logs
+	ok(t, srv.State.SetTaskStatus(taskID, TaskFailed))
+	summary, err = api.WaitForTasks(policy, taskID)
+	ok(t, err)
+	equals(t, []int{taskID}, summary.Failed)
+	assert(t, len(summary.Logs[taskID]) > 0, "No logs for failed task %d", taskID)
+}
+
+func TestRunTasks_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	_, err := srv.State.AddContainer("Leafs", "Tenant")
+	ok(t, err)
+	_, err = srv.State.AddContainer("Spines", "Tenant")
+	ok(t, err)
+	api := connect(t, srv.ClientOptions()...).API
+
+	// move leaf1-3 to Leafs and spine1 to Spines, one task per device
+	var taskIDs []int
+	for i, move := range [][2]string{
+		{"leaf1", "Leafs"}, {"spine1", "Spines"}, {"leaf2", "Leafs"}, {"leaf3", "Leafs"},
+	} {
+		dev, err := srv.State.AddDevice(cvpapi.NetElement{
+			SystemMacAddress: fmt.Sprintf("00:1c:73:00:00:%02d", i+1),
+			Fqdn:             move[0] + ".example.com"}, "Undefined")
+		ok(t, err)
+		cont, err := api.GetContainerByName(move[1])
+		ok(t, err)
+		info, err := api.MoveDeviceToContainer("cvptest", &dev, cont, true)
+		ok(t, err)
+		id, _ := strconv.Atoi(info.TaskIDs[0])
+		taskIDs = append(taskIDs, id)
+	}
+
+	var waves [][]int
+	policy := cvpapi.TaskRunPolicy{
+		WaveSize: 2,
+		GroupBy:  cvpapi.GroupByContainer,
+		Wait:     cvpapi.TaskWaitPolicy{Interval: time.Millisecond},
+		OnWave: func(group string, wave int, ids []int) {
+			waves = append(waves, ids)
+		},
+	}
+	report, err := api.RunTasks(policy, taskIDs...)
+	ok(t, err)
+	assert(t, report.Succeeded(), "Run didn't succeed: %v", report.Failed())
+	equals(t, [][]int{{taskIDs[0], taskIDs[2]}, {taskIDs[3]}, {taskIDs[1]}}, waves)
+	for _, id := range taskIDs {
+		task, found := srv.State.Task(id)
+		assert(t, found, "Task %d not found", id)
+		equals(t, TaskCompleted, task.WorkOrderUserDefinedStatus)
+	}
+}
+
+func TestConfiglets_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	dev, _ := seed(t, srv.State)
+	api := connect(t, srv.ClientOptions()...).API
+
+	added, err := api.AddConfiglet("ntp", "ntp server 192.0.2.123\n")
+	ok(t, err)
+	configlet := *added
+	ok(t, err)
+	_, err = api.AddConfiglet("ntp", "")
+	assert(t, err != nil, "Added a duplicate configlet")
+
+	info, err := api.ApplyConfigletToDevice("cvptest", &dev, &configlet, true)
+	ok(t, err)
+	equals(t, 1, len(info.TaskIDs))
+	applied, err := api.GetConfigletsByDeviceID(dev.SystemMacAddress)
+	ok(t, err)
+	equals(t, 1, len(applied))
+	equals(t, "ntp", applied[0].Name)
+
+	// Updating an applied configlet creates a task for its devices
+	taskIDs, err := api.UpdateConfigletWaitForTask("ntp server 192.0.2.124\n", "ntp",
+		configlet.Key)
+	ok(t, err)
+	equals(t, 1, len(taskIDs))
+	updated, err := api.GetConfigletByName("ntp")
+	ok(t, err)
+	equals(t, "ntp server 192.0.2.124\n", updated.Config)
+	equals(t, 1, updated.NetElementCount)
+
+	err = api.DeleteConfiglet("ntp", configlet.Key)
+	assert(t, err != nil, "Deleted an applied configlet")
+	_, err = api.RemoveConfigletFromDevice("cvptest", &dev, updated, true)
+	ok(t, err)
+	ok(t, api.DeleteConfiglet("ntp", configlet.Key))
+	updated, err = api.GetConfigletByName("ntp")
+	ok(t, err)
+	assert(t, updated == nil, "Configlet not deleted")
+}
+
+func TestIterateConfiglets_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	for i := 0; i < 5; i++ {
+		_, err := srv.State.AddConfiglet(fmt.Sprintf("cfglt%d", i), "")
+		ok(t, err)
+	}
+	api := connect(t, srv.ClientOptions()...).API
+
+	before := srv.Requests()
+	it := api.IterateConfiglets(2)
+	var names []string
+	for it.Next() {
+		names = append(names, it.Item().Name)
+	}
+	ok(t, it.Err())
+	equals(t, 5, it.Total())
+	equals(t, []string{"cfglt0", "cfglt1", "cfglt2", "cfglt3", "cfglt4"}, names)
+	equals(t, 3, srv.Requests()-before)
+}
+
+func TestVersionNegotiation_UnitTest(t *testing.T) {
+	srv := NewServer(Version("2018.1.4"))
+	defer srv.Close()
+	seed(t, srv.State)
+	cvpClient := connect(t, srv.ClientOptions()...)
+
+	devices, err := cvpClient.API.GetInventory()
+	ok(t, err)
+	equals(t, 1, len(devices))
+	equals(t, cvpapi.MustParseVersion("2018.1.4"), cvpClient.Version())
+
+	_, err = cvpClient.API.SaveInventory()
+	assert(t, errors.Is(err, cvpapi.ErrUnsupportedVersion), "Unexpected error: %v", err)
+
+	// a pinned release isn't detected
+	cvpClient = connect(t, append(srv.ClientOptions(), client.CvpVersion("2020.2.3"))...)
+	_, err = cvpClient.API.SaveInventory()
+	ok(t, err)
+	equals(t, cvpapi.MustParseVersion("2020.2.3"), cvpClient.Version())
+}
+
+func TestContainers_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	api := connect(t, srv.ClientOptions()...).API
+
+	ok(t, api.AddContainer("Spines", "Tenant", RootContainerKey))
+	err := api.AddContainer("Spines", "Tenant", RootContainerKey)
+	assert(t, err != nil, "Added a duplicate container")
+
+	res, err := api.SearchTopology("Spines")
+	ok(t, err)
+	equals(t, 1, res.Total)
+	cont, err := api.GetContainerByName("spines")
+	ok(t, err)
+	assert(t, cont != nil, "No Spines container")
+	info, err := api.GetContainerInfoByID(cont.Key)
+	ok(t, err)
+	equals(t, "Tenant", info.ParentName)
+
+	ok(t, api.DeleteContainer("Spines", cont.Key, "Tenant", RootContainerKey))
+	cont, err = api.GetContainerByName("Spines")
+	ok(t, err)
+	assert(t, cont == nil, "Container not deleted")
+}
+
+func TestChangeControl_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	dev, configlet := seed(t, srv.State)
+	api := connect(t, srv.ClientOptions()...).API
+
+	info, err := api.ApplyConfigletToDevice("cvptest", &dev, &configlet, true)
+	ok(t, err)
+
+	available, err := api.GetChangeControlAvailableTasks("", 0, 0)
+	ok(t, err)
+	equals(t, 1, len(available))
+	equals(t, info.TaskIDs[0], available[0].WorkOrderID)
+
+	ccID, err := api.CreateChangeControl("cc1", "America/Los_Angeles", "United States",
+		"2026-10-17 12:00", "", "Custom", "false",
+		[]cvpapi.ChangeControlTaskInfo{{TaskID: info.TaskIDs[0], TaskOrder: 1}})
+	ok(t, err)
+	id, _ := strconv.Atoi(ccID)
+	ok(t, api.AddNotesToChangeControl(id, "leaf1 configlets"))
+
+	ccs, err := api.GetChangeControls("cc1", 0, 0)
+	ok(t, err)
+	equals(t, 1, len(ccs))
+	equals(t, 1, ccs[0].TaskCount)
+	equals(t, "leaf1 configlets", ccs[0].Notes)
+
+	available, err = api.GetChangeControlAvailableTasks("", 0, 0)
+	ok(t, err)
+	equals(t, 0, len(available))
+}
+
+func TestLogin_UnitTest(t *testing.T) {
+	srv := NewServer(Credentials("admin", "secret"), Version("2019.1.0"))
+	defer srv.Close()
+
+	cvpClient, err := client.NewCvpClient(srv.ClientOptions()...)
+	ok(t, err)
+	err = cvpClient.Connect("cvpadmin", "cvp123")
+	assert(t, err != nil, "Logged in with invalid credentials")
+
+	ok(t, cvpClient.Connect("admin", "secret"))
+	info, err := cvpClient.API.GetCvpInfo()
+	ok(t, err)
+	equals(t, "2019.1.0", info.Version)
+
+	// An expired session is renewed by logging in again
+	srv.State.ExpireSessions()
+	_, err = cvpClient.API.GetCvpInfo()
+	ok(t, err)
+}
+
+func TestClusterNodeStopped_UnitTest(t *testing.T) {
+	cluster := NewCluster(2)
+	defer cluster.Close()
+	seed(t, cluster.State)
+	cvpClient := connect(t, cluster.ClientOptions()...)
+
+	// Stop the node the client logged in to, connections to it are refused
+	current, other := cluster.Nodes[0], cluster.Nodes[1]
+	if current.Requests() == 0 {
+		current, other = other, current
+	}
+	current.Close()
+
+	devices, err := cvpClient.API.GetInventory()
+	ok(t, err)
+	equals(t, 1, len(devices))
+	served := other.Requests()
+	assert(t, served > 0, "No failover to %s", other.Name)
+
+	// The session stays on the node that is up
+	_, err = cvpClient.API.GetInventory()
+	ok(t, err)
+	equals(t, served+1, other.Requests())
+}
+
+func TestClusterFailover_UnitTest(t *testing.T) {
+	cluster := NewCluster(3)
+	defer cluster.Close()
+	seed(t, cluster.State)
+
+	opts := append(cluster.ClientOptions(), client.Retry(client.RetryPolicy{MaxAttempts: 1}))
+	cvpClient := connect(t, opts...)
+	api := cvpClient.API
+
+	// Take down the node the client logged in to
+	var current *Server
+	for _, node := range cluster.Nodes {
+		if node.Requests() > 0 {
+			current = node
+		}
+	}
+	assert(t, current != nil, "No node was logged in to")
+	current.SetDown(true)
+	served := current.Requests()
+
+	devices, err := api.GetInventory()
+	ok(t, err)
+	equals(t, 1, len(devices))
+	equals(t, served+1, current.Requests())
+
+	// The nodes share their state
+	cont, err := api.GetContainerByName("Leafs")
+	ok(t, err)
+	info, err := api.MoveDeviceToContainer("cvptest", &devices[0], cont, true)
+	ok(t, err)
+	taskID, _ := strconv.Atoi(info.TaskIDs[0])
+	task, found := cluster.State.Task(taskID)
+	assert(t, found, "No task %d", taskID)
+	equals(t, TaskPending, task.WorkOrderUserDefinedStatus)
+}
@@ -0,0 +1,307 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvptest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Fault is a failure injected into the requests to CVP. Latency can be
+// combined with one of Drop, Status or ErrorCode/ErrorMessage.
+type Fault struct {
+	// Path restricts the fault to requests whose path ends with Path, e.g.
+	// "/inventory/devices". Every request matches an empty Path.
+	Path string
+	// After lets the first After matching requests through
+	After int
+	// Times is the number of requests failed, 0 fails all the following
+	// matching requests
+	Times int
+
+	// Latency delays the request
+	Latency time.Duration
+	// Drop closes the connection without a response
+	Drop bool
+	// Status answers with the HTTP status, e.g. 401 or 503
+	Status int
+	// Location is the Location header sent with a redirect Status
+	Location string
+	// ErrorCode and ErrorMessage answer 200 with a CVP error body
+	ErrorCode    string
+	ErrorMessage string
+}
+
+// ExpireSession answers 401 Unauthorized once, after after requests, like
+// CVP does when a session times out
+func ExpireSession(after int) Fault {
+	return Fault{After: after, Times: 1, Status: http.StatusUnauthorized}
+}
+
+// Redirect answers 301 Moved Permanently. Without location the client can't
+// follow the redirect and has to fail over to another node.
+func Redirect(location string) Fault {
+	return Fault{Status: http.StatusMovedPermanently, Location: location}
+}
+
+// DropConnection closes the connections without a response
+func DropConnection() Fault {
+	return Fault{Drop: true}
+}
+
+// Delay slows down the requests by latency
+func Delay(latency time.Duration) Fault {
+	return Fault{Latency: latency}
+}
+
+// CvpError answers with a CVP error body, e.g. cvpapi.ENTITY_DOES_NOT_EXIST
+func CvpError(code string, message string) Fault {
+	return Fault{ErrorCode: code, ErrorMessage: message}
+}
+
+// ErrConnectionDropped is returned by a fault injecting Transport for a
+// dropped connection
+var ErrConnectionDropped = errors.New("cvptest: Connection dropped")
+
+// activeFault tracks the requests matching a fault
+type activeFault struct {
+	Fault
+	seen   int
+	failed int
+}
+
+// Injector injects faults into the requests served by a fake CVP or sent
+// through a transport, e.g. a replay.Replayer. Every Server has one in
+// its Faults field.
+type Injector struct {
+	mu       sync.Mutex
+	faults   []*activeFault
+	injected int
+}
+
+// NewInjector returns an Injector of faults
+func NewInjector(faults ...Fault) *Injector {
+	i := &Injector{}
+	i.Inject(faults...)
+	return i
+}
+
+// Inject adds faults. A request is failed by the first fault it triggers and
+// delayed by the Latency of all of them.
+func (i *Injector) Inject(faults ...Fault) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, f := range faults {
+		i.faults = append(i.faults, &activeFault{Fault: f})
+	}
+}
+
+// Clear removes all faults
+func (i *Injector) Clear() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults = nil
+}
+
+// Injected returns the number of requests faults were injected into
+func (i *Injector) Injected() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.injected
+}
+
+// next returns the fault to inject into a request for path, nil if there
+// is none
+func (i *Injector) next(path string) *Fault {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	var fault *Fault
+	for _, f := range i.faults {
+		if !strings.HasSuffix(path, f.Path) {
+			continue
+		}
+		f.seen++
+		if f.seen <= f.After || (f.Times > 0 && f.failed >= f.Times) {
+			continue
+		}
+		f.failed++
+		if fault == nil {
+			fault = &Fault{}
+		}
+		fault.Latency += f.Latency
+		if !fault.fails() {
+			fault.Drop, fault.Status, fault.Location = f.Drop, f.Status, f.Location
+			fault.ErrorCode, fault.ErrorMessage = f.ErrorCode, f.ErrorMessage
+		}
+	}
+	if fault != nil {
+		i.injected++
+	}
+	return fault
+}
+
+// fails reports whether the fault fails the request rather than only
+// delaying it
+func (f *Fault) fails() bool {
+	return f.Drop || f.Status != 0 || f.ErrorCode != "" || f.ErrorMessage != ""
+}
+
+// body returns the CVP error body of the fault
+func (f *Fault) body() []byte {
+	body, _ := json.Marshal(cvpError(f.ErrorCode, f.ErrorMessage))
+	return body
+}
+
+// wait sleeps for the latency of the fault unless done is closed first
+func (f *Fault) wait(done <-chan struct{}) bool {
+	if f.Latency <= 0 {
+		return true
+	}
+	timer := time.NewTimer(f.Latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-done:
+		return false
+	}
+}
+
+// Handler returns a handler injecting the faults into the requests before
+// passing them to next
+func (i *Injector) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !i.serve(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// serve injects the fault for r, reporting whether r was answered
+func (i *Injector) serve(w http.ResponseWriter, r *http.Request) bool {
+	f := i.next(r.URL.Path)
+	if f == nil {
+		return false
+	}
+	if !f.wait(r.Context().Done()) {
+		return true
+	}
+	switch {
+	case f.Drop:
+		// closes the connection without a response
+		panic(http.ErrAbortHandler)
+	case f.Status != 0:
+		if f.Location != "" {
+			w.Header().Set("Location", f.Location)
+		}
+		http.Error(w, http.StatusText(f.Status), f.Status)
+	case f.ErrorCode != "" || f.ErrorMessage != "":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(f.body())
+	default:
+		return false
+	}
+	return true
+}
+
+// Transport returns a transport injecting the faults into the requests
+// before sending them through next, http.DefaultTransport if nil
+func (i *Injector) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &faultTransport{injector: i, next: next}
+}
+
+type faultTransport struct {
+	injector *Injector
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f := t.injector.next(req.URL.Path)
+	if f == nil {
+		return t.next.RoundTrip(req)
+	}
+	if !f.wait(req.Context().Done()) {
+		closeBody(req)
+		return nil, req.Context().Err()
+	}
+	switch {
+	case f.Drop:
+		closeBody(req)
+		return nil, ErrConnectionDropped
+	case f.Status != 0:
+		closeBody(req)
+		header := make(http.Header)
+		if f.Location != "" {
+			header.Set("Location", f.Location)
+		}
+		return faultResponse(req, f.Status, header, []byte(http.StatusText(f.Status))), nil
+	case f.ErrorCode != "" || f.ErrorMessage != "":
+		closeBody(req)
+		header := http.Header{"Content-Type": {"application/json"}}
+		return faultResponse(req, http.StatusOK, header, f.body()), nil
+	}
+	return t.next.RoundTrip(req)
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+func faultResponse(req *http.Request, status int, header http.Header,
+	body []byte) *http.Response {
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,186 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvptest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
+	"github.com/aristanetworks/go-cvprac/client"
+)
+
+// attempts records why each request was attempted
+type attempts struct {
+	mu      sync.Mutex
+	reasons []client.AttemptReason
+}
+
+func (a *attempts) middleware() client.Option {
+	return client.Middlewares(client.Hooks{Before: func(req *client.RequestInfo) error {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.reasons = append(a.reasons, req.Reason)
+		return nil
+	}})
+}
+
+func (a *attempts) get() []client.AttemptReason {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	reasons := a.reasons
+	a.reasons = nil
+	return reasons
+}
+
+func TestFaultExpireSession_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	var seen attempts
+	cvpClient := connect(t, append(srv.ClientOptions(), seen.middleware())...)
+
+	fault := ExpireSession(1)
+	fault.Path = "/cvpInfo/getCvpInfo.do"
+	srv.Faults.Inject(fault)
+
+	_, err := cvpClient.API.GetCvpInfo()
+	ok(t, err)
+	equals(t, []client.AttemptReason{client.AttemptFirst}, seen.get())
+
+	// The second request is rejected and succeeds after logging in again
+	_, err = cvpClient.API.GetCvpInfo()
+	ok(t, err)
+	equals(t, []client.AttemptReason{client.AttemptFirst, client.AttemptRelogin}, seen.get())
+	equals(t, 1, srv.Faults.Injected())
+}
+
+func TestFaultRedirectFailover_UnitTest(t *testing.T) {
+	cluster := NewCluster(2)
+	defer cluster.Close()
+	var seen attempts
+	// pin the release so only GetInventory is sent to CVP
+	cvpClient := connect(t, append(cluster.ClientOptions(), seen.middleware(),
+		client.CvpVersion("2020.2.3"))...)
+
+	// Redirect the requests to the node the client logged in to
+	current, other := cluster.Nodes[0], cluster.Nodes[1]
+	if current.Requests() == 0 {
+		current, other = other, current
+	}
+	current.Faults.Inject(Redirect(""))
+
+	_, err := cvpClient.API.GetInventory()
+	ok(t, err)
+	equals(t, []client.AttemptReason{client.AttemptFirst, client.AttemptFailover}, seen.get())
+	equals(t, 1, current.Faults.Injected())
+	assert(t, other.Requests() > 0, "No failover to %s", other.Name)
+
+	// With every node redirecting there is nowhere left to fail over to
+	other.Faults.Inject(Redirect(""))
+	_, err = cvpClient.API.GetInventory()
+	assert(t, err != nil, "Request redirected by every node succeeded")
+}
+
+func TestFaultDropConnection_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	fault := DropConnection()
+	fault.Times = 1
+	srv.Faults.Inject(fault)
+	_, err := http.Get(srv.URL + "/web/cvpInfo/getCvpInfo.do")
+	assert(t, err != nil, "Dropped connection returned a response")
+
+	// Through the transport the client sees the dropped connection
+	faults := NewInjector()
+	cvpClient := connect(t, append(srv.ClientOptions(),
+		client.Transport(faults.Transport(nil)))...)
+	faults.Inject(DropConnection())
+	_, err = cvpClient.API.GetCvpInfo()
+	assert(t, errors.Is(err, ErrConnectionDropped), "Expected dropped connection, "+
+		"got: %v", err)
+
+	faults.Clear()
+	_, err = cvpClient.API.GetCvpInfo()
+	ok(t, err)
+}
+
+func TestFaultLatency_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	cvpClient := connect(t, srv.ClientOptions()...)
+
+	srv.Faults.Inject(Delay(50 * time.Millisecond))
+	start := time.Now()
+	_, err := cvpClient.API.GetCvpInfo()
+	ok(t, err)
+	assert(t, time.Since(start) >= 50*time.Millisecond, "Request not delayed")
+
+	srv.Faults.Clear()
+	srv.Faults.Inject(Delay(time.Minute))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = cvpClient.API.GetCvpInfoCtx(ctx)
+	assert(t, err != nil, "Request not abandoned")
+}
+
+func TestFaultCvpError_UnitTest(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	seed(t, srv.State)
+
+	faults := NewInjector(Fault{Path: "/configlet/getConfigletByName.do", After: 1,
+		ErrorCode: cvpapi.INVALID_USER, ErrorMessage: "Invalid user"})
+	cvpClient := connect(t, append(srv.ClientOptions(),
+		client.Transport(faults.Transport(nil)))...)
+
+	configlet, err := cvpClient.API.GetConfigletByName("leaf1-base")
+	ok(t, err)
+	assert(t, configlet != nil, "No configlet")
+
+	_, err = cvpClient.API.GetConfigletByName("leaf1-base")
+	var cvpErr *cvpapi.CvpError
+	assert(t, errors.As(err, &cvpErr), "Expected a CvpError, got: %v", err)
+	equals(t, cvpapi.INVALID_USER, cvpErr.Code)
+	equals(t, "GetConfigletByName", cvpErr.Op)
+
+	// The same fault served by the fake CVP itself
+	faults.Clear()
+	srv.Faults.Inject(CvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No such task"))
+	_, err = cvpClient.API.GetAllTasks()
+	assert(t, errors.As(err, &cvpErr), "Expected a CvpError, got: %v", err)
+	equals(t, cvpapi.ENTITY_DOES_NOT_EXIST, cvpErr.Code)
+}
@@ -0,0 +1,835 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvptest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
+)
+
+// routes maps the paths below /web to their handler
+var routes = map[string]route{
+	"/login/authenticate.do": {method: "POST", handler: login, public: true},
+	"/login/logout.do":       {method: "POST", handler: logout, public: true},
+	"/cvpInfo/getCvpInfo.do": {method: "GET", handler: getCvpInfo},
+
+	"/inventory/devices":                    {method: "GET", handler: getDevices},
+	"/inventory/getInventory.do":            {method: "GET", handler: getInventory},
+	"/inventory/containers":                 {method: "GET", handler: getContainers},
+	"/inventory/add/addToInventory.do":      {method: "POST", handler: addToInventory},
+	"/inventory/v2/saveInventory.do":        {method: "POST", handler: saveInventory},
+	"/inventory/deleteDevices.do":           {method: "POST", handler: deleteDevices},
+	"/provisioning/getContainerInfoById.do": {method: "GET", handler: getContainerInfo},
+	"/provisioning/searchTopology.do":       {method: "GET", handler: searchTopology},
+	"/provisioning/checkCompliance.do":      {method: "POST", handler: checkCompliance},
+	"/provisioning/getTempConfigsByNetElementId.do": {method: "GET",
+		handler: getTempConfigs},
+
+	"/configlet/getConfiglets.do":      {method: "GET", handler: getConfiglets},
+	"/configlet/getConfigletByName.do": {method: "GET", handler: getConfigletByName},
+	"/configlet/getConfigletById.do":   {method: "GET", handler: getConfigletByID},
+	"/configlet/searchConfiglets.do":   {method: "GET", handler: searchConfiglets},
+	"/configlet/addConfiglet.do":       {method: "POST", handler: addConfiglet},
+	"/configlet/updateConfiglet.do":    {method: "POST", handler: updateConfiglet},
+	"/configlet/deleteConfiglet.do":    {method: "POST", handler: deleteConfiglet},
+	"/configlet/addNoteToConfiglet.do": {method: "POST", handler: addNoteToConfiglet},
+	"/configlet/getAppliedDevices.do":  {method: "GET", handler: getAppliedDevices},
+	"/configlet/getHierarchicalConfigletBuilders.do": {method: "GET",
+		handler: getConfigletBuilders},
+	"/provisioning/getConfigletsByNetElementId.do": {method: "GET",
+		handler: getDeviceConfiglets},
+	"/provisioning/getConfigletsByContainerId.do": {method: "GET",
+		handler: getContainerConfiglets},
+
+	"/ztp/addTempAction.do":              {method: "POST", handler: addTempAction},
+	"/provisioning/getAllTempActions.do": {method: "GET", handler: getTempActions},
+	"/ztp/deleteAllTempAction.do":        {method: "DELETE", handler: deleteTempActions},
+	"/ztp/v2/saveTopology.do":            {method: "POST", handler: saveTopology},
+	"/ztp/saveTopology.do":               {method: "POST", handler: saveTopology},
+
+	"/task/getTaskById.do":     {method: "GET", handler: getTask},
+	"/workflow/getTasks.do":    {method: "GET", handler: getTasks},
+	"/task/getLogsById.do":     {method: "GET", handler: getLogs},
+	"/task/addNoteToTask.do":   {method: "POST", handler: addNoteToTask},
+	"/workflow/executeTask.do": {method: "POST", handler: executeTask},
+	"/task/cancelTask.do":      {method: "POST", handler: cancelTask},
+
+	"/changeControl/getChangeControls.do":        {method: "GET", handler: getChangeControls},
+	"/changeControl/getTasksByStatus.do":         {method: "GET", handler: getCCTasks},
+	"/changeControl/addOrUpdateChangeControl.do": {method: "POST", handler: addChangeControl},
+	"/changeControl/addNotesToChangeControl.do":  {method: "POST", handler: addCCNotes},
+}
+
+// data is the {"data": ...} envelope of most CVP responses
+type data struct {
+	Data interface{} `json:"data"`
+}
+
+// list is the paged {"total": ..., "data": [...]} response
+type list struct {
+	Total int         `json:"total"`
+	Data  interface{} `json:"data"`
+}
+
+func invalidBody(err error) *cvpapi.ErrorResponse {
+	return cvpError("", "Invalid request body: "+err.Error())
+}
+
+// page returns the bounds of the items between the startIndex and endIndex
+// query parameters, where an endIndex of 0 selects all items
+func page(q url.Values, n int) (int, int) {
+	start, _ := strconv.Atoi(q.Get("startIndex"))
+	end, _ := strconv.Atoi(q.Get("endIndex"))
+	if end <= 0 || end > n {
+		end = n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start > end {
+		start = end
+	}
+	return start, end
+}
+
+// matches reports whether one of the fields contains query, ignoring case
+func matches(query string, fields ...string) bool {
+	query = strings.ToLower(query)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return query == ""
+}
+
+func login(s *State, c *call) interface{} {
+	var auth struct {
+		UserID   string `json:"userId"`
+		Password string `json:"password"`
+	}
+	if err := c.decode(&auth); err != nil {
+		return invalidBody(err)
+	}
+	if auth.UserID != s.username || auth.Password != s.password {
+		return cvpError(cvpapi.UNABLE_TO_LOGIN, "Authentication failed")
+	}
+	id := s.newSession()
+	http.SetCookie(c.w, &http.Cookie{Name: "session_id", Value: id, Path: "/web"})
+	return cvpapi.LoginResp{SessionID: id, Username: s.username,
+		User: cvpapi.User{UserID: s.username, UserStatus: "Enabled"}}
+}
+
+func logout(s *State, c *call) interface{} {
+	if cookie, err := c.r.Cookie("session_id"); err == nil {
+		delete(s.sessions, cookie.Value)
+	}
+	return data{Data: "success"}
+}
+
+func getCvpInfo(s *State, c *call) interface{} {
+	return cvpapi.CvpInfo{Version: s.version, AppVersion: s.version}
+}
+
+func getDevices(s *State, c *call) interface{} {
+	devices := make([]cvpapi.NetElement, 0, len(s.devices))
+	for _, d := range s.devices {
+		devices = append(devices, d.NetElement)
+	}
+	return devices
+}
+
+// getInventory is the inventory of CVP releases older than 2019.0
+func getInventory(s *State, c *call) interface{} {
+	devices := getDevices(s, c).([]cvpapi.NetElement)
+	return struct {
+		Total          int                 `json:"total"`
+		NetElementList []cvpapi.NetElement `json:"netElementList"`
+	}{len(devices), devices}
+}
+
+// saveInventory reports every device as connected, devices being added to
+// the inventory right away
+func saveInventory(s *State, c *call) interface{} {
+	n := strconv.Itoa(len(s.devices))
+	return data{Data: cvpapi.SaveInventoryData{Total: n, Connected: n, Message: "success"}}
+}
+
+func getContainers(s *State, c *call) interface{} {
+	name := c.query.Get("name")
+	containers := []cvpapi.Container{}
+	for _, cont := range s.containers {
+		if name == "" || strings.EqualFold(cont.Name, name) {
+			containers = append(containers, cont.Container)
+		}
+	}
+	return containers
+}
+
+func addToInventory(s *State, c *call) interface{} {
+	var req struct {
+		Data []struct {
+			ContainerID string `json:"containerId"`
+			IPAddress   string `json:"ipAddress"`
+		} `json:"data"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	for _, dev := range req.Data {
+		if s.container(dev.ContainerID) == nil {
+			return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No container "+dev.ContainerID)
+		}
+	}
+	for _, dev := range req.Data {
+		id := s.nextID()
+		s.addDevice(cvpapi.NetElement{
+			IPAddress:        dev.IPAddress,
+			SystemMacAddress: fakeMAC(id),
+			Fqdn:             dev.IPAddress,
+			Hostname:         dev.IPAddress,
+			SerialNumber:     "SN" + strconv.Itoa(id),
+		}, dev.ContainerID)
+	}
+	return data{Data: "success"}
+}
+
+// fakeMAC returns a system MAC address in the Arista range
+func fakeMAC(id int) string {
+	return "00:1c:73:" + hexByte(id>>16) + ":" + hexByte(id>>8) + ":" + hexByte(id)
+}
+
+func hexByte(b int) string {
+	const digits = "0123456789abcdef"
+	return string([]byte{digits[b>>4&0xf], digits[b&0xf]})
+}
+
+func deleteDevices(s *State, c *call) interface{} {
+	var req struct {
+		Data []string `json:"data"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	for _, mac := range req.Data {
+		for i, d := range s.devices {
+			if d.SystemMacAddress == mac {
+				s.devices = append(s.devices[:i], s.devices[i+1:]...)
+				break
+			}
+		}
+	}
+	return data{Data: "success"}
+}
+
+func getContainerInfo(s *State, c *call) interface{} {
+	cont := s.container(c.query.Get("containerId"))
+	if cont == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No container "+
+			c.query.Get("containerId"))
+	}
+	info := cvpapi.ContainerInfo{
+		Date:                 cont.CreatedOn,
+		Name:                 cont.Name,
+		UserID:               cont.CreatedBy,
+		AssociatedSwitches:   len(s.devicesIn(cont.Key)),
+		AssociatedConfiglets: len(cont.configlets),
+	}
+	if parent := s.container(cont.parent); parent != nil {
+		info.ParentName = parent.Name
+	}
+	return info
+}
+
+func searchTopology(s *State, c *call) interface{} {
+	query := c.query.Get("queryParam")
+	resp := cvpapi.SearchTopologyResp{
+		NetElementContainerList: []cvpapi.NetElementContainer{},
+		KeywordList:             []string{},
+		ContainerList:           []cvpapi.ContainerData{},
+		NetElementList:          []cvpapi.NetElement{},
+	}
+	var devices []*device
+	for _, d := range s.devices {
+		if matches(query, d.SystemMacAddress, d.Fqdn, d.Hostname, d.IPAddress) {
+			devices = append(devices, d)
+		}
+	}
+	start, end := page(c.query, len(devices))
+	for _, d := range devices[start:end] {
+		cont := s.container(d.ParentContainerKey)
+		resp.NetElementContainerList = append(resp.NetElementContainerList,
+			cvpapi.NetElementContainer{ContainerKey: cont.Key, ContainerName: cont.Name,
+				NetElementKey: d.Key})
+		resp.NetElementList = append(resp.NetElementList, d.NetElement)
+	}
+	for _, cont := range s.containers {
+		if matches(query, cont.Name) {
+			resp.ContainerList = append(resp.ContainerList, cvpapi.ContainerData{
+				Undefined:            cont.Key == UndefinedContainerKey,
+				UserID:               cont.CreatedBy,
+				DateTimeInLongFormat: cont.CreatedOn,
+				Root:                 cont.Key == RootContainerKey,
+				Mode:                 cont.Mode,
+				Name:                 cont.Name,
+				Key:                  cont.Key,
+			})
+		}
+	}
+	resp.Total = len(devices) + len(resp.ContainerList)
+	return resp
+}
+
+func checkCompliance(s *State, c *call) interface{} {
+	var req struct {
+		NodeID string `json:"nodeId"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	d := s.device(req.NodeID)
+	if d == nil {
+		return cvpError(cvpapi.NETELEMENT_ENTITY_DOES_NOT_EXIST, "No device "+req.NodeID)
+	}
+	return cvpapi.ComplianceResp{
+		ComplianceCode:       d.ComplianceCode,
+		ComplianceIndication: d.ComplianceIndication,
+		DeviceStatus:         d.DeviceStatus,
+		Fqdn:                 d.Fqdn,
+		IPAddress:            d.IPAddress,
+		Key:                  d.Key,
+		SerialNumber:         d.SerialNumber,
+		SystemMacAddress:     d.SystemMacAddress,
+		Type:                 d.Type,
+		Version:              d.Version,
+	}
+}
+
+func getTempConfigs(s *State, c *call) interface{} {
+	d := s.device(c.query.Get("netElementId"))
+	if d == nil {
+		return cvpError(cvpapi.NETELEMENT_ENTITY_DOES_NOT_EXIST, "No device "+
+			c.query.Get("netElementId"))
+	}
+	return cvpapi.TempConfig{ProposedConfiglets: []cvpapi.Configlet{}}
+}
+
+func configletList(s *State, configlets []*cvpapi.Configlet, c *call) interface{} {
+	start, end := page(c.query, len(configlets))
+	resp := cvpapi.ConfigletList{Total: len(configlets), Data: []cvpapi.Configlet{}}
+	for _, configlet := range configlets[start:end] {
+		resp.Data = append(resp.Data, s.withCounts(configlet))
+	}
+	return resp
+}
+
+// withCounts returns a copy of the configlet with its usage counts
+func (s *State) withCounts(configlet *cvpapi.Configlet) cvpapi.Configlet {
+	counted := *configlet
+	for _, d := range s.devices {
+		if oneOf(configlet.Key, d.configlets...) {
+			counted.NetElementCount++
+		}
+	}
+	for _, cont := range s.containers {
+		if oneOf(configlet.Key, cont.configlets...) {
+			counted.ContainerCount++
+		}
+	}
+	return counted
+}
+
+func getConfiglets(s *State, c *call) interface{} {
+	return configletList(s, s.configlets, c)
+}
+
+func searchConfiglets(s *State, c *call) interface{} {
+	var configlets []*cvpapi.Configlet
+	for _, configlet := range s.configlets {
+		if matches(c.query.Get("queryparam"), configlet.Name) {
+			configlets = append(configlets, configlet)
+		}
+	}
+	return configletList(s, configlets, c)
+}
+
+func getConfigletByName(s *State, c *call) interface{} {
+	configlet := s.configletByName(c.query.Get("name"))
+	if configlet == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No configlet "+c.query.Get("name"))
+	}
+	return s.withCounts(configlet)
+}
+
+func getConfigletByID(s *State, c *call) interface{} {
+	configlet := s.configlet(c.query.Get("id"))
+	if configlet == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No configlet "+c.query.Get("id"))
+	}
+	return s.withCounts(configlet)
+}
+
+func addConfiglet(s *State, c *call) interface{} {
+	var req struct {
+		Name   string `json:"name"`
+		Config string `json:"config"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	configlet, err := s.addConfiglet(req.Name, req.Config)
+	if err != nil {
+		return cvpError(cvpapi.DATA_ALREADY_EXISTS, err.Error())
+	}
+	return data{Data: *configlet}
+}
+
+func updateConfiglet(s *State, c *call) interface{} {
+	var req struct {
+		Config string `json:"config"`
+		Key    string `json:"key"`
+		Name   string `json:"name"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	configlet := s.configlet(req.Key)
+	if configlet == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No configlet "+req.Key)
+	}
+	if other := s.configletByName(req.Name); other != nil && other != configlet {
+		return cvpError(cvpapi.DATA_ALREADY_EXISTS, "Configlet "+req.Name+" already exists")
+	}
+	configlet.Name = req.Name
+	configlet.Config = req.Config
+	configlet.DateTimeInLongFormat = timestamp()
+
+	// the devices the configlet applies to need the new configuration
+	taskIDs := []string{}
+	action := &cvpapi.Action{Info: "Configlet Update: " + configlet.Name}
+	for _, d := range s.devices {
+		if oneOf(configlet.Key, s.effectiveConfiglets(d)...) {
+			taskIDs = append(taskIDs, s.newTask(d, action, "Configlet Push").WorkOrderID)
+		}
+	}
+	return cvpapi.ConfigletUpdateReturn{Data: "Configlet is successfully updated",
+		TaskIDs: taskIDs}
+}
+
+// effectiveConfiglets returns the keys of the configlets applied to the
+// device directly or through its containers
+func (s *State) effectiveConfiglets(d *device) []string {
+	keys := append([]string(nil), d.configlets...)
+	for cont := s.container(d.ParentContainerKey); cont != nil; cont = s.container(
+		cont.parent) {
+		keys = append(keys, cont.configlets...)
+	}
+	return keys
+}
+
+func deleteConfiglet(s *State, c *call) interface{} {
+	var req []struct {
+		Name string `json:"name"`
+		Key  string `json:"key"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	for _, del := range req {
+		configlet := s.configlet(del.Key)
+		if configlet == nil {
+			return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No configlet "+del.Key)
+		}
+		if counted := s.withCounts(configlet); counted.NetElementCount > 0 ||
+			counted.ContainerCount > 0 {
+			return cvpError("", "Configlet "+configlet.Name+" is applied")
+		}
+	}
+	for _, del := range req {
+		for i, configlet := range s.configlets {
+			if configlet.Key == del.Key {
+				s.configlets = append(s.configlets[:i], s.configlets[i+1:]...)
+				break
+			}
+		}
+	}
+	return data{Data: "success"}
+}
+
+func addNoteToConfiglet(s *State, c *call) interface{} {
+	var req struct {
+		Key  string `json:"key"`
+		Note string `json:"note"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	configlet := s.configlet(req.Key)
+	if configlet == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No configlet "+req.Key)
+	}
+	configlet.Note = req.Note
+	return data{Data: "success"}
+}
+
+func getAppliedDevices(s *State, c *call) interface{} {
+	configlet := s.configletByName(c.query.Get("configletName"))
+	if configlet == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No configlet "+
+			c.query.Get("configletName"))
+	}
+	var devices []*device
+	for _, d := range s.devices {
+		if oneOf(configlet.Key, d.configlets...) {
+			devices = append(devices, d)
+		}
+	}
+	start, end := page(c.query, len(devices))
+	resp := cvpapi.GenericReq{Total: len(devices), Data: []cvpapi.ObjectInfo{}}
+	for _, d := range devices[start:end] {
+		resp.Data = append(resp.Data, cvpapi.ObjectInfo{
+			ContainerName:     s.container(d.ParentContainerKey).Name,
+			AppliedBy:         configlet.User,
+			HostName:          d.Fqdn,
+			IPAddress:         d.IPAddress,
+			TotalDevicesCount: len(devices),
+		})
+	}
+	return resp
+}
+
+func getConfigletBuilders(s *State, c *call) interface{} {
+	return cvpapi.BuilderInfo{BuilderList: []cvpapi.Configlet{},
+		BuildMapperList: []cvpapi.BuilderMaps{}}
+}
+
+func configletInfo(s *State, keys []string, c *call) interface{} {
+	start, end := page(c.query, len(keys))
+	resp := cvpapi.ConfigletInfo{Total: len(keys),
+		ConfigletMapper: map[string]cvpapi.ConfigletMapping{},
+		ConfigletList:   []cvpapi.Configlet{}}
+	for _, key := range keys[start:end] {
+		if configlet := s.configlet(key); configlet != nil {
+			resp.ConfigletList = append(resp.ConfigletList, *configlet)
+		}
+	}
+	return resp
+}
+
+func getDeviceConfiglets(s *State, c *call) interface{} {
+	d := s.device(c.query.Get("netElementId"))
+	if d == nil {
+		return cvpError(cvpapi.NETELEMENT_ENTITY_DOES_NOT_EXIST, "No device "+
+			c.query.Get("netElementId"))
+	}
+	return configletInfo(s, d.configlets, c)
+}
+
+func getContainerConfiglets(s *State, c *call) interface{} {
+	cont := s.container(c.query.Get("containerId"))
+	if cont == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No container "+
+			c.query.Get("containerId"))
+	}
+	return configletInfo(s, cont.configlets, c)
+}
+
+func addTempAction(s *State, c *call) interface{} {
+	var req struct {
+		Data []cvpapi.Action `json:"data"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	for i := range req.Data {
+		if err := s.validateAction(&req.Data[i]); err != nil {
+			return err
+		}
+	}
+	for _, action := range req.Data {
+		action.ID = s.nextID()
+		s.tempActions = append(s.tempActions, action)
+	}
+	return data{Data: "success"}
+}
+
+func getTempActions(s *State, c *call) interface{} {
+	start, end := page(c.query, len(s.tempActions))
+	return list{Total: len(s.tempActions),
+		Data: append([]cvpapi.Action{}, s.tempActions[start:end]...)}
+}
+
+func deleteTempActions(s *State, c *call) interface{} {
+	s.tempActions = nil
+	return data{Data: "success"}
+}
+
+func saveTopology(s *State, c *call) interface{} {
+	taskIDs, err := s.saveTopology()
+	if err != nil {
+		return err
+	}
+	if taskIDs == nil {
+		taskIDs = []string{}
+	}
+	return data{Data: cvpapi.TaskInfo{TaskIDs: taskIDs, Status: "success"}}
+}
+
+func getTask(s *State, c *call) interface{} {
+	id, _ := strconv.Atoi(c.query.Get("taskId"))
+	t := s.task(id)
+	if t == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No task "+c.query.Get("taskId"))
+	}
+	t.poll(s)
+	return t.CvpTask
+}
+
+func getTasks(s *State, c *call) interface{} {
+	var tasks []*task
+	for _, t := range s.tasks {
+		if matches(c.query.Get("queryparam"), t.WorkOrderUserDefinedStatus, t.WorkOrderID,
+			t.Description, t.WorkOrderDetails.NetElementHostName) {
+			tasks = append(tasks, t)
+		}
+	}
+	start, end := page(c.query, len(tasks))
+	resp := cvpapi.CvpTaskList{Total: len(tasks), Data: []cvpapi.CvpTask{}}
+	for _, t := range tasks[start:end] {
+		resp.Data = append(resp.Data, t.CvpTask)
+	}
+	return resp
+}
+
+func getLogs(s *State, c *call) interface{} {
+	id, _ := strconv.Atoi(c.query.Get("id"))
+	t := s.task(id)
+	if t == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No task "+c.query.Get("id"))
+	}
+	start, end := page(c.query, len(t.logs))
+	return cvpapi.CvpLogList{Total: len(t.logs),
+		Data: append([]cvpapi.LogData{}, t.logs[start:end]...)}
+}
+
+func addNoteToTask(s *State, c *call) interface{} {
+	var req struct {
+		WorkOrderID string `json:"workOrderId"`
+		Note        string `json:"note"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	id, _ := strconv.Atoi(req.WorkOrderID)
+	t := s.task(id)
+	if t == nil {
+		return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No task "+req.WorkOrderID)
+	}
+	t.Note = req.Note
+	return data{Data: "success"}
+}
+
+func executeTask(s *State, c *call) interface{} {
+	var req struct {
+		Data []string `json:"data"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	if err := s.execute(req.Data); err != nil {
+		return err
+	}
+	return data{Data: "success"}
+}
+
+func cancelTask(s *State, c *call) interface{} {
+	var req struct {
+		Data []string `json:"data"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	if err := s.cancel(req.Data); err != nil {
+		return err
+	}
+	return data{Data: "success"}
+}
+
+func getChangeControls(s *State, c *call) interface{} {
+	var ccs []*changeControl
+	for _, cc := range s.changeControls {
+		if matches(c.query.Get("queryparam"), cc.CcName, cc.CcID, cc.Status) {
+			ccs = append(ccs, cc)
+		}
+	}
+	start, end := page(c.query, len(ccs))
+	resp := cvpapi.ChangeControlList{Total: len(ccs), Data: []cvpapi.ChangeControl{}}
+	for _, cc := range ccs[start:end] {
+		resp.Data = append(resp.Data, cc.ChangeControl)
+	}
+	return resp
+}
+
+// changeControlOf returns the change control a task was added to
+func (s *State) changeControlOf(id int) *changeControl {
+	for _, cc := range s.changeControls {
+		for _, taskID := range cc.tasks {
+			if taskID == id {
+				return cc
+			}
+		}
+	}
+	return nil
+}
+
+func getCCTasks(s *State, c *call) interface{} {
+	var tasks []*task
+	for _, t := range s.tasks {
+		if t.WorkOrderUserDefinedStatus == TaskPending && s.changeControlOf(t.ID) == nil &&
+			matches(c.query.Get("queryparam"), t.WorkOrderID, t.Description,
+				t.WorkOrderDetails.NetElementHostName) {
+			tasks = append(tasks, t)
+		}
+	}
+	start, end := page(c.query, len(tasks))
+	resp := cvpapi.ChangeControlTaskList{Total: len(tasks),
+		Data: []cvpapi.ChangeControlTask{}}
+	for _, t := range tasks[start:end] {
+		d := s.device(t.WorkOrderDetails.NetElementID)
+		cct := cvpapi.ChangeControlTask{
+			CurrentTaskName:            t.CurrentTaskName,
+			Description:                t.Description,
+			CreatedOnInLongFormat:      t.CreatedOnInLongFormat,
+			WorkOrderID:                t.WorkOrderID,
+			NetElementHostName:         t.WorkOrderDetails.NetElementHostName,
+			Note:                       t.Note,
+			NetElementID:               t.WorkOrderDetails.NetElementID,
+			CreatedBy:                  t.CreatedBy,
+			TemplateID:                 t.TemplateID,
+			WorkOrderUserDefinedStatus: t.WorkOrderUserDefinedStatus,
+			IPAddress:                  t.WorkOrderDetails.IPAddress,
+			WorkOrderState:             t.WorkOrderState,
+		}
+		if d != nil {
+			cct.Model = d.ModelName
+			if cont := s.container(d.ParentContainerKey); cont != nil {
+				cct.ContainerName = cont.Name
+			}
+		}
+		resp.Data = append(resp.Data, cct)
+	}
+	return resp
+}
+
+func addChangeControl(s *State, c *call) interface{} {
+	var req struct {
+		CcID                string                         `json:"ccId"`
+		CcName              string                         `json:"ccName"`
+		TimeZone            string                         `json:"timeZone"`
+		CountryID           string                         `json:"countryId"`
+		DateTime            string                         `json:"dateTime"`
+		Type                string                         `json:"type"`
+		StopOnError         string                         `json:"stopOnError"`
+		ChangeControlTasks  []cvpapi.ChangeControlTaskInfo `json:"changeControlTasks"`
+		SnapshotTemplateKey string                         `json:"snapshotTemplateKey"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+
+	cc := &changeControl{}
+	if req.CcID != "" {
+		for _, existing := range s.changeControls {
+			if existing.CcID == req.CcID {
+				cc = existing
+			}
+		}
+		if cc.CcID == "" {
+			return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No change control "+req.CcID)
+		}
+	}
+
+	var ids []int
+	devices := make(map[string]bool)
+	for _, cct := range req.ChangeControlTasks {
+		tasks, err := s.tasksByID([]string{cct.TaskID}, TaskPending)
+		if err != nil {
+			return err
+		}
+		if other := s.changeControlOf(tasks[0].ID); other != nil && other != cc {
+			return cvpError("", "Task "+cct.TaskID+" is in change control "+other.CcID)
+		}
+		ids = append(ids, tasks[0].ID)
+		devices[tasks[0].WorkOrderDetails.NetElementID] = true
+	}
+
+	if cc.CcID == "" {
+		id := strconv.Itoa(s.nextID())
+		cc.CcID, cc.Key, cc.ID = id, id, len(s.changeControls)+1
+		cc.CreatedBy = s.username
+		cc.CreatedTimestamp = timestamp()
+		cc.Status = TaskPending
+		s.changeControls = append(s.changeControls, cc)
+	}
+	cc.CcName = req.CcName
+	cc.TimeZone = req.TimeZone
+	cc.CountryID = req.CountryID
+	cc.DateTime = req.DateTime
+	cc.Type = req.Type
+	cc.StopOnError = req.StopOnError == "true"
+	cc.StopOnErrorStatus = req.StopOnError
+	cc.tasks = ids
+	cc.TaskCount = len(ids)
+	cc.DeviceCount = len(devices)
+	return cvpapi.AddOrUpdateChangeControlResp{Data: "success", CcID: cc.CcID}
+}
+
+func addCCNotes(s *State, c *call) interface{} {
+	var req struct {
+		CcID  string `json:"ccId"`
+		Notes string `json:"notes"`
+	}
+	if err := c.decode(&req); err != nil {
+		return invalidBody(err)
+	}
+	for _, cc := range s.changeControls {
+		if cc.CcID == req.CcID {
+			cc.Notes = req.Notes
+			return cvpapi.AddNotesToChangeControlResp{Data: "success"}
+		}
+	}
+	return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No change control "+req.CcID)
+}
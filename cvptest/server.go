@@ -0,0 +1,264 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+// Package cvptest provides a fake CVP for tests. It serves the endpoints
+// used by go-cvprac from in-memory state, so workflows like DeployDevice,
+// ExecuteTask and GetTaskByID can be tested without a lab CVP. Several
+// nodes sharing one State make a cluster to test failover.
+package cvptest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aristanetworks/go-cvprac/client"
+	"github.com/pkg/errors"
+)
+
+// call is a request made to a fake CVP
+type call struct {
+	w     http.ResponseWriter
+	r     *http.Request
+	query url.Values
+	body  []byte
+}
+
+// decode unmarshals the JSON body of the request into v
+func (c *call) decode(v interface{}) error {
+	return json.Unmarshal(c.body, v)
+}
+
+// handler serves an endpoint with the state locked. The returned value is
+// sent as JSON.
+type handler func(s *State, c *call) interface{}
+
+type route struct {
+	method  string
+	handler handler
+	public  bool
+}
+
+// Server is a node of a fake CVP
+type Server struct {
+	*httptest.Server
+
+	// Name is the host name of the node in a Cluster
+	Name  string
+	State *State
+	// Faults are injected into the requests to the node
+	Faults *Injector
+
+	down     int32
+	requests int64
+}
+
+// NewServer starts a single node fake CVP. The caller should call Close
+// when finished.
+func NewServer(opts ...Option) *Server {
+	return newServer("", NewState(opts...))
+}
+
+func newServer(name string, state *State) *Server {
+	s := &Server{Name: name, State: state, Faults: NewInjector()}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// SetDown makes the node answer every request with 503 Service Unavailable
+// while down is set, like a CVP node that is restarting
+func (s *Server) SetDown(down bool) {
+	var v int32
+	if down {
+		v = 1
+	}
+	atomic.StoreInt32(&s.down, v)
+}
+
+// Requests returns the number of requests the node received
+func (s *Server) Requests() int {
+	return int(atomic.LoadInt64(&s.requests))
+}
+
+// ClientOptions returns the options to connect a client.CvpClient to the
+// node
+func (s *Server) ClientOptions() []client.Option {
+	u, _ := url.Parse(s.URL)
+	port, _ := strconv.Atoi(u.Port())
+	return []client.Option{
+		client.Protocol("http"),
+		client.Hosts(u.Hostname()),
+		client.Port(port),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&s.requests, 1)
+	if atomic.LoadInt32(&s.down) != 0 {
+		http.Error(w, "CVP node down", http.StatusServiceUnavailable)
+		return
+	}
+	if s.Faults.serve(w, r) {
+		return
+	}
+	rt, ok := routes[strings.TrimPrefix(r.URL.Path, "/web")]
+	if !ok || !strings.HasPrefix(r.URL.Path, "/web/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != rt.method {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.State.mu.Lock()
+	if !rt.public && !s.State.authorized(r) {
+		s.State.mu.Unlock()
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	resp := rt.handler(s.State, &call{w: w, r: r, query: r.URL.Query(), body: body})
+	s.State.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// authorized reports whether the request carries a session or the token
+func (s *State) authorized(r *http.Request) bool {
+	if cookie, err := r.Cookie("session_id"); err == nil && s.sessions[cookie.Value] {
+		return true
+	}
+	return s.token != "" && r.Header.Get("Authorization") == "Bearer "+s.token
+}
+
+// newSession returns a new session id
+func (s *State) newSession() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	id := hex.EncodeToString(buf)
+	s.sessions[id] = true
+	return id
+}
+
+// ExpireSessions logs out every client, e.g. to test logging in again
+func (s *State) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]bool)
+}
+
+// Cluster is a multi-node fake CVP. Its nodes are named cvp1, cvp2... and
+// only reachable through Dial since they all listen on the loopback
+// address.
+type Cluster struct {
+	Nodes []*Server
+	State *State
+}
+
+// NewCluster starts a fake CVP of nodes nodes sharing one State. The caller
+// should call Close when finished.
+func NewCluster(nodes int, opts ...Option) *Cluster {
+	c := &Cluster{State: NewState(opts...)}
+	for i := 1; i <= nodes; i++ {
+		c.Nodes = append(c.Nodes, newServer("cvp"+strconv.Itoa(i), c.State))
+	}
+	return c
+}
+
+// Hosts returns the names of the nodes
+func (c *Cluster) Hosts() []string {
+	hosts := make([]string, 0, len(c.Nodes))
+	for _, node := range c.Nodes {
+		hosts = append(hosts, node.Name)
+	}
+	return hosts
+}
+
+// Node returns the node named name or nil
+func (c *Cluster) Node(name string) *Server {
+	for _, node := range c.Nodes {
+		if node.Name == name {
+			return node
+		}
+	}
+	return nil
+}
+
+// Dial connects to the node named by the host of addr, see
+// client.DialContext
+func (c *Cluster) Dial(ctx context.Context, network string, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "Dial")
+	}
+	node := c.Node(host)
+	if node == nil {
+		return nil, errors.Errorf("Dial: No CVP node [%s]", host)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, node.Listener.Addr().String())
+}
+
+// ClientOptions returns the options to connect a client.CvpClient to all
+// nodes of the cluster
+func (c *Cluster) ClientOptions() []client.Option {
+	return []client.Option{
+		client.Protocol("http"),
+		client.Hosts(c.Hosts()...),
+		client.Proxy(""),
+		client.DialContext(c.Dial),
+	}
+}
+
+// Close shuts down all nodes
+func (c *Cluster) Close() {
+	for _, node := range c.Nodes {
+		node.Close()
+	}
+}
@@ -0,0 +1,454 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvptest
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
+	"github.com/pkg/errors"
+)
+
+// Keys of the containers every CVP has
+const (
+	RootContainerKey      = "root"
+	UndefinedContainerKey = "undefined_container"
+)
+
+// Task states reported in CvpTask.WorkOrderUserDefinedStatus
+const (
+	TaskPending    = "Pending"
+	TaskInProgress = "In-Progress"
+	TaskCompleted  = "Completed"
+	TaskFailed     = "Failed"
+	TaskCancelled  = "Cancelled"
+)
+
+// Option configures the State of a fake CVP
+type Option func(*State)
+
+// Credentials sets the username and password accepted by the login
+// endpoint. The default is cvpadmin/cvp123.
+func Credentials(username string, password string) Option {
+	return func(s *State) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// Token sets a service account token accepted as bearer token instead of a
+// session.
+func Token(token string) Option {
+	return func(s *State) {
+		s.token = token
+	}
+}
+
+// Version sets the CVP version reported by getCvpInfo.do. The default is
+// 2020.2.3.
+func Version(version string) Option {
+	return func(s *State) {
+		s.version = version
+	}
+}
+
+// TaskPolls sets how many times an executed task is reported In-Progress by
+// getTaskById.do before completing. By default tasks complete as soon as
+// they are executed.
+func TaskPolls(polls int) Option {
+	return func(s *State) {
+		s.taskPolls = polls
+	}
+}
+
+type container struct {
+	cvpapi.Container
+	parent     string
+	configlets []string
+}
+
+type device struct {
+	cvpapi.NetElement
+	configlets []string
+}
+
+type task struct {
+	cvpapi.CvpTask
+	logs []cvpapi.LogData
+	// polls left before an In-Progress task completes, negative when the
+	// status was forced with SetTaskStatus
+	polls int
+}
+
+type changeControl struct {
+	cvpapi.ChangeControl
+	tasks []int
+}
+
+// State is the in-memory data of a fake CVP. The nodes of a Cluster share
+// one State like the nodes of a CVP cluster share their database.
+type State struct {
+	mu sync.Mutex
+
+	username  string
+	password  string
+	token     string
+	version   string
+	taskPolls int
+
+	sessions       map[string]bool
+	containers     []*container
+	devices        []*device
+	configlets     []*cvpapi.Configlet
+	tempActions    []cvpapi.Action
+	tasks          []*task
+	changeControls []*changeControl
+	lastID         int
+}
+
+// NewState returns the State of a fresh CVP with only the Tenant and
+// Undefined containers.
+func NewState(opts ...Option) *State {
+	s := &State{
+		username: "cvpadmin",
+		password: "cvp123",
+		version:  "2020.2.3",
+		sessions: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	now := timestamp()
+	s.containers = []*container{
+		{Container: cvpapi.Container{Key: RootContainerKey, Name: "Tenant",
+			CreatedBy: "cvp system", CreatedOn: now, Mode: "expand"}},
+		{Container: cvpapi.Container{Key: UndefinedContainerKey, Name: "Undefined",
+			CreatedBy: "cvp system", CreatedOn: now, Mode: "expand"},
+			parent: RootContainerKey},
+	}
+	return s
+}
+
+// AddContainer creates the container name under the container parentName
+func (s *State) AddContainer(name string, parentName string) (cvpapi.Container, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parent := s.containerByName(parentName)
+	if parent == nil {
+		return cvpapi.Container{}, errors.Errorf("AddContainer: No container [%s]", parentName)
+	}
+	cont, err := s.addContainer(name, parent.Key)
+	if err != nil {
+		return cvpapi.Container{}, errors.Wrap(err, "AddContainer")
+	}
+	return cont.Container, nil
+}
+
+// AddDevice adds dev to the inventory in the container containerName. Only
+// the SystemMacAddress of dev is required, the key, hostname and status are
+// filled in when not set.
+func (s *State) AddDevice(dev cvpapi.NetElement, containerName string) (cvpapi.NetElement,
+	error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if dev.SystemMacAddress == "" {
+		return cvpapi.NetElement{}, errors.New("AddDevice: No SystemMacAddress")
+	}
+	if s.device(dev.SystemMacAddress) != nil {
+		return cvpapi.NetElement{}, errors.Errorf("AddDevice: Device [%s] already exists",
+			dev.SystemMacAddress)
+	}
+	cont := s.containerByName(containerName)
+	if cont == nil {
+		return cvpapi.NetElement{}, errors.Errorf("AddDevice: No container [%s]",
+			containerName)
+	}
+	d := s.addDevice(dev, cont.Key)
+	return d.NetElement, nil
+}
+
+// AddConfiglet creates a static configlet
+func (s *State) AddConfiglet(name string, config string) (cvpapi.Configlet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	configlet, err := s.addConfiglet(name, config)
+	if err != nil {
+		return cvpapi.Configlet{}, errors.Wrap(err, "AddConfiglet")
+	}
+	return *configlet, nil
+}
+
+// Device returns the device with the system MAC address mac
+func (s *State) Device(mac string) (cvpapi.NetElement, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d := s.device(mac); d != nil {
+		return d.NetElement, true
+	}
+	return cvpapi.NetElement{}, false
+}
+
+// DeviceConfiglets returns the names of the configlets applied directly to
+// the device with the system MAC address mac
+func (s *State) DeviceConfiglets(mac string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.device(mac)
+	if d == nil {
+		return nil
+	}
+	return s.configletNames(d.configlets)
+}
+
+// ContainerConfiglets returns the names of the configlets applied to the
+// container name
+func (s *State) ContainerConfiglets(name string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cont := s.containerByName(name)
+	if cont == nil {
+		return nil
+	}
+	return s.configletNames(cont.configlets)
+}
+
+// TempActions returns the actions waiting for saveTopology.do
+func (s *State) TempActions() []cvpapi.Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]cvpapi.Action(nil), s.tempActions...)
+}
+
+// Task returns the task with the given id
+func (s *State) Task(id int) (cvpapi.CvpTask, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := s.task(id); t != nil {
+		return t.CvpTask, true
+	}
+	return cvpapi.CvpTask{}, false
+}
+
+// Tasks returns all tasks in the order they were created
+func (s *State) Tasks() []cvpapi.CvpTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := make([]cvpapi.CvpTask, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		tasks = append(tasks, t.CvpTask)
+	}
+	return tasks
+}
+
+// SetTaskStatus forces the state of a task, e.g. to make it fail
+func (s *State) SetTaskStatus(id int, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.task(id)
+	if t == nil {
+		return errors.Errorf("SetTaskStatus: No task [%d]", id)
+	}
+	switch status {
+	case TaskPending, TaskInProgress, TaskCompleted, TaskFailed, TaskCancelled:
+	default:
+		return errors.Errorf("SetTaskStatus: Invalid status [%s]", status)
+	}
+	t.setStatus(status)
+	t.polls = -1
+	return nil
+}
+
+// ChangeControls returns all change controls in the order they were created
+func (s *State) ChangeControls() []cvpapi.ChangeControl {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ccs := make([]cvpapi.ChangeControl, 0, len(s.changeControls))
+	for _, cc := range s.changeControls {
+		ccs = append(ccs, cc.ChangeControl)
+	}
+	return ccs
+}
+
+// nextID returns a new id, unique across all object types
+func (s *State) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+func (s *State) addContainer(name string, parentKey string) (*container, error) {
+	if s.containerByName(name) != nil {
+		return nil, errors.Errorf("Container [%s] already exists", name)
+	}
+	cont := &container{
+		Container: cvpapi.Container{
+			Key:       "container_" + strconv.Itoa(s.nextID()),
+			Name:      name,
+			CreatedBy: s.username,
+			CreatedOn: timestamp(),
+			Mode:      "expand",
+		},
+		parent: parentKey,
+	}
+	s.containers = append(s.containers, cont)
+	return cont, nil
+}
+
+func (s *State) addDevice(dev cvpapi.NetElement, containerKey string) *device {
+	dev.Key = dev.SystemMacAddress
+	dev.Type = "netelement"
+	dev.ParentContainerKey = containerKey
+	if dev.Hostname == "" {
+		dev.Hostname = strings.SplitN(dev.Fqdn, ".", 2)[0]
+	}
+	if dev.Fqdn == "" {
+		dev.Fqdn = dev.Hostname
+	}
+	if dev.ComplianceCode == "" {
+		dev.ComplianceCode = "0000"
+	}
+	if dev.DeviceStatus == "" {
+		dev.DeviceStatus = "Registered"
+	}
+	dev.LastSyncUp = timestamp()
+	d := &device{NetElement: dev}
+	s.devices = append(s.devices, d)
+	return d
+}
+
+func (s *State) addConfiglet(name string, config string) (*cvpapi.Configlet, error) {
+	if s.configletByName(name) != nil {
+		return nil, errors.Errorf("Configlet [%s] already exists", name)
+	}
+	id := s.nextID()
+	configlet := &cvpapi.Configlet{
+		IsDefault:            "no",
+		DateTimeInLongFormat: timestamp(),
+		IsAutoBuilder:        "",
+		Config:               config,
+		User:                 s.username,
+		Name:                 name,
+		Key:                  "configlet_" + strconv.Itoa(id),
+		ID:                   id,
+		Type:                 "Static",
+	}
+	s.configlets = append(s.configlets, configlet)
+	return configlet, nil
+}
+
+func (s *State) container(key string) *container {
+	for _, cont := range s.containers {
+		if cont.Key == key {
+			return cont
+		}
+	}
+	return nil
+}
+
+// containerByName finds a container, names are not case sensitive
+func (s *State) containerByName(name string) *container {
+	for _, cont := range s.containers {
+		if strings.EqualFold(cont.Name, name) {
+			return cont
+		}
+	}
+	return nil
+}
+
+func (s *State) device(mac string) *device {
+	for _, d := range s.devices {
+		if d.SystemMacAddress == mac {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *State) configlet(key string) *cvpapi.Configlet {
+	for _, configlet := range s.configlets {
+		if configlet.Key == key {
+			return configlet
+		}
+	}
+	return nil
+}
+
+func (s *State) configletByName(name string) *cvpapi.Configlet {
+	for _, configlet := range s.configlets {
+		if configlet.Name == name {
+			return configlet
+		}
+	}
+	return nil
+}
+
+func (s *State) configletNames(keys []string) []string {
+	var names []string
+	for _, key := range keys {
+		if configlet := s.configlet(key); configlet != nil {
+			names = append(names, configlet.Name)
+		}
+	}
+	return names
+}
+
+func (s *State) task(id int) *task {
+	for _, t := range s.tasks {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// inContainer reports whether the container key is cont or one of its
+// descendants
+func (s *State) inContainer(key string, cont string) bool {
+	for key != "" {
+		if key == cont {
+			return true
+		}
+		c := s.container(key)
+		if c == nil {
+			return false
+		}
+		key = c.parent
+	}
+	return false
+}
+
+// timestamp returns the current time in milliseconds like CVP
+func timestamp() int64 {
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
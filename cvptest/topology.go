@@ -0,0 +1,361 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package cvptest
+
+import (
+	"strconv"
+
+	cvpapi "github.com/aristanetworks/go-cvprac/api"
+)
+
+// cvpError returns the error body CVP answers failed requests with
+func cvpError(code string, msg string) *cvpapi.ErrorResponse {
+	return &cvpapi.ErrorResponse{ErrorCode: code, ErrorMessage: msg}
+}
+
+// validateAction checks a temp action before it is queued
+func (s *State) validateAction(a *cvpapi.Action) *cvpapi.ErrorResponse {
+	switch {
+	case a.NodeType == "netelement" && (a.Action == "update" || a.Action == "reset"):
+		if s.device(a.NodeID) == nil {
+			return cvpError(cvpapi.NETELEMENT_ENTITY_DOES_NOT_EXIST,
+				"No device "+a.NodeID)
+		}
+		if s.container(a.ToID) == nil {
+			return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No container "+a.ToID)
+		}
+	case a.NodeType == "configlet" && a.Action == "associate":
+		if err := s.validateTarget(a); err != nil {
+			return err
+		}
+		for _, keys := range [][]string{a.ConfigletList, a.ConfigletBuilderList} {
+			for _, key := range keys {
+				if s.configlet(key) == nil {
+					return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No configlet "+key)
+				}
+			}
+		}
+	case a.NodeType == "imagebundle" && a.Action == "associate":
+		return s.validateTarget(a)
+	case a.NodeType == "container" && a.Action == "add":
+		if s.container(a.ToID) == nil {
+			return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No container "+a.ToID)
+		}
+		if s.containerByName(a.NodeName) != nil {
+			return cvpError(cvpapi.DATA_ALREADY_EXISTS,
+				"Container "+a.NodeName+" already exists")
+		}
+	case a.NodeType == "container" && a.Action == "delete":
+		if a.NodeID == RootContainerKey || a.NodeID == UndefinedContainerKey {
+			return cvpError("", "Container "+a.NodeName+" can't be deleted")
+		}
+		if s.container(a.NodeID) == nil {
+			return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No container "+a.NodeID)
+		}
+	default:
+		return cvpError("", "Unsupported action "+a.Action+" of "+a.NodeType)
+	}
+	return nil
+}
+
+// validateTarget checks the device or container an action applies to
+func (s *State) validateTarget(a *cvpapi.Action) *cvpapi.ErrorResponse {
+	switch a.ToIDType {
+	case "netelement":
+		if s.device(a.ToID) == nil {
+			return cvpError(cvpapi.NETELEMENT_ENTITY_DOES_NOT_EXIST, "No device "+a.ToID)
+		}
+	case "container":
+		if s.container(a.ToID) == nil {
+			return cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No container "+a.ToID)
+		}
+	default:
+		return cvpError("", "Invalid toIdType "+a.ToIDType)
+	}
+	return nil
+}
+
+// saveTopology applies the temp actions and returns the ids of the tasks
+// created for the devices they change. Like CVP the topology changes right
+// away, executing the tasks only pushes the configuration to the devices.
+func (s *State) saveTopology() ([]string, *cvpapi.ErrorResponse) {
+	actions := s.tempActions
+	s.tempActions = nil
+
+	var taskIDs []string
+	tasks := make(map[string]*task)
+	addTask := func(d *device, a *cvpapi.Action, workflow string) {
+		if t, ok := tasks[d.SystemMacAddress]; ok {
+			if a.NodeType == "netelement" {
+				t.Data.NewparentContainerID = a.ToID
+			}
+			return
+		}
+		t := s.newTask(d, a, workflow)
+		tasks[d.SystemMacAddress] = t
+		taskIDs = append(taskIDs, t.WorkOrderID)
+	}
+
+	for i := range actions {
+		a := &actions[i]
+		if err := s.validateAction(a); err != nil {
+			return taskIDs, err
+		}
+		switch {
+		case a.NodeType == "netelement" && a.Action == "update":
+			d := s.device(a.NodeID)
+			addTask(d, a, "Device Add")
+			d.ParentContainerKey = a.ToID
+		case a.NodeType == "netelement" && a.Action == "reset":
+			d := s.device(a.NodeID)
+			addTask(d, a, "Device Reset")
+			d.ParentContainerKey = UndefinedContainerKey
+			d.configlets = nil
+		case a.NodeType == "configlet" && a.ToIDType == "netelement":
+			d := s.device(a.ToID)
+			d.configlets = configletKeys(a)
+			addTask(d, a, "Configlet Push")
+		case a.NodeType == "configlet":
+			s.container(a.ToID).configlets = configletKeys(a)
+			for _, d := range s.devicesIn(a.ToID) {
+				addTask(d, a, "Configlet Push")
+			}
+		case a.NodeType == "imagebundle" && a.ToIDType == "netelement":
+			addTask(s.device(a.ToID), a, "Image Push")
+		case a.NodeType == "imagebundle":
+			for _, d := range s.devicesIn(a.ToID) {
+				addTask(d, a, "Image Push")
+			}
+		case a.Action == "add":
+			if _, err := s.addContainer(a.NodeName, a.ToID); err != nil {
+				return taskIDs, cvpError(cvpapi.DATA_ALREADY_EXISTS, err.Error())
+			}
+		case a.Action == "delete":
+			if err := s.deleteContainer(a.NodeID); err != nil {
+				return taskIDs, err
+			}
+		}
+	}
+	return taskIDs, nil
+}
+
+// configletKeys returns the configlets an associate action leaves applied
+func configletKeys(a *cvpapi.Action) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, list := range [][]string{a.ConfigletList, a.ConfigletBuilderList} {
+		for _, key := range list {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// devicesIn returns the devices in the container key or its descendants
+func (s *State) devicesIn(key string) []*device {
+	var devices []*device
+	for _, d := range s.devices {
+		if s.inContainer(d.ParentContainerKey, key) {
+			devices = append(devices, d)
+		}
+	}
+	return devices
+}
+
+func (s *State) deleteContainer(key string) *cvpapi.ErrorResponse {
+	for _, d := range s.devices {
+		if d.ParentContainerKey == key {
+			return cvpError("", "Container "+key+" has devices")
+		}
+	}
+	idx := -1
+	for i, cont := range s.containers {
+		if cont.parent == key {
+			return cvpError("", "Container "+key+" has child containers")
+		}
+		if cont.Key == key {
+			idx = i
+		}
+	}
+	s.containers = append(s.containers[:idx], s.containers[idx+1:]...)
+	return nil
+}
+
+func (s *State) newTask(d *device, a *cvpapi.Action, workflow string) *task {
+	id := s.nextID()
+	now := timestamp()
+	description := a.Info
+	if description == "" {
+		description = workflow + ": " + d.Fqdn
+	}
+	t := &task{
+		CvpTask: cvpapi.CvpTask{
+			TemplateID:      "BasicTemplate",
+			CurrentTaskType: "User Task",
+			CreatedBy:       s.username,
+			WorkOrderID:     strconv.Itoa(id),
+			WorkOrderDetails: cvpapi.WorkOrderDetail{
+				NetElementID:       d.SystemMacAddress,
+				NetElementHostName: d.Fqdn,
+				IPAddress:          d.IPAddress,
+				SerialNumber:       d.SerialNumber,
+			},
+			CreatedOnInLongFormat: now,
+			Data: cvpapi.WorkData{
+				WorkFlowAction:           workflow,
+				CurrentparentContainerID: d.ParentContainerKey,
+				View:                     "CONFIG",
+				NewparentContainerID:     d.ParentContainerKey,
+				NetElementID:             d.SystemMacAddress,
+				IsConfigPushNeeded:       "yes",
+			},
+			Description: description,
+			Name:        workflow,
+			ID:          id,
+		},
+	}
+	if a.NodeType == "netelement" {
+		t.Data.NewparentContainerID = a.ToID
+	}
+	t.setStatus(TaskPending)
+	t.log(s, "Task created")
+	s.tasks = append(s.tasks, t)
+	return t
+}
+
+// setStatus updates all the status fields of the task
+func (t *task) setStatus(status string) {
+	t.WorkOrderUserDefinedStatus = status
+	switch status {
+	case TaskPending:
+		t.WorkOrderState, t.CurrentTaskName = "ACTIVE", "Submit"
+	case TaskInProgress:
+		t.WorkOrderState, t.CurrentTaskName = "ACTIVE", "Execute"
+	case TaskCompleted:
+		t.WorkOrderState, t.CurrentTaskName = "COMPLETED", ""
+	case TaskFailed:
+		t.WorkOrderState, t.CurrentTaskName = "FAILED", ""
+	case TaskCancelled:
+		t.WorkOrderState, t.CurrentTaskName = "CANCELLED", ""
+	}
+	t.TaskStatus = t.WorkOrderState
+}
+
+func (t *task) log(s *State, details string) {
+	t.logs = append(t.logs, cvpapi.LogData{
+		DateTimeInLongFormat: timestamp(),
+		LogDetails:           details,
+		WorkOrderID:          t.WorkOrderID,
+		ObjectName:           t.WorkOrderDetails.NetElementHostName,
+		UserName:             s.username,
+		Key:                  t.WorkOrderID + "_" + strconv.Itoa(len(t.logs)+1),
+		ID:                   len(t.logs) + 1,
+	})
+}
+
+// poll advances an executed task each time its status is read
+func (t *task) poll(s *State) {
+	if t.WorkOrderUserDefinedStatus != TaskInProgress || t.polls < 0 {
+		return
+	}
+	if t.polls > 0 {
+		t.polls--
+		return
+	}
+	t.setStatus(TaskCompleted)
+	t.log(s, "Task completed")
+}
+
+// execute starts the tasks, which must all be pending or failed
+func (s *State) execute(ids []string) *cvpapi.ErrorResponse {
+	tasks, err := s.tasksByID(ids, TaskPending, TaskFailed)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		t.ExecutedBy = s.username
+		t.ExecutedOnInLongFormat = timestamp()
+		t.setStatus(TaskInProgress)
+		t.polls = s.taskPolls
+		t.log(s, "Task executed")
+		if t.polls == 0 {
+			t.poll(s)
+		}
+	}
+	return nil
+}
+
+// cancel cancels the tasks, which must all be pending
+func (s *State) cancel(ids []string) *cvpapi.ErrorResponse {
+	tasks, err := s.tasksByID(ids, TaskPending)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		t.setStatus(TaskCancelled)
+		t.log(s, "Task cancelled")
+	}
+	return nil
+}
+
+// tasksByID looks up the tasks, checking they are in one of the states
+func (s *State) tasksByID(ids []string, states ...string) ([]*task,
+	*cvpapi.ErrorResponse) {
+	var tasks []*task
+	for _, id := range ids {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, cvpError("", "Invalid task id "+id)
+		}
+		t := s.task(n)
+		if t == nil {
+			return nil, cvpError(cvpapi.ENTITY_DOES_NOT_EXIST, "No task "+id)
+		}
+		if !oneOf(t.WorkOrderUserDefinedStatus, states...) {
+			return nil, cvpError("", "Task "+id+" is "+t.WorkOrderUserDefinedStatus)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+func oneOf(s string, values ...string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
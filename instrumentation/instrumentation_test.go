@@ -0,0 +1,153 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package instrumentation
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aristanetworks/go-cvprac/client"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTestClient returns a client connected to a server that fails the first
+// request with a 401 and then accepts all requests
+func newTestClient(t *testing.T, middleware ...client.Middleware) (*client.CvpClient, func()) {
+	var hits int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/web/login/authenticate.do" {
+			fmt.Fprintf(w, `{ "sessionId": "1" }`)
+			return
+		}
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{}`)
+	}))
+
+	u, _ := url.Parse(ts.URL)
+	port, _ := strconv.Atoi(u.Port())
+	cvpClient, err := client.NewCvpClient(
+		client.Protocol("http"),
+		client.Hosts(u.Hostname()),
+		client.Port(port),
+		client.Middlewares(middleware...))
+	if err != nil {
+		t.Fatalf("NewCvpClient: %s", err)
+	}
+	if err := cvpClient.Connect("cvpadmin", "cvp123"); err != nil {
+		t.Fatalf("Connect: %s", err)
+	}
+	return cvpClient, ts.Close
+}
+
+func TestTracing_UnitTest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	cvpClient, closeFn := newTestClient(t, NewTracing(provider))
+	defer closeFn()
+
+	if _, err := cvpClient.Get("/cvpInfo/getCvpInfo.do", nil); err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	call := spans[2]
+	if call.Name() != "CVP GET /cvpInfo/getCvpInfo.do" {
+		t.Errorf("Unexpected call span name %q", call.Name())
+	}
+	for i, attempt := range spans[:2] {
+		if attempt.Parent().SpanID() != call.SpanContext().SpanID() {
+			t.Errorf("Attempt %d is not a child of the call span", i+1)
+		}
+	}
+	reason := ""
+	for _, attr := range spans[1].Attributes() {
+		if attr.Key == "cvp.attempt.reason" {
+			reason = attr.Value.AsString()
+		}
+	}
+	if reason != string(client.AttemptRelogin) {
+		t.Errorf("Expected relogin attempt, got %q", reason)
+	}
+}
+
+func TestMetrics_UnitTest(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := NewMetrics(registry)
+	if err != nil {
+		t.Fatalf("NewMetrics: %s", err)
+	}
+
+	cvpClient, closeFn := newTestClient(t, metrics)
+	defer closeFn()
+
+	if _, err := cvpClient.Get("/test", nil); err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+
+	expected := `
+# HELP cvprac_requests_total Requests sent to CVP, including retries.
+# TYPE cvprac_requests_total counter
+cvprac_requests_total{endpoint="/test",method="GET",status="200"} 1
+cvprac_requests_total{endpoint="/test",method="GET",status="401"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"cvprac_requests_total"); err != nil {
+		t.Error(err)
+	}
+	if count := testutil.CollectAndCount(metrics.relogins); count != 1 {
+		t.Errorf("Expected relogin metric, got %d", count)
+	}
+	if count := testutil.CollectAndCount(metrics.calls); count != 1 {
+		t.Errorf("Expected call latency metric, got %d", count)
+	}
+
+	// metrics can only be registered once
+	if _, err := NewMetrics(registry); err == nil {
+		t.Error("Expected error registering metrics twice")
+	}
+}
@@ -0,0 +1,126 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package instrumentation
+
+import (
+	"strconv"
+
+	"github.com/aristanetworks/go-cvprac/client"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a client.CallMiddleware that records Prometheus metrics for
+// the requests made to CVP:
+//
+//	cvprac_requests_total{method,endpoint,status}
+//	cvprac_request_duration_seconds{method,endpoint,status}
+//	cvprac_call_duration_seconds{method,endpoint}
+//	cvprac_relogins_total{host}
+//	cvprac_failovers_total{host}
+//
+// A request that got no response has status "error". Failovers are counted
+// against the host failed over to.
+type Metrics struct {
+	requests  *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	calls     *prometheus.HistogramVec
+	relogins  *prometheus.CounterVec
+	failovers *prometheus.CounterVec
+}
+
+var _ client.CallMiddleware = (*Metrics)(nil)
+
+// NewMetrics creates the metrics and registers them with registerer
+func NewMetrics(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cvprac_requests_total",
+			Help: "Requests sent to CVP, including retries.",
+		}, []string{"method", "endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cvprac_request_duration_seconds",
+			Help:    "Latency of the requests sent to CVP.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "endpoint", "status"}),
+		calls: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cvprac_call_duration_seconds",
+			Help:    "Latency of CVP calls, including retries and failovers.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "endpoint"}),
+		relogins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cvprac_relogins_total",
+			Help: "Requests retried after logging in to CVP again.",
+		}, []string{"host"}),
+		failovers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cvprac_failovers_total",
+			Help: "Requests retried after failing over to another CVP node.",
+		}, []string{"host"}),
+	}
+	for _, c := range []prometheus.Collector{m.requests, m.latency, m.calls,
+		m.relogins, m.failovers} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// BeforeCall implements client.CallMiddleware
+func (m *Metrics) BeforeCall(call *client.CallInfo) error {
+	return nil
+}
+
+// AfterCall records the latency of the call
+func (m *Metrics) AfterCall(call *client.CallInfo) {
+	m.calls.WithLabelValues(call.Method, call.Path).Observe(call.Duration.Seconds())
+}
+
+// BeforeRequest counts re-logins and failovers
+func (m *Metrics) BeforeRequest(req *client.RequestInfo) error {
+	switch req.Reason {
+	case client.AttemptRelogin:
+		m.relogins.WithLabelValues(req.Host).Inc()
+	case client.AttemptFailover:
+		m.failovers.WithLabelValues(req.Host).Inc()
+	}
+	return nil
+}
+
+// AfterResponse counts the request and records its latency
+func (m *Metrics) AfterResponse(req *client.RequestInfo, resp *client.ResponseInfo) {
+	status := "error"
+	if resp.Err == nil {
+		status = strconv.Itoa(resp.Status)
+	}
+	m.requests.WithLabelValues(req.Method, req.Path, status).Inc()
+	m.latency.WithLabelValues(req.Method, req.Path, status).Observe(resp.Duration.Seconds())
+}
@@ -0,0 +1,122 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+// Package instrumentation provides OpenTelemetry tracing and Prometheus
+// metrics for the go-cvprac client. It is a separate module so the client
+// doesn't depend on either.
+//
+//	metrics, err := instrumentation.NewMetrics(prometheus.DefaultRegisterer)
+//	cvpClient, err := client.NewCvpClient(client.Hosts(hosts...),
+//		client.Middlewares(instrumentation.NewTracing(nil), metrics))
+package instrumentation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aristanetworks/go-cvprac/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ client.CallMiddleware = (*Tracing)(nil)
+
+// TracerName is the name of the tracer used for the CVP spans
+const TracerName = "github.com/aristanetworks/go-cvprac"
+
+// Tracing is a client.CallMiddleware that creates a span for every CVP call
+// and a child span for every attempt made by the call (retries, re-logins
+// and failovers).
+type Tracing struct {
+	tracer trace.Tracer
+}
+
+// NewTracing returns Tracing using provider, or the global TracerProvider
+// if nil
+func NewTracing(provider trace.TracerProvider) *Tracing {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracing{tracer: provider.Tracer(TracerName)}
+}
+
+// BeforeCall starts the span for the call
+func (t *Tracing) BeforeCall(call *client.CallInfo) error {
+	call.Context = t.start(call.Context, fmt.Sprintf("CVP %s %s", call.Method, call.Path),
+		attribute.String("http.request.method", call.Method),
+		attribute.String("url.path", call.Path))
+	return nil
+}
+
+// AfterCall ends the span for the call
+func (t *Tracing) AfterCall(call *client.CallInfo) {
+	span := trace.SpanFromContext(call.Context)
+	span.SetAttributes(attribute.Int("cvp.attempts", call.Attempts))
+	if call.Err != nil {
+		span.RecordError(call.Err)
+		span.SetStatus(codes.Error, call.Err.Error())
+	}
+	span.End()
+}
+
+// BeforeRequest starts the span for an attempt
+func (t *Tracing) BeforeRequest(req *client.RequestInfo) error {
+	req.Context = t.start(req.Context, fmt.Sprintf("CVP %s attempt", req.Method),
+		attribute.String("server.address", req.Host),
+		attribute.Int("cvp.attempt", req.Attempt),
+		attribute.String("cvp.attempt.reason", string(req.Reason)))
+	return nil
+}
+
+// AfterResponse ends the span for an attempt
+func (t *Tracing) AfterResponse(req *client.RequestInfo, resp *client.ResponseInfo) {
+	span := trace.SpanFromContext(req.Context)
+	switch {
+	case resp.Err != nil:
+		span.RecordError(resp.Err)
+		span.SetStatus(codes.Error, resp.Err.Error())
+	case resp.Status >= 400:
+		span.SetStatus(codes.Error, fmt.Sprintf("Status [%d]", resp.Status))
+	}
+	if resp.Status != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.Status))
+	}
+	span.End()
+}
+
+func (t *Tracing) start(ctx context.Context, name string,
+	attrs ...attribute.KeyValue) context.Context {
+	ctx, _ = t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	return ctx
+}
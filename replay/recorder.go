@@ -0,0 +1,179 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package replay
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Option configures a Recorder or Replayer
+type Option func(*options) error
+
+type options struct {
+	secretKeys []string
+	match      MatchFunc
+}
+
+// SecretKeys adds JSON fields and query parameters to scrub on top of
+// DefaultSecretKeys
+func SecretKeys(keys ...string) Option {
+	return func(o *options) error {
+		o.secretKeys = append(o.secretKeys, keys...)
+		return nil
+	}
+}
+
+// Match sets how a Replayer matches requests to the recorded ones.
+// Defaults to DefaultMatch.
+func Match(match MatchFunc) Option {
+	return func(o *options) error {
+		if match == nil {
+			return errors.New("Match: nil match function")
+		}
+		o.match = match
+		return nil
+	}
+}
+
+func newOptions(opts []Option) (*options, error) {
+	o := &options{
+		secretKeys: append([]string(nil), DefaultSecretKeys...),
+		match:      DefaultMatch,
+	}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Recorder is an http.RoundTripper sending requests through another
+// transport and recording them, and their responses, with secrets scrubbed.
+// The responses are returned unscrubbed to the caller.
+type Recorder struct {
+	transport http.RoundTripper
+	scrub     *scrubber
+
+	mu      sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder returns a Recorder sending requests through transport, or
+// http.DefaultTransport if nil
+func NewRecorder(transport http.RoundTripper, opts ...Option) (*Recorder, error) {
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewRecorder")
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{transport: transport, scrub: newScrubber(o.secretKeys)}, nil
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Recorder")
+	}
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		out.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Recorder")
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: r.scrub.request(req, reqBody),
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.scrub.header(resp.Header),
+		},
+	}
+	interaction.Response.Body, interaction.Response.BodyBase64 =
+		encodeBody(r.scrub.body(respBody, isLogin(req.URL.Path)))
+
+	r.mu.Lock()
+	r.fixture.Interactions = append(r.fixture.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// Fixture returns the interactions recorded so far
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Fixture{
+		Interactions: append([]Interaction(nil), r.fixture.Interactions...),
+	}
+}
+
+// Save writes the interactions recorded so far to a fixture file
+func (r *Recorder) Save(path string) error {
+	return r.Fixture().Save(path)
+}
+
+// request returns the scrubbed recording of req
+func (s *scrubber) request(req *http.Request, body []byte) Request {
+	recorded := Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  s.query(req.URL.Query()),
+		Header: s.header(req.Header),
+	}
+	recorded.Body, recorded.BodyBase64 = encodeBody(s.body(body, isLogin(req.URL.Path)))
+	return recorded
+}
+
+// readBody reads and closes body, which may be nil
+func readBody(body io.ReadCloser) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
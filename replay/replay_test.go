@@ -0,0 +1,185 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package replay
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aristanetworks/go-cvprac/client"
+)
+
+// createCvpServer returns a fake CVP issuing a session on login and serving
+// getCvpInfo.do to logged in clients
+func createCvpServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		switch r.URL.Path {
+		case "/web/login/authenticate.do":
+			http.SetCookie(w, &http.Cookie{Name: "session_id", Value: "s3cr3t-session",
+				Path: "/web"})
+			fmt.Fprintf(w, `{ "sessionId": "s3cr3t-session", "username": "cvpadmin" }`)
+		case "/web/cvpInfo/getCvpInfo.do":
+			if cookie, err := r.Cookie("session_id"); err != nil || cookie.Value == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{ "version": "2020.1.0", "appVersion": "Phase_2" }`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newClient(t *testing.T, host string, port int, transport http.RoundTripper) *client.CvpClient {
+	cvpClient, err := client.NewCvpClient(client.Protocol("http"), client.Hosts(host),
+		client.Port(port), client.Transport(transport))
+	ok(t, err)
+	return cvpClient
+}
+
+func TestRecordReplay_UnitTest(t *testing.T) {
+	ts := createCvpServer(t)
+	defer ts.Close()
+	serverURL, err := url.Parse(ts.URL)
+	ok(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	ok(t, err)
+
+	recorder, err := NewRecorder(nil)
+	ok(t, err)
+	cvpClient := newClient(t, serverURL.Hostname(), port, recorder)
+	ok(t, cvpClient.Connect("cvpadmin", "p4ssw0rd"))
+	info, err := cvpClient.API.GetCvpInfo()
+	ok(t, err)
+	equals(t, "2020.1.0", info.Version)
+
+	dir, err := ioutil.TempDir("", "cvprac")
+	ok(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "testdata", "cvpinfo.json")
+	ok(t, recorder.Save(path))
+	data, err := ioutil.ReadFile(path)
+	ok(t, err)
+	for _, secret := range []string{"p4ssw0rd", "s3cr3t-session", "cvpadmin"} {
+		assert(t, !strings.Contains(string(data), secret), "Fixture contains %s", secret)
+	}
+	assert(t, strings.Contains(string(data), "Phase_2"), "Fixture misses response")
+
+	// the fixture replays without CVP and whatever the credentials
+	replayer, err := ReplayFile(path)
+	ok(t, err)
+	cvpClient = newClient(t, "cvp.example.invalid", 443, replayer)
+	ok(t, cvpClient.Connect("other", "password"))
+	replayed, err := cvpClient.API.GetCvpInfo()
+	ok(t, err)
+	equals(t, info, replayed)
+	equals(t, 0, replayer.Remaining())
+
+	// each interaction is only served once
+	_, err = cvpClient.API.GetCvpInfo()
+	assert(t, err != nil, "Expected error for request not recorded")
+}
+
+func TestReplayerMatch_UnitTest(t *testing.T) {
+	fixture := &Fixture{Interactions: []Interaction{
+		{
+			Request:  Request{Method: "GET", Path: "/web/task/getTasks.do"},
+			Response: Response{StatusCode: 200, Body: `{"state": "Pending"}`},
+		},
+		{
+			Request: Request{Method: "POST", Path: "/web/task/cancelTask.do",
+				Body: `{"data": ["1"], "user": "x"}`},
+			Response: Response{StatusCode: 200, Body: `{"data": "success"}`},
+		},
+		{
+			Request:  Request{Method: "GET", Path: "/web/task/getTasks.do"},
+			Response: Response{StatusCode: 200, Body: `{"state": "Completed"}`},
+		},
+	}}
+	replayer, err := NewReplayer(fixture)
+	ok(t, err)
+	httpClient := &http.Client{Transport: replayer}
+
+	get := func() string {
+		resp, err := httpClient.Get("http://cvp/web/task/getTasks.do")
+		ok(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		ok(t, err)
+		return string(body)
+	}
+	// identical requests are served in the recorded order
+	equals(t, `{"state": "Pending"}`, get())
+	equals(t, `{"state": "Completed"}`, get())
+
+	// JSON bodies match regardless of formatting
+	resp, err := httpClient.Post("http://cvp/web/task/cancelTask.do", "application/json",
+		strings.NewReader(`{"user":"x","data":["1"]}`))
+	ok(t, err)
+	resp.Body.Close()
+	equals(t, 200, resp.StatusCode)
+
+	_, err = httpClient.Get("http://cvp/web/task/getTasks.do?id=1")
+	assert(t, err != nil, "Expected error for unmatched request")
+
+	// a custom matcher can ignore the query
+	replayer, err = NewReplayer(fixture, Match(func(live, recorded *Request) bool {
+		return live.Method == recorded.Method && live.Path == recorded.Path
+	}))
+	ok(t, err)
+	httpClient.Transport = replayer
+	equals(t, `{"state": "Pending"}`, get())
+	_, err = NewReplayer(fixture, Match(nil))
+	assert(t, err != nil, "Expected error for nil matcher")
+}
+
+func TestFixtureBinaryBody_UnitTest(t *testing.T) {
+	body := []byte{0xff, 0x00, 0xfe}
+	encoded, isBase64 := encodeBody(body)
+	equals(t, true, isBase64)
+	decoded, err := decodeBody(encoded, isBase64)
+	ok(t, err)
+	equals(t, body, decoded)
+
+	_, err = LoadFixture("testdata/missing.json")
+	assert(t, err != nil, "Expected error for missing fixture")
+}
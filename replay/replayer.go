@@ -0,0 +1,180 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// MatchFunc reports whether the scrubbed live request matches a recorded
+// request
+type MatchFunc func(live *Request, recorded *Request) bool
+
+// DefaultMatch matches requests with the same method, path, query and body,
+// ignoring the headers and the formatting of JSON bodies
+func DefaultMatch(live *Request, recorded *Request) bool {
+	if live.Method != recorded.Method || live.Path != recorded.Path {
+		return false
+	}
+	if len(live.Query) != 0 || len(recorded.Query) != 0 {
+		if !reflect.DeepEqual(live.Query, recorded.Query) {
+			return false
+		}
+	}
+	if live.BodyBase64 != recorded.BodyBase64 {
+		return false
+	}
+	return canonicalJSON(live.Body) == canonicalJSON(recorded.Body)
+}
+
+// canonicalJSON returns body re-encoded with sorted keys if it's JSON
+func canonicalJSON(body string) string {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(body)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return body
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return string(data)
+}
+
+// Replayer is an http.RoundTripper serving the responses of a fixture. Each
+// recorded interaction is served once, in the recorded order among the
+// matching ones, so repeated requests such as task polling replay in turn.
+type Replayer struct {
+	scrub *scrubber
+	match MatchFunc
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer returns a Replayer serving the interactions of fixture
+func NewReplayer(fixture *Fixture, opts ...Option) (*Replayer, error) {
+	if fixture == nil {
+		return nil, errors.New("NewReplayer: nil fixture")
+	}
+	o, err := newOptions(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewReplayer")
+	}
+	return &Replayer{
+		scrub:        newScrubber(o.secretKeys),
+		match:        o.match,
+		interactions: fixture.Interactions,
+		used:         make([]bool, len(fixture.Interactions)),
+	}, nil
+}
+
+// ReplayFile returns a Replayer serving the interactions of a fixture file
+func ReplayFile(path string, opts ...Option) (*Replayer, error) {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(fixture, opts...)
+}
+
+// RoundTrip satisfies http.RoundTripper. Requests without a matching
+// recorded interaction fail.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Replayer")
+	}
+	live := r.scrub.request(req, body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.interactions {
+		if r.used[i] || !r.match(&live, &r.interactions[i].Request) {
+			continue
+		}
+		r.used[i] = true
+		return newResponse(req, &r.interactions[i].Response)
+	}
+	return nil, errors.Errorf("Replayer: No recorded response for %s %s", req.Method,
+		req.URL.RequestURI())
+}
+
+// Remaining returns the number of interactions not served yet
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	remaining := 0
+	for _, used := range r.used {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// newResponse builds the response to req from a recorded one
+func newResponse(req *http.Request, recorded *Response) (*http.Response, error) {
+	body, err := decodeBody(recorded.Body, recorded.BodyBase64)
+	if err != nil {
+		return nil, errors.Wrap(err, "Replayer")
+	}
+	header := make(http.Header, len(recorded.Header))
+	for key, values := range recorded.Header {
+		header[key] = append([]string(nil), values...)
+	}
+	// the body may have changed length when scrubbed
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status: fmt.Sprintf("%d %s", recorded.StatusCode,
+			http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,189 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Redacted replaces the secrets scrubbed from fixtures
+const Redacted = "REDACTED"
+
+// DefaultSecretKeys are the JSON fields and query parameters scrubbed from
+// fixtures, compared case insensitively
+var DefaultSecretKeys = []string{"password", "sessionId", "session_id", "token",
+	"access_token", "refresh_token", "secret"}
+
+// LoginSecretKeys are the JSON fields also scrubbed from the login requests
+// and responses. Other APIs, e.g. the user APIs, keep them so they replay.
+var LoginSecretKeys = []string{"userId", "username"}
+
+// loginPaths are the suffixes of the paths of the login requests
+var loginPaths = []string{"/login/authenticate.do", "/api/v1/oauth"}
+
+// secretHeaders are scrubbed entirely
+var secretHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// scrubber removes secrets from recorded requests and responses
+type scrubber struct {
+	keys      map[string]bool
+	loginKeys map[string]bool
+}
+
+func newScrubber(keys []string) *scrubber {
+	s := &scrubber{keys: make(map[string]bool), loginKeys: make(map[string]bool)}
+	for _, key := range keys {
+		s.keys[strings.ToLower(key)] = true
+		s.loginKeys[strings.ToLower(key)] = true
+	}
+	for _, key := range LoginSecretKeys {
+		s.loginKeys[strings.ToLower(key)] = true
+	}
+	return s
+}
+
+// isLogin reports whether path is the path of a login request
+func isLogin(path string) bool {
+	for _, suffix := range loginPaths {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// header returns a copy of header with the credentials and cookies scrubbed
+func (s *scrubber) header(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	scrubbed := make(http.Header, len(header))
+	for key, values := range header {
+		scrubbed[key] = append([]string(nil), values...)
+	}
+	for _, key := range secretHeaders {
+		if scrubbed.Get(key) != "" {
+			scrubbed.Set(key, Redacted)
+		}
+	}
+	for i, value := range scrubbed["Cookie"] {
+		scrubbed["Cookie"][i] = scrubCookies(value, true)
+	}
+	// only the first pair of a Set-Cookie is the cookie, the others are
+	// attributes
+	for i, value := range scrubbed["Set-Cookie"] {
+		scrubbed["Set-Cookie"][i] = scrubCookies(value, false)
+	}
+	return scrubbed
+}
+
+// scrubCookies redacts the values of the name=value pairs of a cookie
+// header, or of its first pair only unless all is set
+func scrubCookies(value string, all bool) string {
+	pairs := strings.Split(value, ";")
+	for i, pair := range pairs {
+		if i > 0 && !all {
+			break
+		}
+		if eq := strings.Index(pair, "="); eq >= 0 {
+			pairs[i] = pair[:eq+1] + Redacted
+		}
+	}
+	return strings.Join(pairs, ";")
+}
+
+// query returns a copy of query with the secret parameters scrubbed
+func (s *scrubber) query(query url.Values) url.Values {
+	if len(query) == 0 {
+		return nil
+	}
+	scrubbed := make(url.Values, len(query))
+	for key, values := range query {
+		if s.keys[strings.ToLower(key)] {
+			values = []string{Redacted}
+		}
+		scrubbed[key] = append([]string(nil), values...)
+	}
+	return scrubbed
+}
+
+// body returns body with the secret JSON fields scrubbed, including the
+// LoginSecretKeys for a login request or response. Bodies which aren't JSON,
+// or have no secrets, are returned unchanged.
+func (s *scrubber) body(body []byte, login bool) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return body
+	}
+	keys := s.keys
+	if login {
+		keys = s.loginKeys
+	}
+	if !scrubValue(value, keys) {
+		return body
+	}
+	scrubbed, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return scrubbed
+}
+
+// scrubValue replaces the string values of the keys fields in a decoded JSON
+// value and reports whether it changed anything
+func scrubValue(value interface{}, keys map[string]bool) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if str, ok := field.(string); ok && keys[strings.ToLower(key)] {
+				if str != Redacted {
+					v[key] = Redacted
+					changed = true
+				}
+				continue
+			}
+			changed = scrubValue(field, keys) || changed
+		}
+	case []interface{}:
+		for _, item := range v {
+			changed = scrubValue(item, keys) || changed
+		}
+	}
+	return changed
+}
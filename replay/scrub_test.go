@@ -0,0 +1,91 @@
+//
+// Copyright (c) 2016-2017, Arista Networks, Inc. All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are
+// met:
+//
+//   * Redistributions of source code must retain the above copyright notice,
+//   this list of conditions and the following disclaimer.
+//
+//   * Redistributions in binary form must reproduce the above copyright
+//   notice, this list of conditions and the following disclaimer in the
+//   documentation and/or other materials provided with the distribution.
+//
+//   * Neither the name of Arista Networks nor the names of its
+//   contributors may be used to endorse or promote products derived from
+//   this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS
+// "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT
+// LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR
+// A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL ARISTA NETWORKS
+// BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR
+// BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY,
+// WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE
+// OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN
+// IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+//
+
+package replay
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestScrubHeader_UnitTest(t *testing.T) {
+	s := newScrubber(DefaultSecretKeys)
+	header := http.Header{
+		"Authorization": {"Bearer abc"},
+		"Cookie":        {"session_id=abc; other=def"},
+		"Set-Cookie":    {"session_id=abc; Path=/web; HttpOnly"},
+		"Accept":        {"application/json"},
+	}
+	scrubbed := s.header(header)
+	equals(t, http.Header{
+		"Authorization": {Redacted},
+		"Cookie":        {"session_id=" + Redacted + "; other=" + Redacted},
+		"Set-Cookie":    {"session_id=" + Redacted + "; Path=/web; HttpOnly"},
+		"Accept":        {"application/json"},
+	}, scrubbed)
+	// the original is left untouched
+	equals(t, "Bearer abc", header.Get("Authorization"))
+}
+
+func TestScrubQuery_UnitTest(t *testing.T) {
+	s := newScrubber(append(DefaultSecretKeys, "apiKey"))
+	query := url.Values{"queryparam": {"a"}, "Token": {"x"}, "apikey": {"y", "z"}}
+	equals(t, url.Values{"queryparam": {"a"}, "Token": {Redacted}, "apikey": {Redacted}},
+		s.query(query))
+}
+
+func TestScrubBody_UnitTest(t *testing.T) {
+	s := newScrubber(DefaultSecretKeys)
+	tests := map[string]string{
+		`{"userId":"cvpadmin","password":"p"}`: `{"password":"REDACTED","userId":"cvpadmin"}`,
+		`{"user":{"token":"a","id":12345678901234567890}}`: `{"user":{"id":12345678901234567890,` +
+			`"token":"REDACTED"}}`,
+		`[{"sessionId":"a"}]`: `[{"sessionId":"REDACTED"}]`,
+		// user names are kept outside of logins so the user APIs replay
+		`{"user":{"userId":"a","username":"b"}}`: `{"user":{"userId":"a","username":"b"}}`,
+		// no secrets, the formatting is kept
+		`{ "version": "2020.1.0" }`: `{ "version": "2020.1.0" }`,
+		`not json password=x`:       `not json password=x`,
+	}
+	for body, exp := range tests {
+		equals(t, exp, string(s.body([]byte(body), false)))
+	}
+
+	// logins also have their user names scrubbed
+	equals(t, `{"password":"REDACTED","userId":"REDACTED"}`,
+		string(s.body([]byte(`{"userId":"cvpadmin","password":"p"}`), true)))
+	equals(t, `{"sessionId":"REDACTED","username":"REDACTED"}`,
+		string(s.body([]byte(`{"sessionId":"a","username":"cvpadmin"}`), true)))
+	assert(t, isLogin("/web/login/authenticate.do"), "Expected login path")
+	assert(t, isLogin("/api/v1/oauth"), "Expected oauth login path")
+	assert(t, !isLogin("/web/user/getUser.do"), "Unexpected login path")
+}